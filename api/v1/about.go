@@ -2,31 +2,25 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/patch"
 )
 
-// GetAbout fetches the about page info from a file and sends it to the client.
+// GetAbout fetches the about page info from the database and sends it to the
+// client.
 func (a API) GetAbout(w http.ResponseWriter, r *http.Request) {
-	// Open about page info file
-	path := filepath.Join(a.resourcesDir, "about-info.json")
-	file, err := openOrCreate(path)
+	ret, err := a.db.GetAbout()
 	if err != nil {
-		WriteError(w, err.Error(), http.StatusInternalServerError)
-		a.log.Errorf("error opening about page file: %s\n", err.Error())
-		return
-	}
-	defer file.Close()
-
-	// Read the file contents
-	decoder := json.NewDecoder(file)
-	var ret entities.About
-	if err := decoder.Decode(&ret); err != nil {
-		WriteError(w, err.Error(), http.StatusInternalServerError)
-		a.log.Errorf("error reading about page file: %s\n", err.Error())
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting about page from database: %s\n", err.Error())
 		return
 	}
 
@@ -37,50 +31,57 @@ func (a API) GetAbout(w http.ResponseWriter, r *http.Request) {
 	encoder.Encode(ret)
 }
 
-// UpdateAbout updates the about page info with new values.
+// UpdateAbout updates the about page info with new values. The body is a
+// JSON Merge Patch (RFC 7386): members that are absent are left unchanged, and
+// members set to null are cleared.
+//
+// Requires a valid auth token.
 func (a API) UpdateAbout(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
-	// Decode from request body
-	decoder := json.NewDecoder(r.Body)
-	var update entities.About
-	if err := decoder.Decode(&update); err != nil {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
 		WriteError(w, err.Error(), http.StatusBadRequest)
-		a.log.Errorf("error decoding JSON body in about page update request: %s\n", err.Error())
+		a.log.Errorf("error reading about page update body: %s\n", err.Error())
 		return
 	}
 
-	// Open about page info file
-	path := filepath.Join(a.resourcesDir, "about-info.json")
-	b, err := os.ReadFile(path)
+	// The lists on the about page have their own endpoints
+	if err := checkPatchMembers(body, "sections", "socialLinks", "clients", "awards"); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Check that the patch is valid before it's applied to the stored info
+	merged, err := patch.Merge(nil, body)
 	if err != nil {
-		WriteError(w, err.Error(), http.StatusInternalServerError)
-		a.log.Errorf("error reading about page file: %s\n", err.Error())
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error applying about page patch: %s\n", err.Error())
 		return
 	}
 
-	// Decode the file contents
 	var details entities.About
-	if err := json.Unmarshal(b, &details); err != nil {
-		WriteError(w, err.Error(), http.StatusInternalServerError)
-		a.log.Errorf("error decoding about page contents: %s\n", err.Error())
+	if err := json.Unmarshal(merged, &details); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding patched about page: %s\n", err.Error())
 		return
 	}
 
-	// Set the new values
-	merged := entities.MergeAbout(details, update)
+	if details.ContactEmail != "" && !isEmail(details.ContactEmail) {
+		WriteError(w, "contact email is not a valid email address", http.StatusBadRequest)
+		return
+	}
 
-	// Write out to the file
-	b2, err := json.Marshal(&merged)
-	if err != nil {
-		WriteError(w, err.Error(), http.StatusInternalServerError)
-		a.log.Errorf("error encoding new about info: %s\n", err.Error())
+	if err := a.db.PatchAbout(body); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating about page in database: %s\n", err.Error())
 		return
 	}
 
-	if err := os.WriteFile(path, b2, 0644); err != nil {
-		WriteError(w, err.Error(), http.StatusInternalServerError)
-		a.log.Errorf("error writing about info: %s\n", err.Error())
+	ret, err := a.db.GetAbout()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting about page from database: %s\n", err.Error())
 		return
 	}
 
@@ -88,37 +89,235 @@ func (a API) UpdateAbout(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(200)
 	encoder := json.NewEncoder(w)
-	encoder.Encode(&merged)
+	encoder.Encode(ret)
+}
+
+// SetAboutSections handles requests to replace the free-form sections of the
+// about page. The body is the full list of sections in the order they should
+// be shown.
+//
+// Requires a valid auth token.
+func (a API) SetAboutSections(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	sections := make([]*entities.AboutSection, 0)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&sections); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in about sections request: %s\n", err.Error())
+		return
+	}
+
+	for _, section := range sections {
+		if section == nil {
+			WriteError(w, "sections can't be null", http.StatusBadRequest)
+			return
+		}
+
+		section.Title = strings.TrimSpace(section.Title)
+		if strings.TrimSpace(section.Body) == "" {
+			WriteError(w, "every section needs a body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := a.db.SetAboutSections(sections); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error setting about sections in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// SetSocialLinks handles requests to replace the social links on the about
+// page. The body is the full list of links in the order they should be shown.
+//
+// Requires a valid auth token.
+func (a API) SetSocialLinks(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	links := make([]*entities.SocialLink, 0)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&links); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in social links request: %s\n", err.Error())
+		return
+	}
+
+	for _, link := range links {
+		if link == nil {
+			WriteError(w, "social links can't be null", http.StatusBadRequest)
+			return
+		}
+
+		if err := validateSocialLink(link); err != nil {
+			WriteError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := a.db.SetSocialLinks(links); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error setting social links in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// SetAboutClients handles requests to replace the selected clients on the
+// about page. The body is the full list of clients in the order they should
+// be shown.
+//
+// Requires a valid auth token.
+func (a API) SetAboutClients(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	clients := make([]*entities.AboutClient, 0)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&clients); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in about clients request: %s\n", err.Error())
+		return
+	}
+
+	for _, client := range clients {
+		if client == nil {
+			WriteError(w, "clients can't be null", http.StatusBadRequest)
+			return
+		}
+
+		client.Name = strings.TrimSpace(client.Name)
+		if client.Name == "" {
+			WriteError(w, "every client needs a name", http.StatusBadRequest)
+			return
+		}
+
+		client.URL.Valid = client.URL.Valid && client.URL.String != ""
+		if client.URL.Valid && !isHTTPURL(client.URL.String) {
+			WriteError(w, "client URLs must be absolute http or https URLs", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := a.db.SetAboutClients(clients); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error setting about clients in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
 }
 
-// openOrCreate tries to open a file, and creates it if it doesn't exist. In the
-// case that the file is created, an empty about page struct is written out.
+// SetAwards handles requests to replace the awards on the about page. The
+// body is the full list of awards in the order they should be shown.
 //
-// This has the same use semantics as the file create/open functions in the
-// standard library.
-func openOrCreate(path string) (file *os.File, err error) {
-	file, err = os.OpenFile(path, os.O_RDWR, 0644)
-
-	if err != nil && os.IsNotExist(err) {
-		file, err = os.Create(path)
-		if err != nil {
+// Requires a valid auth token.
+func (a API) SetAwards(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	awards := make([]*entities.Award, 0)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&awards); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in awards request: %s\n", err.Error())
+		return
+	}
+
+	for _, award := range awards {
+		if award == nil {
+			WriteError(w, "awards can't be null", http.StatusBadRequest)
 			return
 		}
 
-		// Write out empty About page details
-		encoder := json.NewEncoder(file)
-		if err = encoder.Encode(&entities.About{
-			Portrait:  "",
-			Statement: "",
-			Resume:    "",
-		}); err != nil {
-			file.Close()
-			file = nil
+		if err := validateAward(award); err != nil {
+			WriteError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+	}
 
+	if err := a.db.SetAwards(awards); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error setting awards in database: %s\n", err.Error())
 		return
 	}
 
-	return
+	w.WriteHeader(200)
+}
+
+// validateSocialLink checks that a social link has a known platform and a
+// valid URL.
+func validateSocialLink(link *entities.SocialLink) error {
+	switch link.Platform {
+	case entities.SocialBehance, entities.SocialDribbble, entities.SocialFacebook, entities.SocialGitHub,
+		entities.SocialInstagram, entities.SocialLinkedIn, entities.SocialPinterest, entities.SocialTwitter,
+		entities.SocialVimeo, entities.SocialYouTube, entities.SocialWebsite, entities.SocialOther:
+	default:
+		return errors.New("unknown social link platform: " + link.Platform)
+	}
+
+	link.URL = strings.TrimSpace(link.URL)
+	if !isHTTPURL(link.URL) {
+		return errors.New("social link URLs must be absolute http or https URLs")
+	}
+
+	link.Label = strings.TrimSpace(link.Label)
+	return nil
+}
+
+// validateAward checks that an award has a title, and that its year and URL
+// are valid if they're set.
+func validateAward(award *entities.Award) error {
+	award.Title = strings.TrimSpace(award.Title)
+	if award.Title == "" {
+		return errors.New("every award needs a title")
+	}
+
+	award.Issuer = strings.TrimSpace(award.Issuer)
+	award.Description = strings.TrimSpace(award.Description)
+
+	// A zero year means no year is set
+	award.Year.Valid = award.Year.Valid && award.Year.Int32 != 0
+	if award.Year.Valid && (award.Year.Int32 < minProjectYear || award.Year.Int32 > maxProjectYear) {
+		return fmt.Errorf("award years must be between %d and %d", minProjectYear, maxProjectYear)
+	}
+
+	award.URL.Valid = award.URL.Valid && award.URL.String != ""
+	if award.URL.Valid && !isHTTPURL(award.URL.String) {
+		return errors.New("award URLs must be absolute http or https URLs")
+	}
+
+	return nil
+}
+
+// ImportAboutFile saves the about page info from the about-info.json file
+// that was used before the info was stored in the database. The file is only
+// imported if the about page has never been edited, and is left in place.
+func (a API) ImportAboutFile() error {
+	path := filepath.Join(a.resourcesDir, "about-info.json")
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	var about entities.About
+	if err := json.Unmarshal(b, &about); err != nil {
+		return err
+	}
+
+	imported, err := a.db.ImportAbout(&about)
+	if err != nil {
+		return err
+	}
+
+	if imported {
+		a.log.Infof("Imported the about page info from '%s'\n", path)
+	}
+
+	return nil
 }
@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestSetAboutLists_Null ensures that null entries in the lists on the about
+// page are rejected instead of crashing the handler.
+func TestSetAboutLists_Null(t *testing.T) {
+	a := API{}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"sections", a.SetAboutSections},
+		{"social links", a.SetSocialLinks},
+		{"clients", a.SetAboutClients},
+		{"awards", a.SetAwards},
+	}
+
+	for _, test := range tests {
+		// Given
+		r := httptest.NewRequest("PUT", "/api/v1/admin/about", strings.NewReader("[null]"))
+		w := httptest.NewRecorder()
+
+		// When
+		test.handler(w, r)
+
+		// Then
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status does not match expected: got %d, expected: %d\n", test.name, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+// TestUpdateAbout_Lists ensures that patches that try to change the lists on
+// the about page are rejected, since they have their own endpoints.
+func TestUpdateAbout_Lists(t *testing.T) {
+	for _, body := range []string{
+		`{"sections": []}`,
+		`{"statement": "Hi", "socialLinks": null}`,
+		`{"clients": [{"name": "Studio"}]}`,
+		`{"awards": []}`,
+		`[]`,
+		`null`,
+	} {
+		// Given
+		a := API{}
+		r := httptest.NewRequest("PATCH", "/api/v1/admin/about", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		// When
+		a.UpdateAbout(w, r)
+
+		// Then
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status does not match expected: got %d, expected: %d\n", body, w.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// GetAlbums handles requests to get all photo albums. Watermarked cover photos
+// are sent without their file names, like GetPhotos.
+func (a API) GetAlbums(w http.ResponseWriter, r *http.Request) {
+	ret, err := a.db.GetAlbums()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting albums from database: %s\n", err.Error())
+		return
+	}
+
+	hide, err := a.hidesOriginals(r)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting watermark settings from database: %s\n", err.Error())
+		return
+	}
+
+	if hide {
+		for _, album := range ret {
+			hideAlbumCover(album)
+		}
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// GetAlbum handles requests to get a photo album and its photos.
+func (a API) GetAlbum(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+
+	ret, err := a.db.GetAlbum(slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "album not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting album from database: %s\n", err.Error())
+		return
+	}
+
+	hide, err := a.hidesOriginals(r)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting watermark settings from database: %s\n", err.Error())
+		return
+	}
+
+	if hide {
+		hideAlbumCover(ret)
+		hideOriginals(ret.Photos)
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// AddAlbum handles requests to create a new photo album.
+//
+// Requires a valid auth token.
+func (a API) AddAlbum(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var album entities.Album
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&album); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in add album request: %s\n", err.Error())
+		return
+	}
+
+	if status, err := a.validateAlbum(&album); err != nil {
+		WriteError(w, err.Error(), status)
+		return
+	}
+
+	id, err := a.db.AddAlbum(&album)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding album to database: %s\n", err.Error())
+		return
+	}
+
+	album.ID = id
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&album)
+}
+
+// UpdateAlbum handles requests to change the details of a photo album.
+//
+// Requires a valid auth token.
+func (a API) UpdateAlbum(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var album entities.Album
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&album); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in album update request: %s\n", err.Error())
+		return
+	}
+
+	album.ID = uint(id)
+
+	if status, err := a.validateAlbum(&album); err != nil {
+		WriteError(w, err.Error(), status)
+		return
+	}
+
+	if err := a.db.UpdateAlbum(&album); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "album not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating album in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// RemoveAlbum handles requests to remove a photo album. The photos in the
+// album are kept.
+//
+// Requires a valid auth token.
+func (a API) RemoveAlbum(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !a.checkNotInMenu(w, entities.MenuLinkAlbum, uint(id)) {
+		return
+	}
+
+	if err := a.db.RemoveAlbum(uint(id)); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error removing album from database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// SetAlbumPhotos handles requests to replace the photos in an album. The body
+// is the full list of photo IDs in the order they should be shown.
+//
+// Requires a valid auth token.
+func (a API) SetAlbumPhotos(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var photoIDs []uint
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&photoIDs); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in album photos request: %s\n", err.Error())
+		return
+	}
+
+	// Only add each photo once
+	seen := make(map[uint]bool, len(photoIDs))
+	ids := make([]uint, 0, len(photoIDs))
+	for _, photoID := range photoIDs {
+		if !seen[photoID] {
+			seen[photoID] = true
+			ids = append(ids, photoID)
+		}
+	}
+
+	// Make sure every photo exists
+	found, err := a.db.CountPhotos(ids)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error counting photos in database: %s\n", err.Error())
+		return
+	}
+
+	if found != len(ids) {
+		WriteError(w, "some of the photos don't exist", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.SetAlbumPhotos(uint(id), ids); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "album not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error setting album photos in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// validateAlbum checks that an album has a valid, unused slug and a title, and
+// that its cover photo exists. It returns the HTTP status code to respond
+// with if the album isn't valid.
+func (a API) validateAlbum(album *entities.Album) (int, error) {
+	album.Slug = strings.TrimSpace(album.Slug)
+	if !isSlug(album.Slug) {
+		return http.StatusBadRequest, errors.New("slug must be lowercase letters and numbers separated by hyphens")
+	}
+
+	album.Title = strings.TrimSpace(album.Title)
+	if album.Title == "" {
+		return http.StatusBadRequest, errors.New("an album needs a title")
+	}
+
+	existing, err := a.db.GetAlbum(album.Slug)
+	if err != nil && err != sql.ErrNoRows {
+		a.log.Errorf("error getting album from database: %s\n", err.Error())
+		return http.StatusInternalServerError, errors.New(dbError)
+	}
+
+	if err == nil && existing.ID != album.ID {
+		return http.StatusConflict, errors.New("an album with that slug already exists")
+	}
+
+	// A zero cover photo ID means no cover is set
+	album.CoverPhotoID.Valid = album.CoverPhotoID.Valid && album.CoverPhotoID.Int32 != 0
+	if album.CoverPhotoID.Valid {
+		found, err := a.db.CountPhotos([]uint{uint(album.CoverPhotoID.Int32)})
+		if err != nil {
+			a.log.Errorf("error counting photos in database: %s\n", err.Error())
+			return http.StatusInternalServerError, errors.New(dbError)
+		}
+
+		if found == 0 {
+			return http.StatusBadRequest, errors.New("cover photo does not exist")
+		}
+	}
+
+	return http.StatusOK, nil
+}
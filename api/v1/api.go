@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"net"
 	"net/http"
 	"time"
 
@@ -8,25 +9,85 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/nicolekellydesign/webby-api/database"
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/mailer"
+	"github.com/nicolekellydesign/webby-api/internal/patch"
+	"github.com/nicolekellydesign/webby-api/internal/payment"
+	"github.com/nicolekellydesign/webby-api/internal/ratelimit"
+	"github.com/nicolekellydesign/webby-api/internal/signing"
 )
 
 const dbError = "internal database error"
 
+// Config holds the optional settings for the API.
+type Config struct {
+	// RejectDuplicates makes adding an image that looks like an image that's
+	// already stored fail, instead of only warning about it.
+	RejectDuplicates bool
+
+	// SigningKey is the secret key used to sign download links. Links signed
+	// with a different key are rejected.
+	SigningKey []byte
+
+	// SiteURL is the address of the public site, used for absolute links
+	// like the ones in feeds. If it's empty, the host of each request is used,
+	// except for links that are emailed, which always need it.
+	SiteURL string
+
+	// Mailer sends notification email, like when someone uses the contact
+	// form. If it's nil, no email is sent.
+	Mailer *mailer.Mailer
+
+	// Payments takes payment for print orders. If it's nil, the shop catalog
+	// can still be browsed, but orders can't be made.
+	Payments payment.Provider
+
+	// Currency is the ISO 4217 code of the currency that print prices are in.
+	// If it's empty, prices are in US dollars.
+	Currency string
+
+	// TrustedProxies are the addresses of reverse proxies that are trusted to
+	// say who the client is with forwarding headers. Requests from anywhere
+	// else are limited by the address they came from.
+	TrustedProxies []*net.IPNet
+}
+
 // API is our v1 API that serves and handles endpoints.
 type API struct {
 	db           *database.DB
 	log          *waterlog.WaterLog
 	imageDir     string
 	resourcesDir string
+	cacheDir     string
+	privateDir   string
+	config       Config
+	signer       *signing.Signer
+
+	contactLimiter         *ratelimit.Limiter
+	enquiryLimiter         *ratelimit.Limiter
+	subscribeLimiter       *ratelimit.Limiter
+	orderLimiter           *ratelimit.Limiter
+	proofingIPLimiter      *ratelimit.Limiter
+	proofingGalleryLimiter *ratelimit.Limiter
 }
 
 // NewAPI creates a new v1 API.
-func NewAPI(db *database.DB, log *waterlog.WaterLog, imagesDir, resourcesDir string) *API {
+func NewAPI(db *database.DB, log *waterlog.WaterLog, imagesDir, resourcesDir, cacheDir, privateDir string, config Config) *API {
 	return &API{
 		db,
 		log,
 		imagesDir,
 		resourcesDir,
+		cacheDir,
+		privateDir,
+		config,
+		signing.New(config.SigningKey),
+		ratelimit.New(contactRateLimit, contactRateWindow),
+		ratelimit.New(enquiryRateLimit, enquiryRateWindow),
+		ratelimit.New(subscribeRateLimit, subscribeRateWindow),
+		ratelimit.New(orderRateLimit, orderRateWindow),
+		ratelimit.New(proofingIPAttempts, proofingIPWindow),
+		ratelimit.New(proofingGalleryAttempts, proofingGalleryWindow),
 	}
 }
 
@@ -35,9 +96,43 @@ func (a API) Routes() http.Handler {
 	r := chi.NewRouter()
 
 	r.Get("/about", a.GetAbout)
+	r.Get("/albums", a.GetAlbums)
+	r.Get("/albums/{slug}", a.GetAlbum)
+	r.Get("/cv", a.GetCV)
+	r.Get("/cv/pdf", a.GetCVPDF)
+	r.Get("/cv/resume.json", a.GetJSONResume)
+	r.Get("/pages", a.GetPages)
+	r.Get("/pages/{slug}", a.GetPage)
 	r.Get("/photos", a.GetPhotos)
+	r.Get("/posts", a.GetPosts)
+	r.Get("/posts/{slug}", a.GetPost)
+	r.Get("/feeds/atom", a.GetAtomFeed)
+	r.Get("/feeds/json", a.GetJSONFeed)
+	r.Get("/feeds/rss", a.GetRSSFeed)
+	r.Get("/photos/{id}/image", a.GetPhotoImage)
 	r.Get("/gallery", a.GetGalleryItems)
 	r.Get("/gallery/{name}", a.GetProject)
+	r.Get("/gallery/{name}/seo", a.GetProjectSEO)
+
+	r.Mount("/proofing/{token}", a.proofingRouter())
+
+	r.Get("/download", a.Download)
+	r.Get("/contact/token", a.GetContactToken)
+	r.Post("/contact", a.SendContactMessage)
+	r.Post("/enquiries", a.SendEnquiry)
+	r.Get("/settings", a.GetPublicSettings)
+	r.Get("/menus/{menu}", a.GetMenu)
+	r.Post("/newsletter/subscribe", a.Subscribe)
+	r.Post("/newsletter/confirm", a.ConfirmSubscription)
+	r.Post("/newsletter/unsubscribe", a.Unsubscribe)
+	r.Get("/testimonials", a.GetTestimonials)
+
+	r.Route("/shop", func(r chi.Router) {
+		r.Get("/products", a.GetPrintProducts)
+		r.Get("/products/{id}", a.GetPrintProduct)
+		r.Post("/orders", a.AddOrder)
+		r.Get("/orders/{reference}", a.GetOrderByReference)
+	})
 
 	r.Get("/check", a.CheckSession)
 	r.Post("/login", a.PerformLogin)
@@ -52,19 +147,64 @@ func (a API) Routes() http.Handler {
 func (a API) adminRouter() http.Handler {
 	r := chi.NewRouter()
 	r.Use(a.adminOnly)
-	r.Use(middleware.AllowContentType("application/json", "multipart/form-data"))
+	r.Use(middleware.AllowContentType("application/json", patch.ContentType, "multipart/form-data"))
 
 	r.Route("/about", func(r chi.Router) {
 		r.Patch("/", a.UpdateAbout)
+		r.Put("/awards", a.SetAwards)
+		r.Put("/clients", a.SetAboutClients)
+		r.Put("/sections", a.SetAboutSections)
+		r.Put("/social", a.SetSocialLinks)
+	})
+
+	r.Route("/albums", func(r chi.Router) {
+		r.Post("/", a.AddAlbum)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Put("/", a.UpdateAlbum)
+			r.Delete("/", a.RemoveAlbum)
+			r.Put("/photos", a.SetAlbumPhotos)
+		})
+	})
+
+	r.Route("/contributors", func(r chi.Router) {
+		r.Get("/", a.GetContributors)
+		r.Post("/", a.AddContributor)
+		r.Put("/{id}", a.UpdateContributor)
+		r.Delete("/{id}", a.RemoveContributor)
+	})
+
+	r.Route("/downloads", func(r chi.Router) {
+		r.Get("/", a.GetDownloadLinks)
+		r.Post("/", a.AddDownloadLink)
+		r.Delete("/{id}", a.RevokeDownloadLink)
+	})
+
+	r.Put("/cv", a.UpdateCV)
+
+	r.Get("/duplicates", a.GetDuplicateImages)
+
+	r.Route("/enquiries", func(r chi.Router) {
+		r.Get("/", a.GetEnquiries)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", a.GetEnquiry)
+			r.Delete("/", a.RemoveEnquiry)
+			r.Get("/attachments/{attachmentID}", a.GetEnquiryAttachment)
+		})
 	})
 
 	r.Route("/gallery", func(r chi.Router) {
+		r.Get("/", a.GetAllGalleryItems)
 		r.Post("/", a.AddGalleryItem)
 
 		r.Route("/{id}", func(r chi.Router) {
 			r.Delete("/", a.RemoveGalleryItem)
 			r.Put("/", a.UpdateProject)
+			r.Patch("/", a.PatchProject)
 
+			r.Post("/clone", a.CloneProject)
+			r.Put("/credits", a.SetProjectCredits)
 			r.Patch("/thumbnail", a.ChangeThumbnail)
 
 			r.Post("/images", a.AddImages)
@@ -72,8 +212,99 @@ func (a API) adminRouter() http.Handler {
 		})
 	})
 
-	r.Post("/photos", a.AddPhotos)
-	r.Delete("/photos", a.RemovePhotos)
+	r.Put("/menus/{menu}", a.SetMenu)
+
+	r.Route("/messages", func(r chi.Router) {
+		r.Get("/", a.GetContactMessages)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", a.GetContactMessage)
+			r.Delete("/", a.RemoveContactMessage)
+			r.Put("/archived", a.SetContactMessageArchived)
+			r.Put("/read", a.SetContactMessageRead)
+		})
+	})
+
+	r.Route("/orders", func(r chi.Router) {
+		r.Get("/", a.GetOrders)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", a.GetOrder)
+			r.Put("/status", a.SetOrderStatus)
+		})
+	})
+
+	r.Route("/pages", func(r chi.Router) {
+		r.Get("/", a.GetAllPages)
+		r.Post("/", a.AddPage)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", a.GetPageByID)
+			r.Put("/", a.UpdatePage)
+			r.Delete("/", a.RemovePage)
+		})
+	})
+
+	r.Route("/photos", func(r chi.Router) {
+		r.Post("/", a.AddPhotos)
+		r.Patch("/", a.PatchPhotos)
+		r.Delete("/", a.RemovePhotos)
+		r.Patch("/{id}", a.PatchPhoto)
+	})
+
+	r.Route("/posts", func(r chi.Router) {
+		r.Get("/", a.GetAllPosts)
+		r.Post("/", a.AddPost)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", a.GetPostByID)
+			r.Put("/", a.UpdatePost)
+			r.Delete("/", a.RemovePost)
+		})
+	})
+
+	r.Route("/proofing", func(r chi.Router) {
+		r.Get("/", a.GetProofingGalleries)
+		r.Post("/", a.AddProofingGallery)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", a.GetProofingGallery)
+			r.Put("/", a.UpdateProofingGallery)
+			r.Delete("/", a.RemoveProofingGallery)
+
+			r.Post("/photos", a.AddProofingPhotos)
+			r.Delete("/photos", a.RemoveProofingPhotos)
+			r.Get("/selection", a.ExportProofingSelection)
+		})
+	})
+
+	r.Route("/shop/products", func(r chi.Router) {
+		r.Get("/", a.GetAllPrintProducts)
+		r.Post("/", a.AddPrintProduct)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", a.GetPrintProductByID)
+			r.Put("/", a.UpdatePrintProduct)
+			r.Delete("/", a.RemovePrintProduct)
+
+			r.Post("/variants", a.AddPrintVariant)
+			r.Put("/variants/{variantID}", a.UpdatePrintVariant)
+			r.Delete("/variants/{variantID}", a.RemovePrintVariant)
+		})
+	})
+
+	r.Route("/subscribers", func(r chi.Router) {
+		r.Get("/", a.GetSubscribers)
+		r.Get("/export", a.ExportSubscribers)
+		r.Delete("/{id}", a.RemoveSubscriber)
+	})
+
+	r.Route("/testimonials", func(r chi.Router) {
+		r.Get("/", a.GetAllTestimonials)
+		r.Post("/", a.AddTestimonial)
+		r.Put("/{id}", a.UpdateTestimonial)
+		r.Delete("/{id}", a.RemoveTestimonial)
+	})
 
 	r.Route("/users", func(r chi.Router) {
 		r.Get("/", a.GetUsers)
@@ -83,9 +314,53 @@ func (a API) adminRouter() http.Handler {
 
 	r.Post("/upload", a.Upload)
 
+	r.Route("/settings", func(r chi.Router) {
+		r.Get("/", a.GetSettings)
+		r.Put("/", a.UpdateSettings)
+	})
+
+	r.Route("/watermark", func(r chi.Router) {
+		r.Get("/", a.GetWatermark)
+		r.Put("/", a.UpdateWatermark)
+	})
+
 	return r
 }
 
+// isAdmin checks if a request has a valid session, without turning the
+// request away if it doesn't. It's used by public routes that show signed-in
+// users more than everyone else.
+func (a API) isAdmin(r *http.Request) bool {
+	cookie, err := r.Cookie("session_token")
+	if err != nil {
+		return false
+	}
+
+	session, err := a.db.GetSession(cookie.Value)
+	if err != nil {
+		return false
+	}
+
+	return sessionActive(session, time.Now())
+}
+
+// sessionActive checks if a stored session exists and hasn't expired.
+// Sessions that aren't found are returned by the database as empty sessions.
+func sessionActive(session *entities.Session, now time.Time) bool {
+	if session == nil || (*session == entities.Session{}) {
+		return false
+	}
+
+	if session.MaxAge > 0 {
+		expires := session.Created.Add(time.Duration(session.MaxAge) * time.Second)
+		if now.After(expires) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // adminOnly returns a middleware handler to check for a valid session.
 func (a API) adminOnly(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -110,7 +385,7 @@ func (a API) adminOnly(next http.Handler) http.Handler {
 		}
 
 		// Check if we have a session
-		if session == nil {
+		if session == nil || (*session == entities.Session{}) {
 			WriteError(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 			return
 		}
@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// TestIsAdmin_NoCookie ensures that requests without a session cookie aren't
+// treated as signed in.
+func TestIsAdmin_NoCookie(t *testing.T) {
+	// Given
+	a := API{}
+	r := httptest.NewRequest("GET", "/api/v1/photos", nil)
+
+	// When
+	result := a.isAdmin(r)
+
+	// Then
+	if result {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", result, false)
+	}
+}
+
+// TestSessionActive ensures that only stored sessions that haven't expired
+// are active.
+func TestSessionActive(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		session  *entities.Session
+		expected bool
+	}{
+		{"no session", nil, false},
+		// The database gives back an empty session for a token it doesn't know
+		{"bogus cookie", &entities.Session{}, false},
+		{"no expiry", &entities.Session{Token: "token", Created: now.Add(-24 * time.Hour)}, true},
+		{"not expired", &entities.Session{Token: "token", Created: now.Add(-time.Minute), MaxAge: 3600}, true},
+		{"expired", &entities.Session{Token: "token", Created: now.Add(-2 * time.Hour), MaxAge: 3600}, false},
+	}
+
+	for _, test := range tests {
+		// When
+		result := sessionActive(test.session, now)
+
+		// Then
+		if result != test.expected {
+			t.Fatalf("%s: result does not match expected: got %v, expected: %v\n", test.name, result, test.expected)
+		}
+	}
+}
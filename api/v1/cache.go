@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cachedFile opens the file with the given name in a cache directory, using
+// write to make it if there's no cached copy. It's written to a temporary
+// file first, so that a half-written file is never served. Cached files are
+// named after what they were made from, so a change makes a new file; once
+// it's in place, the older copies matching the stale pattern are removed. The
+// caller has to close the file. Since it's already open, it can still be read
+// if a newer copy removes it in the meantime.
+func cachedFile(dir, name, stale string, write func(io.Writer) error) (*os.File, error) {
+	path := filepath.Join(dir, name)
+	if file, err := os.Open(path); err == nil {
+		return file, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+
+	// Files that are still being written are left for their writers
+	old, err := filepath.Glob(filepath.Join(dir, stale))
+	if err == nil {
+		for _, file := range old {
+			if file != path && !strings.HasSuffix(file, ".tmp") {
+				os.Remove(file)
+			}
+		}
+	}
+
+	return tmp, nil
+}
@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCachedFile ensures that a cached file is only written once, and that
+// writing a new copy removes the stale ones.
+func TestCachedFile(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	writes := 0
+	write := func(contents string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			writes++
+			_, err := io.WriteString(w, contents)
+			return err
+		}
+	}
+
+	pending := filepath.Join(dir, "c.txt.123.tmp")
+	if err := os.WriteFile(pending, nil, 0644); err != nil {
+		t.Fatalf("unexpected error: %s\n", err)
+	}
+
+	// When
+	for _, name := range []string{"a.txt", "a.txt", "b.txt"} {
+		file, err := cachedFile(dir, name, "*", write(name))
+		if err != nil {
+			t.Fatalf("unexpected error: %s\n", err)
+		}
+
+		b, err := io.ReadAll(file)
+		file.Close()
+
+		// Then
+		if err != nil || string(b) != name {
+			t.Fatalf("contents do not match expected: got %q, expected: %q\n", b, name)
+		}
+	}
+
+	if writes != 2 {
+		t.Fatalf("writes do not match expected: got %d, expected: %d\n", writes, 2)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "a.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected the stale copy to be removed: %v\n", err)
+	}
+
+	if _, err := os.Stat(pending); err != nil {
+		t.Fatalf("expected the pending file to be left: %s\n", err)
+	}
+}
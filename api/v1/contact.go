@@ -0,0 +1,368 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/mailer"
+	"github.com/nicolekellydesign/webby-api/internal/signing"
+)
+
+const (
+	// contactFormKey is the query parameter in a contact form token that
+	// holds when the form was opened.
+	contactFormKey = "contact"
+
+	// contactTokenExpiry is how long a contact form can be left open before
+	// it has to be reloaded.
+	contactTokenExpiry = 24 * time.Hour
+
+	// minContactFormTime is the least time it can take to fill in the contact
+	// form. Anything quicker is almost certainly a bot.
+	minContactFormTime = 3 * time.Second
+
+	// contactRateLimit is how many messages can be sent from an IP address
+	// within contactRateWindow.
+	contactRateLimit  = 5
+	contactRateWindow = time.Hour
+
+	maxContactNameLength    = 100
+	maxContactSubjectLength = 200
+	maxContactMessageLength = 5000
+)
+
+// GetContactToken handles requests for a token to send the contact form with.
+// The token records when the form was opened, so forms that are sent too
+// quickly can be turned away.
+func (a API) GetContactToken(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	values := url.Values{contactFormKey: {strconv.FormatInt(now.Unix(), 10)}}
+	a.signer.Sign(values, now.Add(contactTokenExpiry))
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ContactTokenResponse{Token: values.Encode()})
+}
+
+// SendContactMessage handles messages sent through the contact form. Each IP
+// address can only send a few messages an hour. Messages that fill in the
+// hidden honeypot field look like they were sent, but are thrown away.
+func (a API) SendContactMessage(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req ContactRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !a.contactLimiter.Allow(clientIP(r), time.Now()) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(contactRateWindow.Seconds())))
+		WriteError(w, "too many messages have been sent, please try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	if req.Website != "" {
+		w.WriteHeader(200)
+		return
+	}
+
+	if err := a.checkContactToken(req.Token, time.Now()); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	message := &entities.ContactMessage{
+		Name:    strings.TrimSpace(req.Name),
+		Email:   strings.TrimSpace(req.Email),
+		Subject: strings.TrimSpace(req.Subject),
+		Message: strings.TrimSpace(req.Message),
+	}
+
+	if err := validateContactMessage(message); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := a.db.AddContactMessage(message); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding contact message to database: %s\n", err.Error())
+		return
+	}
+
+	// The message is saved either way, so the sender doesn't wait on the
+	// email being sent
+	subject := "New message from " + message.Name
+	if message.Subject != "" {
+		subject += ": " + message.Subject
+	}
+
+	body := fmt.Sprintf("Name: %s\nEmail: %s\n\n%s\n", message.Name, message.Email, message.Message)
+	go a.notify(subject, &mail.Address{Name: message.Name, Address: message.Email}, body)
+
+	w.WriteHeader(200)
+}
+
+// GetContactMessages handles requests to get the messages in the contact
+// inbox, newest first. Archived messages are sent instead if the archived
+// query parameter is true.
+//
+// Requires a valid auth token.
+func (a API) GetContactMessages(w http.ResponseWriter, r *http.Request) {
+	archived := false
+	if value := r.URL.Query().Get("archived"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			WriteError(w, "archived must be true or false", http.StatusBadRequest)
+			return
+		}
+
+		archived = parsed
+	}
+
+	ret, err := a.db.GetContactMessages(archived)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting contact messages from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// GetContactMessage handles requests to get a contact message.
+//
+// Requires a valid auth token.
+func (a API) GetContactMessage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := a.db.GetContactMessage(uint(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "message not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting contact message from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// SetContactMessageRead handles requests to mark a contact message as read or
+// unread.
+//
+// Requires a valid auth token.
+func (a API) SetContactMessageRead(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var req ContactReadRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in contact message read request: %s\n", err.Error())
+		return
+	}
+
+	if err := a.db.SetContactMessageRead(uint(id), req.Read); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "message not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating contact message in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// SetContactMessageArchived handles requests to move a contact message to or
+// from the archive.
+//
+// Requires a valid auth token.
+func (a API) SetContactMessageArchived(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var req ContactArchiveRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in contact message archive request: %s\n", err.Error())
+		return
+	}
+
+	if err := a.db.SetContactMessageArchived(uint(id), req.Archived); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "message not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating contact message in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// RemoveContactMessage handles requests to remove a contact message.
+//
+// Requires a valid auth token.
+func (a API) RemoveContactMessage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.RemoveContactMessage(uint(id)); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error removing contact message from database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// checkContactToken checks that a contact form token is valid, and that the
+// form wasn't sent too soon after it was opened.
+func (a API) checkContactToken(token string, now time.Time) error {
+	values, err := url.ParseQuery(token)
+	if err != nil {
+		return errors.New("invalid form token")
+	}
+
+	if err := a.signer.Verify(values, now); err != nil {
+		if err == signing.ErrExpired {
+			return errors.New("the form has expired, please reload the page and try again")
+		}
+
+		return errors.New("invalid form token")
+	}
+
+	opened, err := strconv.ParseInt(values.Get(contactFormKey), 10, 64)
+	if err != nil {
+		return errors.New("invalid form token")
+	}
+
+	if now.Sub(time.Unix(opened, 0)) < minContactFormTime {
+		return errors.New("the form was sent too quickly, please try again")
+	}
+
+	return nil
+}
+
+// notify emails the notification address in the site settings, if email is
+// set up, with replies going to the given address.
+func (a API) notify(subject string, replyTo *mail.Address, body string) {
+	if a.config.Mailer == nil {
+		return
+	}
+
+	settings, err := a.db.GetSettings()
+	if err != nil {
+		a.log.Errorf("error getting settings from database: %s\n", err.Error())
+		return
+	}
+
+	if settings.NotificationEmail == "" {
+		return
+	}
+
+	err = a.config.Mailer.Send(&mailer.Message{
+		To:      []string{settings.NotificationEmail},
+		ReplyTo: replyTo.String(),
+		Subject: subject,
+		Body:    body,
+	})
+	if err != nil {
+		a.log.Errorf("error sending notification email: %s\n", err.Error())
+	}
+}
+
+// validateContactMessage checks that a contact message has a name, a valid
+// email address, and a message, and that nothing is too long.
+func validateContactMessage(message *entities.ContactMessage) error {
+	if message.Name == "" {
+		return errors.New("please enter your name")
+	}
+
+	if len([]rune(message.Name)) > maxContactNameLength {
+		return fmt.Errorf("name can't be longer than %d characters", maxContactNameLength)
+	}
+
+	if !isEmail(message.Email) {
+		return errors.New("please enter a valid email address")
+	}
+
+	if len([]rune(message.Subject)) > maxContactSubjectLength {
+		return fmt.Errorf("subject can't be longer than %d characters", maxContactSubjectLength)
+	}
+
+	if message.Message == "" {
+		return errors.New("please enter a message")
+	}
+
+	if len([]rune(message.Message)) > maxContactMessageLength {
+		return fmt.Errorf("message can't be longer than %d characters", maxContactMessageLength)
+	}
+
+	return nil
+}
+
+// clientIP gets the IP address a request came from, without the port. The
+// remote address is only taken from forwarding headers when the request came
+// through a trusted proxy; see Config.TrustedProxies.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+
+	return r.RemoteAddr
+}
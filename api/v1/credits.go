@@ -0,0 +1,224 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// AddContributor handles requests to add a new person or organisation that
+// can be credited on projects.
+//
+// Requires a valid auth token.
+func (a API) AddContributor(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var contributor entities.Contributor
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&contributor); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in add contributor request: %s\n", err.Error())
+		return
+	}
+
+	if err := validateContributor(&contributor); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := a.db.AddContributor(&contributor)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding contributor to database: %s\n", err.Error())
+		return
+	}
+
+	contributor.ID = id
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&contributor)
+}
+
+// GetContributors handles requests to get all contributors.
+//
+// Requires a valid auth token.
+func (a API) GetContributors(w http.ResponseWriter, r *http.Request) {
+	ret, err := a.db.GetContributors()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting contributors from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// UpdateContributor handles requests to change a contributor's details.
+//
+// Requires a valid auth token.
+func (a API) UpdateContributor(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var contributor entities.Contributor
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&contributor); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in contributor update request: %s\n", err.Error())
+		return
+	}
+
+	if err := validateContributor(&contributor); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contributor.ID = uint(id)
+
+	if err := a.db.UpdateContributor(&contributor); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "contributor not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating contributor in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// RemoveContributor handles requests to remove a contributor. Any credits
+// they have on projects are removed too.
+//
+// Requires a valid auth token.
+func (a API) RemoveContributor(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.RemoveContributor(uint(id)); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error removing contributor from database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// SetProjectCredits handles requests to replace the credits for a project.
+// The body is the full list of credits in the order they should be shown.
+//
+// Requires a valid auth token.
+func (a API) SetProjectCredits(w http.ResponseWriter, r *http.Request) {
+	galleryID := chi.URLParam(r, "id")
+
+	defer r.Body.Close()
+
+	credits := make([]*entities.Credit, 0)
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&credits); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in project credits request: %s\n", err.Error())
+		return
+	}
+
+	if _, err := a.db.GetProject(galleryID); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "project not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting project from database: %s\n", err.Error())
+		return
+	}
+
+	contributors, err := a.db.GetContributors()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting contributors from database: %s\n", err.Error())
+		return
+	}
+
+	known := make(map[uint]bool, len(contributors))
+	for _, contributor := range contributors {
+		known[contributor.ID] = true
+	}
+
+	// Make sure every credit has a role and points to a real contributor
+	for _, credit := range credits {
+		if credit == nil {
+			WriteError(w, "credits can't be null", http.StatusBadRequest)
+			return
+		}
+
+		credit.Role = strings.TrimSpace(credit.Role)
+		if credit.Role == "" {
+			WriteError(w, "every credit needs a role", http.StatusBadRequest)
+			return
+		}
+
+		if !known[credit.ContributorID] {
+			WriteError(w, "no contributor with ID "+strconv.FormatUint(uint64(credit.ContributorID), 10), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := a.db.SetProjectCredits(galleryID, credits); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error setting project credits in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// validateContributor checks that a contributor has a name, a known kind, and
+// an http or https URL if they have one. If no kind is set, the contributor is
+// assumed to be a person.
+func validateContributor(contributor *entities.Contributor) error {
+	contributor.Name = strings.TrimSpace(contributor.Name)
+	if contributor.Name == "" {
+		return errors.New("a contributor needs a name")
+	}
+
+	switch contributor.Kind {
+	case "":
+		contributor.Kind = entities.ContributorPerson
+	case entities.ContributorPerson, entities.ContributorOrganisation:
+	default:
+		return errors.New("contributor kind must be 'person' or 'organisation'")
+	}
+
+	contributor.URL.String = strings.TrimSpace(contributor.URL.String)
+	contributor.URL.Valid = contributor.URL.String != ""
+	if contributor.URL.Valid && !isHTTPURL(contributor.URL.String) {
+		return errors.New("contributor URL must be an http or https URL")
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// TestValidateContributor ensures that contributors need a name, a known
+// kind, and an http or https URL if they have one.
+func TestValidateContributor(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		valid bool
+	}{
+		{"person", `{"name": "Ada", "kind": "person"}`, true},
+		{"organisation", `{"name": "Studio", "kind": "organisation"}`, true},
+		{"no kind", `{"name": "Ada"}`, true},
+		{"unknown kind", `{"name": "Ada", "kind": "robot"}`, false},
+		{"no name", `{"name": "  ", "kind": "person"}`, false},
+		{"https URL", `{"name": "Ada", "url": "https://example.com"}`, true},
+		{"http URL", `{"name": "Ada", "url": "http://example.com"}`, true},
+		{"relative URL", `{"name": "Ada", "url": "/about"}`, false},
+		{"other scheme", `{"name": "Ada", "url": "javascript:alert(1)"}`, false},
+		{"no host", `{"name": "Ada", "url": "https://"}`, false},
+	}
+
+	for _, test := range tests {
+		// Given
+		var contributor entities.Contributor
+		if err := json.Unmarshal([]byte(test.body), &contributor); err != nil {
+			t.Fatalf("%s: unexpected error decoding contributor: %s\n", test.name, err)
+		}
+
+		// When
+		err := validateContributor(&contributor)
+
+		// Then
+		if test.valid != (err == nil) {
+			t.Fatalf("%s: error does not match expected: got %v, expected valid: %v\n", test.name, err, test.valid)
+		}
+	}
+}
+
+// TestValidateContributor_Defaults ensures that a contributor without a kind
+// is a person, and that its fields are trimmed.
+func TestValidateContributor_Defaults(t *testing.T) {
+	// Given
+	contributor := entities.Contributor{Name: " Ada "}
+	contributor.URL.String = " https://example.com "
+	contributor.URL.Valid = true
+
+	// When
+	err := validateContributor(&contributor)
+
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err)
+	}
+
+	if contributor.Name != "Ada" || contributor.Kind != entities.ContributorPerson || contributor.URL.String != "https://example.com" {
+		t.Fatalf("result does not match expected: got %+v\n", contributor)
+	}
+}
+
+// TestValidateContributor_EmptyURL ensures that an empty or blank URL means
+// the contributor has no URL, so it's stored as NULL.
+func TestValidateContributor_EmptyURL(t *testing.T) {
+	for _, body := range []string{
+		`{"name": "Ada"}`,
+		`{"name": "Ada", "url": null}`,
+		`{"name": "Ada", "url": ""}`,
+		`{"name": "Ada", "url": "   "}`,
+	} {
+		// Given
+		var contributor entities.Contributor
+		if err := json.Unmarshal([]byte(body), &contributor); err != nil {
+			t.Fatalf("%s: unexpected error decoding contributor: %s\n", body, err)
+		}
+
+		// When
+		err := validateContributor(&contributor)
+
+		// Then
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s\n", body, err)
+		}
+
+		value, err := contributor.URL.Value()
+		if err != nil || value != nil {
+			t.Fatalf("%s: stored URL does not match expected: got %#v, expected: nil\n", body, value)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/resume"
+)
+
+// cvDatePattern matches ISO 8601 dates that can leave out the day or the
+// month.
+var cvDatePattern = regexp.MustCompile(`^\d{4}(-\d{2}(-\d{2})?)?$`)
+
+// GetCV handles requests to get the structured CV.
+func (a API) GetCV(w http.ResponseWriter, r *http.Request) {
+	ret, err := a.db.GetCV()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting CV from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// GetJSONResume handles requests to export the CV as a JSON Resume document.
+// The social links and awards on the about page are included.
+func (a API) GetJSONResume(w http.ResponseWriter, r *http.Request) {
+	ret, err := a.jsonResume()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting CV from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// GetCVPDF handles requests to download the CV as a PDF. The PDF is cached
+// until the CV or the about page changes.
+func (a API) GetCVPDF(w http.ResponseWriter, r *http.Request) {
+	doc, err := a.jsonResume()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting CV from database: %s\n", err.Error())
+		return
+	}
+
+	file, err := a.cvPDF(doc)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error rendering CV PDF: %s\n", err.Error())
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error getting info for CV PDF: %s\n", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="cv.pdf"`)
+	http.ServeContent(w, r, "cv.pdf", info.ModTime(), file)
+}
+
+// UpdateCV handles requests to replace the structured CV. The PDF version is
+// rendered again straight away.
+//
+// Requires a valid auth token.
+func (a API) UpdateCV(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var cv entities.CV
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&cv); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in CV update request: %s\n", err.Error())
+		return
+	}
+
+	if err := validateCV(&cv); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.UpdateCV(&cv); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating CV in database: %s\n", err.Error())
+		return
+	}
+
+	doc, err := a.jsonResume()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting CV from database: %s\n", err.Error())
+		return
+	}
+
+	// The CV has been saved either way, and the PDF is rendered again when
+	// it's next requested
+	if file, err := a.cvPDF(doc); err != nil {
+		a.log.Errorf("error rendering CV PDF: %s\n", err.Error())
+	} else {
+		file.Close()
+	}
+
+	w.WriteHeader(200)
+}
+
+// jsonResume gets the CV as a JSON Resume document.
+func (a API) jsonResume() (*resume.Resume, error) {
+	cv, err := a.db.GetCV()
+	if err != nil {
+		return nil, err
+	}
+
+	about, err := a.db.GetAbout()
+	if err != nil {
+		return nil, err
+	}
+
+	return resume.FromCV(cv, about), nil
+}
+
+// cvDir is the directory that the CV PDF is cached in.
+func (a API) cvDir() string {
+	return filepath.Join(a.cacheDir, "cv")
+}
+
+// cvPDF opens the PDF of a JSON Resume document, rendering it if there's no
+// cached copy. Cached copies are named after a hash of the document, so any
+// change to it makes a new PDF.
+func (a API) cvPDF(doc *resume.Resume) (*os.File, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(b)
+	name := hex.EncodeToString(sum[:8]) + ".pdf"
+
+	return cachedFile(a.cvDir(), name, "*.pdf", func(w io.Writer) error {
+		return resume.WritePDF(w, doc)
+	})
+}
+
+// validateCV checks that a CV has a name, and that every entry has the
+// fields it needs with valid dates and URLs. Whitespace is trimmed from the
+// text fields.
+func validateCV(cv *entities.CV) error {
+	cv.Name = strings.TrimSpace(cv.Name)
+	cv.Headline = strings.TrimSpace(cv.Headline)
+	cv.Email = strings.TrimSpace(cv.Email)
+	cv.Website = strings.TrimSpace(cv.Website)
+	cv.Location = strings.TrimSpace(cv.Location)
+	cv.Summary = strings.TrimSpace(cv.Summary)
+	cv.UpdatedAt = time.Time{}
+
+	if cv.Name == "" {
+		return errors.New("a CV needs a name")
+	}
+
+	if cv.Email != "" && !isEmail(cv.Email) {
+		return errors.New("email is not a valid email address")
+	}
+
+	if err := validateCVURL(cv.Website); err != nil {
+		return err
+	}
+
+	for _, e := range cv.Experience {
+		if e == nil {
+			return errors.New("experience entries can't be null")
+		}
+
+		e.Organisation = strings.TrimSpace(e.Organisation)
+		e.Position = strings.TrimSpace(e.Position)
+		e.Location = strings.TrimSpace(e.Location)
+		e.Summary = strings.TrimSpace(e.Summary)
+		e.Highlights = trimStrings(e.Highlights)
+
+		if e.Organisation == "" || e.Position == "" {
+			return errors.New("every experience entry needs an organisation and a position")
+		}
+
+		if e.StartDate == "" {
+			return errors.New("every experience entry needs a start date")
+		}
+
+		if err := validateCVDates(e.StartDate, e.EndDate); err != nil {
+			return err
+		}
+
+		if err := validateCVURL(e.URL); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range cv.Education {
+		if e == nil {
+			return errors.New("education entries can't be null")
+		}
+
+		e.Institution = strings.TrimSpace(e.Institution)
+		e.Area = strings.TrimSpace(e.Area)
+		e.StudyType = strings.TrimSpace(e.StudyType)
+
+		if e.Institution == "" {
+			return errors.New("every education entry needs an institution")
+		}
+
+		if err := validateCVDates(e.StartDate, e.EndDate); err != nil {
+			return err
+		}
+
+		if err := validateCVURL(e.URL); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range cv.Skills {
+		if s == nil {
+			return errors.New("skills can't be null")
+		}
+
+		s.Name = strings.TrimSpace(s.Name)
+		s.Level = strings.TrimSpace(s.Level)
+		s.Keywords = trimStrings(s.Keywords)
+
+		if s.Name == "" {
+			return errors.New("every skill needs a name")
+		}
+	}
+
+	for _, e := range cv.Exhibitions {
+		if e == nil {
+			return errors.New("exhibitions can't be null")
+		}
+
+		e.Title = strings.TrimSpace(e.Title)
+		e.Venue = strings.TrimSpace(e.Venue)
+		e.Location = strings.TrimSpace(e.Location)
+		e.Description = strings.TrimSpace(e.Description)
+
+		if e.Title == "" || e.Date == "" {
+			return errors.New("every exhibition needs a title and a date")
+		}
+
+		if err := validateCVDates(e.Date, ""); err != nil {
+			return err
+		}
+
+		if err := validateCVURL(e.URL); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range cv.Publications {
+		if p == nil {
+			return errors.New("publications can't be null")
+		}
+
+		p.Name = strings.TrimSpace(p.Name)
+		p.Publisher = strings.TrimSpace(p.Publisher)
+		p.Summary = strings.TrimSpace(p.Summary)
+
+		if p.Name == "" {
+			return errors.New("every publication needs a name")
+		}
+
+		if err := validateCVDates(p.ReleaseDate, ""); err != nil {
+			return err
+		}
+
+		if err := validateCVURL(p.URL); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateCVDates checks that the dates of a CV entry are valid, and that the
+// start date isn't after the end date. Either date can be empty.
+func validateCVDates(start, end string) error {
+	for _, date := range []string{start, end} {
+		if date == "" {
+			continue
+		}
+
+		if !cvDatePattern.MatchString(date) {
+			return errors.New("dates must be formatted as YYYY, YYYY-MM, or YYYY-MM-DD: " + date)
+		}
+
+		// Fill in a missing month and day so the date can be parsed
+		full := date + "-01-01"[len(date)-4:]
+		if _, err := time.Parse("2006-01-02", full); err != nil {
+			return errors.New("invalid date: " + date)
+		}
+	}
+
+	// Dates in this format sort the same as strings, as long as they're cut
+	// to the same precision
+	if start != "" && end != "" {
+		n := len(start)
+		if len(end) < n {
+			n = len(end)
+		}
+
+		if start[:n] > end[:n] {
+			return errors.New("start dates can't be after end dates")
+		}
+	}
+
+	return nil
+}
+
+// validateCVURL checks that a URL on a CV is an absolute HTTP URL, if it's
+// set.
+func validateCVURL(u string) error {
+	if u != "" && !isHTTPURL(u) {
+		return errors.New("CV URLs must be absolute http or https URLs: " + u)
+	}
+
+	return nil
+}
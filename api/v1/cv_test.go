@@ -0,0 +1,29 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// TestValidateCV_Null ensures that null entries in the lists on a CV are
+// rejected instead of crashing the handler.
+func TestValidateCV_Null(t *testing.T) {
+	for _, list := range []string{"experience", "education", "skills", "exhibitions", "publications"} {
+		// Given
+		var cv entities.CV
+		body := `{"name": "Ada", "` + list + `": [null]}`
+		if err := json.Unmarshal([]byte(body), &cv); err != nil {
+			t.Fatalf("%s: unexpected error decoding CV: %s\n", list, err)
+		}
+
+		// When
+		err := validateCV(&cv)
+
+		// Then
+		if err == nil {
+			t.Fatalf("%s: expected an error\n", list)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/signing"
+)
+
+const (
+	// defaultDownloadExpiry is how long a download link lasts if no expiry is
+	// given.
+	defaultDownloadExpiry = 24 * time.Hour
+
+	// maxDownloadExpiry is the longest a download link can last.
+	maxDownloadExpiry = 30 * 24 * time.Hour
+
+	// downloadRequests is how many requests a download link allows for each
+	// of its downloads, so a download can be resumed a few times.
+	downloadRequests = 5
+)
+
+// Download handles requests to download an original file through a signed
+// download link. The file is only sent if the signature is valid, and the
+// link hasn't expired, been revoked, or run out of downloads.
+//
+// Requests for a single range that starts part way into the file resume an
+// earlier download, so they aren't counted as another download. Every
+// request still counts against the link's request allowance.
+func (a API) Download(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+
+	if err := a.signer.Verify(values, time.Now()); err != nil {
+		if err == signing.ErrExpired {
+			WriteError(w, "this download link has expired", http.StatusGone)
+			return
+		}
+
+		WriteError(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.ParseUint(values.Get("id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	directory := values.Get("dir")
+	fileName := values.Get("file")
+
+	path, err := a.downloadPath(directory, fileName)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			WriteError(w, "file not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error opening file to download: %s\n", err.Error())
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error getting info for file to download: %s\n", err.Error())
+		return
+	}
+
+	resume := resumesDownload(r.Header.Get("Range"))
+	if err := a.db.UseDownloadLink(uint(id), directory, fileName, resume, downloadRequests); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "this download link can no longer be used", http.StatusGone)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error using download link in database: %s\n", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, fileName, info.ModTime(), file)
+}
+
+// resumesDownload checks if a Range header asks for a single range that
+// starts part way into the file, like when a download is resumed. Anything
+// else, including several ranges, is treated as a new download.
+func resumesDownload(header string) bool {
+	if !strings.HasPrefix(header, "bytes=") {
+		return false
+	}
+
+	ranges := strings.TrimPrefix(header, "bytes=")
+	if strings.Contains(ranges, ",") {
+		return false
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(ranges), "-", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	start, err := strconv.ParseInt(parts[0], 10, 64)
+	return err == nil && start > 0
+}
+
+// GetDownloadLinks handles requests to get all download links, along with
+// their URLs.
+//
+// Requires a valid auth token.
+func (a API) GetDownloadLinks(w http.ResponseWriter, r *http.Request) {
+	ret, err := a.db.GetDownloadLinks()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting download links from database: %s\n", err.Error())
+		return
+	}
+
+	for _, link := range ret {
+		link.URL = a.downloadURL(r, link)
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// AddDownloadLink handles requests to create a signed download link for an
+// original file in the images or resources directory.
+//
+// Requires a valid auth token.
+func (a API) AddDownloadLink(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req AddDownloadLinkRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in add download link request: %s\n", err.Error())
+		return
+	}
+
+	path, err := a.downloadPath(req.Directory, req.FileName)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		WriteError(w, "file not found", http.StatusBadRequest)
+		return
+	}
+
+	expiry := defaultDownloadExpiry
+	if req.ExpiresIn != 0 {
+		expiry = time.Duration(req.ExpiresIn) * time.Second
+	}
+
+	if expiry <= 0 || expiry > maxDownloadExpiry {
+		WriteError(w, fmt.Sprintf("expiresIn must be between 1 and %d seconds", int64(maxDownloadExpiry.Seconds())), http.StatusBadRequest)
+		return
+	}
+
+	if req.MaxDownloads.Valid && req.MaxDownloads.Int32 < 1 {
+		WriteError(w, "maxDownloads must be at least 1", http.StatusBadRequest)
+		return
+	}
+
+	link, err := a.db.AddDownloadLink(&entities.DownloadLink{
+		Directory:    req.Directory,
+		FileName:     req.FileName,
+		MaxDownloads: req.MaxDownloads,
+		// Signatures only hold whole seconds
+		ExpiresAt: time.Now().Add(expiry).Truncate(time.Second),
+	})
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding download link to database: %s\n", err.Error())
+		return
+	}
+
+	link.URL = a.downloadURL(r, link)
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(link)
+}
+
+// RevokeDownloadLink handles requests to stop a download link from being
+// used, before it expires.
+//
+// Requires a valid auth token.
+func (a API) RevokeDownloadLink(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.RevokeDownloadLink(uint(id)); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "download link not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error revoking download link in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// downloadPath gets the path to a file in one of the directories that
+// download links can point into.
+func (a API) downloadPath(directory, fileName string) (string, error) {
+	if fileName == "" || filepath.Base(fileName) != fileName {
+		return "", errors.New("invalid file name")
+	}
+
+	switch directory {
+	case entities.DownloadImages:
+		return filepath.Join(a.imageDir, fileName), nil
+	case entities.DownloadResources:
+		return filepath.Join(a.resourcesDir, fileName), nil
+	default:
+		return "", errors.New("directory must be images or resources")
+	}
+}
+
+// downloadURL creates the signed URL for a download link. The URL is relative
+// to the host the request was sent to.
+func (a API) downloadURL(r *http.Request, link *entities.DownloadLink) string {
+	values := url.Values{
+		"id":   {strconv.FormatUint(uint64(link.ID), 10)},
+		"dir":  {link.Directory},
+		"file": {link.FileName},
+	}
+	a.signer.Sign(values, link.ExpiresAt)
+
+	// The admin routes are mounted under the API root
+	root := r.URL.Path
+	if i := strings.Index(root, "/admin/"); i >= 0 {
+		root = root[:i]
+	}
+
+	return root + "/download?" + values.Encode()
+}
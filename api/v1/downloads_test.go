@@ -0,0 +1,38 @@
+package v1
+
+import "testing"
+
+// TestResumesDownload ensures that only a single range starting part way
+// into a file is treated as a resumed download.
+func TestResumesDownload(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{"", false},
+		{"bytes=0-", false},
+		{"bytes=0-499", false},
+		{"bytes=0-99, 200-299", false},
+		{"bytes=500-", true},
+		{"bytes=500-999", true},
+		{" bytes=500-", false},
+		{"items=500-", false},
+		// Suffix ranges can fetch the whole file, so they're new downloads
+		{"bytes=-500", false},
+		// Several ranges can include the start of the file
+		{"bytes=200-299, 0-99", false},
+		{"bytes=1-,0-0", false},
+		{"bytes=1-,2-", false},
+		{"bytes=abc-", false},
+	}
+
+	for _, test := range tests {
+		// When
+		result := resumesDownload(test.header)
+
+		// Then
+		if result != test.expected {
+			t.Fatalf("result for %q does not match expected: got %v, expected: %v\n", test.header, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/phash"
+)
+
+// GetDuplicateImages handles requests for a report of images that look alike.
+// Every image used on the site is compared, whether it's a photo, a project
+// image, or a project thumbnail.
+//
+// Requires a valid auth token.
+func (a API) GetDuplicateImages(w http.ResponseWriter, r *http.Request) {
+	uses, err := a.db.GetImageUses()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting image uses from database: %s\n", err.Error())
+		return
+	}
+
+	usesByFile := make(map[string][]*entities.ImageUse)
+	files := make([]string, 0, len(uses))
+	for _, use := range uses {
+		if _, ok := usesByFile[use.FileName]; !ok {
+			files = append(files, use.FileName)
+		}
+		usesByFile[use.FileName] = append(usesByFile[use.FileName], use)
+	}
+
+	hashes, err := a.hashImages(files)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error hashing images: %s\n", err.Error())
+		return
+	}
+
+	ret := make([][]*entities.ImageUse, 0)
+	for _, cluster := range phash.Cluster(hashes) {
+		group := make([]*entities.ImageUse, 0, len(cluster))
+		for _, file := range cluster {
+			group = append(group, usesByFile[file]...)
+		}
+		ret = append(ret, group)
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// checkDuplicates looks for images that are about to be added which look like
+// images already used on the site, or like each other. If any are found and
+// the API is set to reject duplicates, an error is sent to the client and
+// false is returned.
+func (a API) checkDuplicates(w http.ResponseWriter, files []string) ([]*entities.DuplicateImage, bool) {
+	duplicates, err := a.findDuplicates(files)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error checking for duplicate images: %s\n", err.Error())
+		return nil, false
+	}
+
+	if len(duplicates) > 0 && a.config.RejectDuplicates {
+		messages := make([]string, 0, len(duplicates))
+		for _, duplicate := range duplicates {
+			messages = append(messages, duplicate.FileName+" looks like "+strings.Join(duplicate.Matches, ", "))
+		}
+
+		WriteError(w, "near-duplicate images: "+strings.Join(messages, "; "), http.StatusConflict)
+		return nil, false
+	}
+
+	for _, duplicate := range duplicates {
+		a.log.Warnf("image '%s' looks like: %s\n", duplicate.FileName, strings.Join(duplicate.Matches, ", "))
+	}
+
+	return duplicates, true
+}
+
+// findDuplicates compares new image files against every image used on the
+// site and against each other, returning the new files that look like
+// another image.
+func (a API) findDuplicates(files []string) ([]*entities.DuplicateImage, error) {
+	uses, err := a.db.GetImageUses()
+	if err != nil {
+		return nil, err
+	}
+
+	all := make([]string, 0, len(uses)+len(files))
+	for _, use := range uses {
+		all = append(all, use.FileName)
+	}
+	all = append(all, files...)
+
+	hashes, err := a.hashImages(all)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*entities.DuplicateImage, 0)
+	for _, file := range files {
+		hash, ok := hashes[file]
+		if !ok {
+			continue
+		}
+
+		matches := make([]string, 0)
+		for other, otherHash := range hashes {
+			if other != file && phash.Similar(hash, otherHash) {
+				matches = append(matches, other)
+			}
+		}
+
+		if len(matches) > 0 {
+			sort.Strings(matches)
+			ret = append(ret, &entities.DuplicateImage{FileName: file, Matches: matches})
+		}
+	}
+
+	return ret, nil
+}
+
+// hashImages gets the perceptual hashes for the given image files. Stored
+// hashes are reused as long as the file hasn't changed since it was hashed,
+// and new hashes are stored for next time. Files that can't be hashed, such
+// as missing files or files that aren't images, are skipped.
+func (a API) hashImages(files []string) (map[string]uint64, error) {
+	stored, err := a.db.GetImageHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]uint64, len(files))
+	updated := make([]*entities.ImageHash, 0)
+	for _, file := range files {
+		if _, ok := ret[file]; ok {
+			continue
+		}
+
+		path := filepath.Join(a.imageDir, file)
+		info, err := os.Stat(path)
+		if err != nil {
+			a.log.Warnf("unable to hash image '%s': %s\n", file, err.Error())
+			continue
+		}
+
+		// The database only stores times to the microsecond
+		modTime := info.ModTime().UTC().Truncate(time.Microsecond)
+		if hash, ok := stored[file]; ok && hash.Size == info.Size() && hash.ModTime.Equal(modTime) {
+			ret[file] = uint64(hash.Hash)
+			continue
+		}
+
+		hash, err := phash.HashFile(path)
+		if err != nil {
+			a.log.Warnf("unable to hash image '%s': %s\n", file, err.Error())
+			continue
+		}
+
+		ret[file] = hash
+		updated = append(updated, &entities.ImageHash{
+			FileName: file,
+			Hash:     int64(hash),
+			Size:     info.Size(),
+			ModTime:  modTime,
+		})
+	}
+
+	if len(updated) > 0 {
+		if err := a.db.SetImageHashes(updated); err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
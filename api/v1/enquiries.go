@@ -0,0 +1,439 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid"
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+const (
+	// maxEnquiryAttachments is the most files that can be sent with an
+	// enquiry.
+	maxEnquiryAttachments = 5
+
+	// maxAttachmentSize is the largest an enquiry attachment can be.
+	maxAttachmentSize = 10 * 1024 * 1024
+
+	// maxEnquirySize is the largest an enquiry request can be, leaving room
+	// for the form fields on top of the attachments.
+	maxEnquirySize = maxEnquiryAttachments*maxAttachmentSize + 1024*1024
+
+	maxEnquiryDescriptionLength = 10000
+
+	// enquiryRateLimit is how many enquiries can be sent from an IP address
+	// within enquiryRateWindow.
+	enquiryRateLimit  = 5
+	enquiryRateWindow = time.Hour
+)
+
+// attachmentTypes are the types of file that can be attached to an enquiry,
+// along with the extension they're stored with.
+var attachmentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// SendEnquiry handles commission enquiries sent by clients. The enquiry is a
+// multipart form, with any reference files as attachments. Attachments are
+// stored privately, and can only be downloaded by admins. Enquiries share the
+// contact form's spam protection, but have their own rate limit.
+//
+// The rate limit and the form token are checked before the form is read, so
+// that nobody can make the server buffer large uploads without them. The
+// token is sent in the X-Contact-Token header, or the token query parameter.
+func (a API) SendEnquiry(w http.ResponseWriter, r *http.Request) {
+	if !a.enquiryLimiter.Allow(clientIP(r), time.Now()) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(enquiryRateWindow.Seconds())))
+		WriteError(w, "too many messages have been sent, please try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	if err := a.checkContactToken(enquiryToken(r), time.Now()); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxEnquirySize)
+	if err := r.ParseMultipartForm(8 * 1024 * 1024); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	if r.FormValue("website") != "" {
+		w.WriteHeader(200)
+		return
+	}
+
+	enquiry, err := newEnquiry(r, time.Now())
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	files := r.MultipartForm.File["attachments"]
+	if len(files) > maxEnquiryAttachments {
+		WriteError(w, fmt.Sprintf("enquiries can't have more than %d attachments", maxEnquiryAttachments), http.StatusBadRequest)
+		return
+	}
+
+	if err := os.MkdirAll(a.enquiryDir(), 0700); err != nil {
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error creating enquiry attachment directory: %s\n", err.Error())
+		return
+	}
+
+	for _, header := range files {
+		attachment, status, err := a.saveAttachment(header)
+		if err != nil {
+			a.removeAttachments(enquiry.Attachments)
+			WriteError(w, err.Error(), status)
+			return
+		}
+
+		enquiry.Attachments = append(enquiry.Attachments, attachment)
+	}
+
+	if _, err := a.db.AddEnquiry(enquiry); err != nil {
+		a.removeAttachments(enquiry.Attachments)
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding enquiry to database: %s\n", err.Error())
+		return
+	}
+
+	body := fmt.Sprintf("Name: %s\nEmail: %s\nProject type: %s\nAttachments: %d\n\n%s\n",
+		enquiry.Name, enquiry.Email, enquiry.ProjectType, len(enquiry.Attachments), enquiry.Description)
+	go a.notify("New enquiry from "+enquiry.Name, &mail.Address{Name: enquiry.Name, Address: enquiry.Email}, body)
+
+	w.WriteHeader(200)
+}
+
+// enquiryToken gets the contact form token sent with an enquiry, from either
+// the X-Contact-Token header or the token query parameter.
+func enquiryToken(r *http.Request) string {
+	if token := r.Header.Get("X-Contact-Token"); token != "" {
+		return token
+	}
+
+	return r.URL.Query().Get("token")
+}
+
+// GetEnquiries handles requests to get all commission enquiries, newest
+// first.
+//
+// Requires a valid auth token.
+func (a API) GetEnquiries(w http.ResponseWriter, r *http.Request) {
+	ret, err := a.db.GetEnquiries()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting enquiries from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// GetEnquiry handles requests to get a commission enquiry, along with its
+// attachments.
+//
+// Requires a valid auth token.
+func (a API) GetEnquiry(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := a.db.GetEnquiry(uint(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "enquiry not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting enquiry from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// GetEnquiryAttachment handles requests to download a file attached to a
+// commission enquiry.
+//
+// Requires a valid auth token.
+func (a API) GetEnquiryAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	attachmentID, err := strconv.ParseUint(chi.URLParam(r, "attachmentID"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	attachment, err := a.db.GetEnquiryAttachment(uint(id), uint(attachmentID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "attachment not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting enquiry attachment from database: %s\n", err.Error())
+		return
+	}
+
+	file, err := os.Open(filepath.Join(a.enquiryDir(), attachment.StoredName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			WriteError(w, "attachment not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error opening enquiry attachment: %s\n", err.Error())
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error getting info for enquiry attachment: %s\n", err.Error())
+		return
+	}
+
+	// Attachments come from anyone, so they're never shown inline
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.FileName))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, attachment.FileName, info.ModTime(), file)
+}
+
+// RemoveEnquiry handles requests to remove a commission enquiry, along with
+// its attachments.
+//
+// Requires a valid auth token.
+func (a API) RemoveEnquiry(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	enquiry, err := a.db.GetEnquiry(uint(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "enquiry not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting enquiry from database: %s\n", err.Error())
+		return
+	}
+
+	if err := a.db.RemoveEnquiry(uint(id)); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error removing enquiry from database: %s\n", err.Error())
+		return
+	}
+
+	a.removeAttachments(enquiry.Attachments)
+
+	w.WriteHeader(200)
+}
+
+// enquiryDir is the private directory that enquiry attachments are stored
+// in.
+func (a API) enquiryDir() string {
+	return filepath.Join(a.privateDir, "enquiries")
+}
+
+// saveAttachment checks that an uploaded file can be attached to an enquiry,
+// and saves it under a random name. The type of the file is worked out from
+// its contents, not from what the client says it is. It returns the HTTP
+// status code to respond with if the file can't be saved.
+func (a API) saveAttachment(header *multipart.FileHeader) (*entities.EnquiryAttachment, int, error) {
+	name := strings.TrimSpace(filepath.Base(header.Filename))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		name = "attachment"
+	}
+
+	if header.Size > maxAttachmentSize {
+		return nil, http.StatusBadRequest, fmt.Errorf("%s is too big, attachments can't be larger than %d MB", name, maxAttachmentSize/1024/1024)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	defer file.Close()
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, http.StatusBadRequest, err
+	}
+
+	contentType := http.DetectContentType(sniff[:n])
+	ext, ok := attachmentTypes[contentType]
+	if !ok {
+		return nil, http.StatusBadRequest, fmt.Errorf("%s can't be attached, attachments must be JPEG, PNG, GIF, or WebP images, or PDFs", name)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	attachment := &entities.EnquiryAttachment{
+		FileName:    name,
+		StoredName:  id.String() + ext,
+		ContentType: contentType,
+		Size:        header.Size,
+	}
+
+	if err := saveFile(file, filepath.Join(a.enquiryDir(), attachment.StoredName)); err != nil {
+		a.log.Errorf("error saving enquiry attachment: %s\n", err.Error())
+		return nil, http.StatusInternalServerError, errors.New("unable to save attachment")
+	}
+
+	return attachment, http.StatusOK, nil
+}
+
+// removeAttachments removes the files of enquiry attachments.
+func (a API) removeAttachments(attachments []*entities.EnquiryAttachment) {
+	for _, attachment := range attachments {
+		if err := os.Remove(filepath.Join(a.enquiryDir(), attachment.StoredName)); err != nil && !os.IsNotExist(err) {
+			a.log.Warnf("unable to remove enquiry attachment: %s\n", err.Error())
+		}
+	}
+}
+
+// newEnquiry builds a commission enquiry from the fields of an enquiry form,
+// checking that they're valid. Deadlines can't be before the given time.
+func newEnquiry(r *http.Request, now time.Time) (*entities.Enquiry, error) {
+	enquiry := &entities.Enquiry{
+		Name:        strings.TrimSpace(r.FormValue("name")),
+		Email:       strings.TrimSpace(r.FormValue("email")),
+		ProjectType: strings.TrimSpace(r.FormValue("projectType")),
+		Description: strings.TrimSpace(r.FormValue("description")),
+		Attachments: make([]*entities.EnquiryAttachment, 0),
+	}
+
+	if enquiry.Name == "" {
+		return nil, errors.New("please enter your name")
+	}
+
+	if len([]rune(enquiry.Name)) > maxContactNameLength {
+		return nil, fmt.Errorf("name can't be longer than %d characters", maxContactNameLength)
+	}
+
+	if !isEmail(enquiry.Email) {
+		return nil, errors.New("please enter a valid email address")
+	}
+
+	switch enquiry.ProjectType {
+	case entities.EnquiryBranding, entities.EnquiryIllustration, entities.EnquiryPhotography,
+		entities.EnquiryPrint, entities.EnquiryWeb, entities.EnquiryOther:
+	default:
+		return nil, errors.New("project type must be one of branding, illustration, photography, print, web, or other")
+	}
+
+	var err error
+	if enquiry.BudgetMin, err = parseBudget(r.FormValue("budgetMin")); err != nil {
+		return nil, err
+	}
+
+	if enquiry.BudgetMax, err = parseBudget(r.FormValue("budgetMax")); err != nil {
+		return nil, err
+	}
+
+	if enquiry.BudgetMin.Valid && enquiry.BudgetMax.Valid && enquiry.BudgetMin.Int32 > enquiry.BudgetMax.Int32 {
+		return nil, errors.New("the lowest budget can't be more than the highest budget")
+	}
+
+	if value := strings.TrimSpace(r.FormValue("deadline")); value != "" {
+		deadline, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, errors.New("deadline must be formatted as YYYY-MM-DD")
+		}
+
+		// Dates compare the same as strings in this format
+		if value < now.Format("2006-01-02") {
+			return nil, errors.New("deadline can't be in the past")
+		}
+
+		enquiry.Deadline.Time = deadline
+		enquiry.Deadline.Valid = true
+	}
+
+	if enquiry.Description == "" {
+		return nil, errors.New("please describe your project")
+	}
+
+	if len([]rune(enquiry.Description)) > maxEnquiryDescriptionLength {
+		return nil, fmt.Errorf("description can't be longer than %d characters", maxEnquiryDescriptionLength)
+	}
+
+	return enquiry, nil
+}
+
+// parseBudget parses one end of an enquiry's budget range, which can be left
+// empty.
+func parseBudget(value string) (db.NullInt, error) {
+	var ret db.NullInt
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ret, nil
+	}
+
+	budget, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || budget < 0 {
+		return ret, errors.New("budgets must be whole numbers that aren't negative")
+	}
+
+	ret.Int32 = int32(budget)
+	ret.Valid = true
+	return ret, nil
+}
@@ -0,0 +1,170 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// enquiryRequest makes a request with the given enquiry form fields.
+func enquiryRequest(fields map[string]string) *http.Request {
+	form := url.Values{}
+	for key, value := range fields {
+		form.Set(key, value)
+	}
+
+	r := httptest.NewRequest("POST", "/enquiries", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+// validEnquiry returns the fields of a valid enquiry, with the given fields
+// changed.
+func validEnquiry(changes map[string]string) map[string]string {
+	fields := map[string]string{
+		"name":        "Ada",
+		"email":       "ada@example.com",
+		"projectType": "branding",
+		"description": "A new logo",
+	}
+
+	for key, value := range changes {
+		fields[key] = value
+	}
+
+	return fields
+}
+
+// TestNewEnquiry ensures that enquiry forms are validated.
+func TestNewEnquiry(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		changes map[string]string
+		valid   bool
+	}{
+		{"valid", nil, true},
+		{"no name", map[string]string{"name": "  "}, false},
+		{"long name", map[string]string{"name": strings.Repeat("a", maxContactNameLength+1)}, false},
+		{"bad email", map[string]string{"email": "Ada <ada@example.com>"}, false},
+		{"unknown project type", map[string]string{"projectType": "sculpture"}, false},
+		{"budget range", map[string]string{"budgetMin": "100", "budgetMax": "500"}, true},
+		{"open budget", map[string]string{"budgetMax": "500"}, true},
+		{"backwards budget", map[string]string{"budgetMin": "500", "budgetMax": "100"}, false},
+		{"deadline today", map[string]string{"deadline": "2026-10-19"}, true},
+		{"deadline in the past", map[string]string{"deadline": "2026-10-18"}, false},
+		{"bad deadline", map[string]string{"deadline": "19/10/2026"}, false},
+		{"no description", map[string]string{"description": ""}, false},
+		{"long description", map[string]string{"description": strings.Repeat("a", maxEnquiryDescriptionLength+1)}, false},
+	}
+
+	for _, test := range tests {
+		// Given
+		r := enquiryRequest(validEnquiry(test.changes))
+
+		// When
+		enquiry, err := newEnquiry(r, now)
+
+		// Then
+		if test.valid && err != nil {
+			t.Fatalf("%s: unexpected error: %s\n", test.name, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Fatalf("%s: expected an error, got %+v\n", test.name, enquiry)
+		}
+	}
+}
+
+// TestNewEnquiry_Trimmed ensures that whitespace is trimmed from the fields
+// of an enquiry.
+func TestNewEnquiry_Trimmed(t *testing.T) {
+	// Given
+	r := enquiryRequest(validEnquiry(map[string]string{"name": " Ada ", "description": " A new logo\n"}))
+
+	// When
+	enquiry, err := newEnquiry(r, time.Now())
+
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err)
+	}
+
+	if enquiry.Name != "Ada" || enquiry.Description != "A new logo" {
+		t.Fatalf("result does not match expected: got %q and %q\n", enquiry.Name, enquiry.Description)
+	}
+
+	if enquiry.Attachments == nil {
+		t.Fatalf("expected an empty list of attachments, got nil\n")
+	}
+}
+
+// TestParseBudget ensures that budgets are whole numbers that aren't
+// negative, and can be left out.
+func TestParseBudget(t *testing.T) {
+	tests := []struct {
+		value    string
+		valid    bool
+		set      bool
+		expected int32
+	}{
+		{"", true, false, 0},
+		{"  ", true, false, 0},
+		{"0", true, true, 0},
+		{" 2500 ", true, true, 2500},
+		{"-1", false, false, 0},
+		{"12.50", false, false, 0},
+		{"lots", false, false, 0},
+		{"99999999999", false, false, 0},
+	}
+
+	for _, test := range tests {
+		// When
+		result, err := parseBudget(test.value)
+
+		// Then
+		if test.valid != (err == nil) {
+			t.Fatalf("error for %q does not match expected: got %v, expected valid: %v\n", test.value, err, test.valid)
+		}
+
+		if result.Valid != test.set || result.Int32 != test.expected {
+			t.Fatalf("result for %q does not match expected: got %+v, expected: %d\n", test.value, result, test.expected)
+		}
+	}
+}
+
+// TestEnquiryToken ensures that the form token is taken from the header
+// before the query string.
+func TestEnquiryToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		query    string
+		expected string
+	}{
+		{"header", "from-header", "", "from-header"},
+		{"query", "", "from-query", "from-query"},
+		{"both", "from-header", "from-query", "from-header"},
+		{"neither", "", "", ""},
+	}
+
+	for _, test := range tests {
+		// Given
+		r := httptest.NewRequest("POST", "/enquiries?token="+url.QueryEscape(test.query), nil)
+		if test.header != "" {
+			r.Header.Set("X-Contact-Token", test.header)
+		}
+
+		// When
+		result := enquiryToken(r)
+
+		// Then
+		if result != test.expected {
+			t.Fatalf("%s: result does not match expected: got %q, expected: %q\n", test.name, result, test.expected)
+		}
+	}
+}
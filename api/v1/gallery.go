@@ -1,15 +1,28 @@
 package v1
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/database"
 	"github.com/nicolekellydesign/webby-api/entities"
 	"github.com/nicolekellydesign/webby-api/internal/db"
+	"github.com/nicolekellydesign/webby-api/internal/patch"
+)
+
+// The range of years a project can be dated to.
+const (
+	minProjectYear = 1900
+	maxProjectYear = 2100
 )
 
 // AddGalleryItem handles a request to add a new gallery item.
@@ -60,9 +73,15 @@ func (a API) AddGalleryItem(w http.ResponseWriter, r *http.Request) {
 			String: videoKey,
 			Valid:  videoKey != "",
 		},
+		Published: true,
 	}
 
 	if err := a.db.AddGalleryItem(galleryItem); err != nil {
+		if err == database.ErrProjectExists {
+			WriteError(w, "a project with that name already exists", http.StatusConflict)
+			return
+		}
+
 		WriteError(w, dbError, http.StatusInternalServerError)
 		a.log.Errorf("error adding gallery item to database: %s\n", err.Error())
 		return
@@ -99,10 +118,21 @@ func (a API) GetProject(w http.ResponseWriter, r *http.Request) {
 
 	ret, err := a.db.GetProject(id)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "project not found", http.StatusNotFound)
+			return
+		}
+
 		WriteError(w, dbError, http.StatusInternalServerError)
 		return
 	}
 
+	// Drafts aren't visible to the public
+	if !ret.Published {
+		WriteError(w, "project not found", http.StatusNotFound)
+		return
+	}
+
 	// Send back the response
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(200)
@@ -123,6 +153,16 @@ func (a API) UpdateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := validateProjectMetadata(&project); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.validateProjectSEO(&project); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if err := a.db.UpdateProject(&project); err != nil {
 		WriteError(w, dbError, http.StatusInternalServerError)
 		return
@@ -131,12 +171,169 @@ func (a API) UpdateProject(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(200)
 }
 
-// GetGalleryItems handles a request to get all gallery items from the
-// database.
+// PatchProject handles requests to partially update a portfolio project. The
+// body is a JSON Merge Patch (RFC 7386): members that are absent are left
+// unchanged, and members set to null are cleared.
+func (a API) PatchProject(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error reading project patch body: %s\n", err.Error())
+		return
+	}
+
+	// The images, credits, and testimonials have their own endpoints
+	if err := checkPatchMembers(body, "thumbnail", "images", "credits", "testimonials"); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Check that the patch is valid before it's applied to the stored project
+	merged, err := patch.Merge(nil, body)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error applying project patch: %s\n", err.Error())
+		return
+	}
+
+	var changes entities.GalleryItem
+	if err := json.Unmarshal(merged, &changes); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding patched project: %s\n", err.Error())
+		return
+	}
+
+	// Validation errors are kept apart from database errors, so they can be
+	// sent back to the client
+	var invalid error
+	err = a.db.PatchProject(id, body, func(updated *entities.GalleryItem) error {
+		if invalid = validateProjectMetadata(updated); invalid != nil {
+			return invalid
+		}
+
+		invalid = a.validateProjectSEO(updated)
+		return invalid
+	})
+	if invalid != nil {
+		WriteError(w, invalid.Error(), http.StatusBadRequest)
+		return
+	} else if err == sql.ErrNoRows {
+		WriteError(w, "project not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error patching project in database: %s\n", err.Error())
+		return
+	}
+
+	updated, err := a.db.GetProject(id)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting project from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(updated)
+}
+
+// validateProjectMetadata checks the metadata fields of a project, clearing
+// any that were sent as empty values.
+func validateProjectMetadata(project *entities.GalleryItem) error {
+	// Zero years and empty strings mean the field isn't set
+	project.YearStart.Valid = project.YearStart.Valid && project.YearStart.Int32 != 0
+	project.YearEnd.Valid = project.YearEnd.Valid && project.YearEnd.Int32 != 0
+
+	project.Client.String = strings.TrimSpace(project.Client.String)
+	project.Client.Valid = project.Client.String != ""
+
+	project.LiveURL.String = strings.TrimSpace(project.LiveURL.String)
+	project.LiveURL.Valid = project.LiveURL.String != ""
+
+	if project.YearStart.Valid && (project.YearStart.Int32 < minProjectYear || project.YearStart.Int32 > maxProjectYear) {
+		return fmt.Errorf("year must be between %d and %d", minProjectYear, maxProjectYear)
+	}
+
+	if project.YearEnd.Valid {
+		if !project.YearStart.Valid {
+			return errors.New("an end year needs a start year")
+		}
+
+		if project.YearEnd.Int32 < project.YearStart.Int32 || project.YearEnd.Int32 > maxProjectYear {
+			return fmt.Errorf("end year must be between the start year and %d", maxProjectYear)
+		}
+	}
+
+	if project.LiveURL.Valid && !isHTTPURL(project.LiveURL.String) {
+		return errors.New("live site URL must be an http or https URL")
+	}
+
+	project.Services = trimStrings(project.Services)
+
+	for i, link := range project.Links {
+		link.Label = strings.TrimSpace(link.Label)
+		link.URL = strings.TrimSpace(link.URL)
+
+		if link.Label == "" {
+			return errors.New("every link needs a label")
+		}
+
+		if !isHTTPURL(link.URL) {
+			return fmt.Errorf("link %q must be an http or https URL", link.Label)
+		}
+
+		project.Links[i] = link
+	}
+
+	return nil
+}
+
+// GetGalleryItems handles a request to get all published gallery items from
+// the database.
 func (a API) GetGalleryItems(w http.ResponseWriter, r *http.Request) {
-	ret, err := a.db.GetGalleryItems()
+	a.writeGalleryItems(w, r, false)
+}
+
+// GetAllGalleryItems handles a request to get all gallery items from the
+// database, including drafts.
+//
+// Requires a valid auth token.
+func (a API) GetAllGalleryItems(w http.ResponseWriter, r *http.Request) {
+	a.writeGalleryItems(w, r, true)
+}
+
+// writeGalleryItems gets the gallery items from the database that match the
+// request's query parameters and sends them to the client.
+func (a API) writeGalleryItems(w http.ResponseWriter, r *http.Request, drafts bool) {
+	query := r.URL.Query()
+	filter := database.GalleryFilter{
+		Drafts:  drafts,
+		Client:  query.Get("client"),
+		Service: query.Get("service"),
+	}
+
+	if year := query.Get("year"); year != "" {
+		converted, err := strconv.Atoi(year)
+		if err != nil {
+			WriteError(w, "year must be a number", http.StatusBadRequest)
+			return
+		}
+
+		filter.Year = converted
+	}
+
+	ret, err := a.db.GetGalleryItems(filter)
 	if err != nil {
 		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting gallery items from database: %s\n", err.Error())
 		return
 	}
 
@@ -148,11 +345,146 @@ func (a API) GetGalleryItems(w http.ResponseWriter, r *http.Request) {
 	encoder.Encode(&ret)
 }
 
+// CloneProject handles requests to duplicate a project under a new name. The
+// copy gets the same fields, images, and credits as the original, and starts
+// out as an unpublished draft.
+//
+// If the request asks for the files to be copied, the thumbnail and every
+// image are copied to new files prefixed with the new project name.
+// Otherwise, the copy shares its image files with the original.
+//
+// Requires a valid auth token.
+func (a API) CloneProject(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	defer r.Body.Close()
+
+	decoder := json.NewDecoder(r.Body)
+	var req CloneProjectRequest
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in project clone request: %s\n", err.Error())
+		return
+	}
+
+	if !isSlug(req.Name) {
+		WriteError(w, "name must be lowercase letters and numbers separated by hyphens", http.StatusBadRequest)
+		return
+	}
+
+	project, err := a.db.GetProject(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "project not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting project from database: %s\n", err.Error())
+		return
+	}
+
+	clone := *project
+	clone.Name = req.Name
+	clone.Published = false
+	// Testimonials stay with the project they're about
+	clone.Testimonials = nil
+	// The original is still the canonical page for itself
+	clone.CanonicalURL = db.NullString{}
+	clone.Images = make([]string, len(project.Images))
+	copy(clone.Images, project.Images)
+
+	var copied []string
+	if req.CopyFiles {
+		clone.Thumbnail = req.Name + "-thumb" + filepath.Ext(project.Thumbnail)
+		for i, image := range project.Images {
+			clone.Images[i] = req.Name + "-" + image
+		}
+
+		sources := append([]string{project.Thumbnail}, project.Images...)
+		targets := append([]string{clone.Thumbnail}, clone.Images...)
+		for i := range sources {
+			if err := copyFile(filepath.Join(a.imageDir, sources[i]), filepath.Join(a.imageDir, targets[i])); err != nil {
+				a.removeFiles(copied)
+
+				// Another clone with the same name got there first
+				if os.IsExist(err) {
+					WriteError(w, "a project with that name already exists", http.StatusConflict)
+					return
+				}
+
+				WriteError(w, err.Error(), http.StatusInternalServerError)
+				a.log.Errorf("error copying project image: %s\n", err.Error())
+				return
+			}
+
+			copied = append(copied, targets[i])
+		}
+	}
+
+	if err := a.db.AddGalleryItem(clone); err != nil {
+		a.removeFiles(copied)
+
+		if err == database.ErrProjectExists {
+			WriteError(w, "a project with that name already exists", http.StatusConflict)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding cloned project to database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&clone)
+}
+
+// removeFiles deletes the given files from the images directory, logging any
+// that couldn't be removed. It's used to clean up after a failed request.
+func (a API) removeFiles(files []string) {
+	for _, file := range files {
+		if err := os.Remove(filepath.Join(a.imageDir, file)); err != nil {
+			a.log.Errorf("error removing image: %s\n", err.Error())
+		}
+	}
+}
+
+// copyFile copies the file at src to a new file at dst. It fails if dst
+// already exists.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+
+	return out.Close()
+}
+
 // RemoveGalleryItem handles a request to remove a gallery item.
 //
 // Requires a valid auth token.
 func (a API) RemoveGalleryItem(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	if !a.checkNotInMenu(w, entities.MenuLinkProject, id) {
+		return
+	}
+
 	if err := a.db.RemoveGalleryItem(id); err != nil {
 		WriteError(w, dbError, http.StatusInternalServerError)
 		return
@@ -161,7 +493,9 @@ func (a API) RemoveGalleryItem(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(200)
 }
 
-// AddImages handles requests to add images to a project.
+// AddImages handles requests to add images to a project. Images that look
+// like images already on the site are reported back, or rejected if the API
+// is set to reject duplicates.
 func (a API) AddImages(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -175,17 +509,27 @@ func (a API) AddImages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	duplicates, ok := a.checkDuplicates(w, files)
+	if !ok {
+		return
+	}
+
 	if err := a.db.AddProjectImages(id, files); err != nil {
 		WriteError(w, dbError, http.StatusInternalServerError)
 		a.log.Errorf("error adding project image to database: %s\n", err.Error())
 		return
 	}
 
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&AddImagesResponse{Duplicates: duplicates})
 }
 
-// RemoveProjectImages deletes images for a portfolio project and removes
-// them from the database.
+// RemoveProjectImages removes images from a portfolio project, and deletes
+// the files that no other project uses.
 func (a API) RemoveProjectImages(w http.ResponseWriter, r *http.Request) {
 	galleryID := chi.URLParam(r, "id")
 
@@ -205,7 +549,20 @@ func (a API) RemoveProjectImages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Cloned projects can share image files, so only delete the ones no
+	// project uses anymore
+	inUse, err := a.db.GetProjectFilesInUse(files)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting project images from database: %s\n", err.Error())
+		return
+	}
+
 	for _, file := range files {
+		if inUse[file] {
+			continue
+		}
+
 		path := filepath.Join(a.imageDir, file)
 		if err := os.Remove(path); err != nil {
 			WriteError(w, err.Error(), http.StatusInternalServerError)
@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+const (
+	// maxMenuDepth is how many levels of items a menu can have.
+	maxMenuDepth = 3
+
+	// maxMenuItems is the most items a menu can have, counting nested ones.
+	maxMenuItems = 100
+)
+
+// GetMenu handles requests to get the items in one of the site's menus.
+func (a API) GetMenu(w http.ResponseWriter, r *http.Request) {
+	menu := chi.URLParam(r, "menu")
+	if !isMenu(menu) {
+		WriteError(w, "menu not found", http.StatusNotFound)
+		return
+	}
+
+	ret, err := a.db.GetMenu(menu)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting menu from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// SetMenu handles requests to replace the items in one of the site's menus.
+// Links to projects, pages, and albums must point to ones that exist.
+//
+// Requires a valid auth token.
+func (a API) SetMenu(w http.ResponseWriter, r *http.Request) {
+	menu := chi.URLParam(r, "menu")
+	if !isMenu(menu) {
+		WriteError(w, "menu not found", http.StatusNotFound)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var items []*entities.MenuItem
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&items); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in set menu request: %s\n", err.Error())
+		return
+	}
+
+	count := 0
+	if status, err := a.validateMenuItems(items, 1, &count); err != nil {
+		WriteError(w, err.Error(), status)
+		return
+	}
+
+	if err := a.db.SetMenu(menu, items); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error setting menu in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// isMenu checks if a name is one of the site's menus.
+func isMenu(name string) bool {
+	return name == entities.MenuHeader || name == entities.MenuFooter
+}
+
+// validateMenuItems checks that every item in a menu has a label and a valid
+// link, and that the menu isn't nested too deeply or too big. It returns the
+// HTTP status code to respond with if the menu isn't valid.
+func (a API) validateMenuItems(items []*entities.MenuItem, depth int, count *int) (int, error) {
+	if len(items) > 0 && depth > maxMenuDepth {
+		return http.StatusBadRequest, fmt.Errorf("menus can't be nested more than %d levels deep", maxMenuDepth)
+	}
+
+	for _, item := range items {
+		if item == nil {
+			return http.StatusBadRequest, errors.New("menu items can't be null")
+		}
+
+		if *count++; *count > maxMenuItems {
+			return http.StatusBadRequest, fmt.Errorf("menus can't have more than %d items", maxMenuItems)
+		}
+
+		item.Label = strings.TrimSpace(item.Label)
+		item.Target = strings.TrimSpace(item.Target)
+
+		if item.Label == "" {
+			return http.StatusBadRequest, errors.New("every menu item needs a label")
+		}
+
+		switch item.LinkType {
+		case entities.MenuLinkURL:
+			if !isHTTPURL(item.Target) {
+				return http.StatusBadRequest, errors.New("menu links must be absolute http or https URLs: " + item.Target)
+			}
+		case entities.MenuLinkProject, entities.MenuLinkPage, entities.MenuLinkAlbum:
+			exists, err := a.db.MenuLinkExists(item.LinkType, item.Target)
+			if err != nil {
+				a.log.Errorf("error checking menu link in database: %s\n", err.Error())
+				return http.StatusInternalServerError, errors.New(dbError)
+			}
+
+			if !exists {
+				return http.StatusBadRequest, fmt.Errorf("menu item '%s' links to a %s that doesn't exist: %s", item.Label, item.LinkType, item.Target)
+			}
+		default:
+			return http.StatusBadRequest, errors.New("menu item type must be one of project, page, album, or url")
+		}
+
+		if status, err := a.validateMenuItems(item.Children, depth+1, count); err != nil {
+			return status, err
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// checkNotInMenu checks that no menus link to a project, page, or album that's
+// about to be removed, so that menus don't end up with broken links. If any
+// do, or the check fails, an error is sent and false is returned.
+func (a API) checkNotInMenu(w http.ResponseWriter, linkType string, id interface{}) bool {
+	menus, err := a.db.GetMenusLinkingTo(linkType, id)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting menu links from database: %s\n", err.Error())
+		return false
+	}
+
+	if len(menus) > 0 {
+		msg := fmt.Sprintf("this %s is linked from the %s menu; remove it from the menu first", linkType, strings.Join(menus, " and "))
+		WriteError(w, msg, http.StatusConflict)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,301 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/mailer"
+	"github.com/nicolekellydesign/webby-api/internal/signing"
+)
+
+const (
+	// newsletterKey is the query parameter in a newsletter token that holds
+	// what the token is for.
+	newsletterKey = "newsletter"
+
+	newsletterConfirm     = "confirm"
+	newsletterUnsubscribe = "unsubscribe"
+
+	// confirmExpiry is how long a subscriber has to confirm their email
+	// address.
+	confirmExpiry = 7 * 24 * time.Hour
+
+	// unsubscribeExpiry is how long unsubscribe links last. They have to keep
+	// working for as long as someone might open an old email.
+	unsubscribeExpiry = 10 * 365 * 24 * time.Hour
+
+	// subscribeRateLimit is how many sign ups can be sent from an IP address
+	// within subscribeRateWindow.
+	subscribeRateLimit  = 5
+	subscribeRateWindow = time.Hour
+)
+
+// Subscribe handles requests to sign up for the newsletter. The subscriber is
+// pending until they open the link in the confirmation email. The response is
+// the same whether or not the address was already subscribed, so it can't be
+// used to find out who is.
+func (a API) Subscribe(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req SubscribeRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if a.config.Mailer == nil || a.config.SiteURL == "" {
+		WriteError(w, "the newsletter isn't available right now", http.StatusServiceUnavailable)
+		return
+	}
+
+	if !a.subscribeLimiter.Allow(clientIP(r), time.Now()) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(subscribeRateWindow.Seconds())))
+		WriteError(w, "too many requests have been sent, please try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	if req.Website != "" {
+		w.WriteHeader(200)
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if !isEmail(email) {
+		WriteError(w, "please enter a valid email address", http.StatusBadRequest)
+		return
+	}
+
+	status, err := a.db.AddSubscriber(email)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding subscriber to database: %s\n", err.Error())
+		return
+	}
+
+	if status != entities.SubscriberConfirmed {
+		link := a.newsletterLink(newsletterConfirm, email, time.Now().Add(confirmExpiry))
+		go a.sendConfirmation(email, link)
+	}
+
+	w.WriteHeader(200)
+}
+
+// ConfirmSubscription handles requests to confirm a newsletter subscriber's
+// email address, using the token from the confirmation email.
+func (a API) ConfirmSubscription(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req NewsletterTokenRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	email, err := a.checkNewsletterToken(req.Token, newsletterConfirm)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.ConfirmSubscriber(email); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "subscription not found, please sign up again", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error confirming subscriber in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// Unsubscribe handles requests to unsubscribe from the newsletter. The token
+// can be in the query string, so that email clients can unsubscribe with a
+// single POST request, or in a JSON body.
+func (a API) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		var req NewsletterTokenRequest
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&req); err != nil {
+			WriteError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		token = req.Token
+	}
+
+	email, err := a.checkNewsletterToken(token, newsletterUnsubscribe)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.UnsubscribeSubscriber(email); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error unsubscribing subscriber in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// GetSubscribers handles requests to get newsletter subscribers, oldest
+// first. They can be narrowed down with the status query parameter.
+//
+// Requires a valid auth token.
+func (a API) GetSubscribers(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", entities.SubscriberPending, entities.SubscriberConfirmed, entities.SubscriberUnsubscribed:
+	default:
+		WriteError(w, "status must be one of pending, confirmed, or unsubscribed", http.StatusBadRequest)
+		return
+	}
+
+	ret, err := a.db.GetSubscribers(status)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting subscribers from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// ExportSubscribers handles requests to export the confirmed newsletter
+// subscribers as CSV, along with an unsubscribe link for each of them to put
+// in the newsletter.
+//
+// Requires a valid auth token.
+func (a API) ExportSubscribers(w http.ResponseWriter, r *http.Request) {
+	if a.config.SiteURL == "" {
+		WriteError(w, "the site URL has to be set to make unsubscribe links", http.StatusServiceUnavailable)
+		return
+	}
+
+	subscribers, err := a.db.GetSubscribers(entities.SubscriberConfirmed)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting subscribers from database: %s\n", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"subscribers.csv\"")
+	w.WriteHeader(200)
+
+	expires := time.Now().Add(unsubscribeExpiry)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"email", "confirmed_at", "unsubscribe_url"})
+	for _, subscriber := range subscribers {
+		writer.Write([]string{
+			csvCell(subscriber.Email),
+			subscriber.ConfirmedAt.Time.Format(time.RFC3339),
+			a.newsletterLink(newsletterUnsubscribe, subscriber.Email, expires),
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		a.log.Errorf("error writing subscribers: %s\n", err.Error())
+	}
+}
+
+// RemoveSubscriber handles requests to remove a newsletter subscriber
+// entirely, instead of only unsubscribing them.
+//
+// Requires a valid auth token.
+func (a API) RemoveSubscriber(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.RemoveSubscriber(uint(id)); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error removing subscriber from database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// newsletterLink creates a link to one of the site's newsletter pages, with a
+// signed token for the subscriber in it. The page sends the token back to the
+// API. Links always use the configured site URL, never the request's host,
+// since anyone can set that to a site of their own.
+func (a API) newsletterLink(action, email string, expires time.Time) string {
+	values := url.Values{newsletterKey: {action}, "email": {email}}
+	a.signer.Sign(values, expires)
+
+	return strings.TrimSuffix(a.config.SiteURL, "/") + "/newsletter/" + action + "?" + url.Values{"token": {values.Encode()}}.Encode()
+}
+
+// checkNewsletterToken checks that a newsletter token is valid and for the
+// given action, and gets the email address it's for.
+func (a API) checkNewsletterToken(token, action string) (string, error) {
+	values, err := url.ParseQuery(token)
+	if err != nil {
+		return "", errors.New("invalid token")
+	}
+
+	if err := a.signer.Verify(values, time.Now()); err != nil {
+		if err == signing.ErrExpired {
+			return "", errors.New("this link has expired, please sign up again")
+		}
+
+		return "", errors.New("invalid token")
+	}
+
+	if values.Get(newsletterKey) != action {
+		return "", errors.New("invalid token")
+	}
+
+	return values.Get("email"), nil
+}
+
+// sendConfirmation emails a new subscriber the link to confirm their email
+// address.
+func (a API) sendConfirmation(email, link string) {
+	name := "our newsletter"
+	if settings, err := a.db.GetSettings(); err == nil && settings.SiteTitle != "" {
+		name = "the " + settings.SiteTitle + " newsletter"
+	}
+
+	body := fmt.Sprintf("Thanks for signing up to %s! Please confirm your email address by opening this link:\n\n%s\n\n"+
+		"The link works for %d days. If you didn't sign up, you can ignore this email and you won't be subscribed.\n",
+		name, link, int(confirmExpiry.Hours()/24))
+
+	err := a.config.Mailer.Send(&mailer.Message{
+		To:      []string{email},
+		Subject: "Please confirm your subscription",
+		Body:    body,
+	})
+	if err != nil {
+		a.log.Errorf("error sending newsletter confirmation: %s\n", err.Error())
+	}
+}
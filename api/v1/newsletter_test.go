@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/internal/signing"
+)
+
+// newsletterAPI makes an API that can sign newsletter links for a site.
+func newsletterAPI(key string) API {
+	return API{
+		config: Config{SiteURL: "https://example.com/"},
+		signer: signing.New([]byte(key)),
+	}
+}
+
+// linkToken gets the token out of a newsletter link.
+func linkToken(t *testing.T, link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("unexpected error parsing link: %s\n", err)
+	}
+
+	return u.Query().Get("token")
+}
+
+// TestNewsletterLink ensures that newsletter links point to the configured
+// site, whatever host the request was sent to.
+func TestNewsletterLink(t *testing.T) {
+	// Given
+	a := newsletterAPI("key")
+
+	// When
+	link := a.newsletterLink(newsletterConfirm, "ada@example.com", time.Now().Add(time.Hour))
+
+	// Then
+	if !strings.HasPrefix(link, "https://example.com/newsletter/confirm?token=") {
+		t.Fatalf("result does not match expected: got %s\n", link)
+	}
+}
+
+// TestCheckNewsletterToken ensures that tokens from newsletter links can only
+// be used for what they were made for, by the API that signed them, before
+// they expire.
+func TestCheckNewsletterToken(t *testing.T) {
+	a := newsletterAPI("key")
+	now := time.Now()
+
+	confirm := linkToken(t, a.newsletterLink(newsletterConfirm, "ada@example.com", now.Add(time.Hour)))
+	unsubscribe := linkToken(t, a.newsletterLink(newsletterUnsubscribe, "ada@example.com", now.Add(time.Hour)))
+	expired := linkToken(t, a.newsletterLink(newsletterConfirm, "ada@example.com", now.Add(-time.Hour)))
+	other := linkToken(t, newsletterAPI("other key").newsletterLink(newsletterConfirm, "ada@example.com", now.Add(time.Hour)))
+	tampered := strings.Replace(confirm, "ada%40example.com", "eve%40example.com", 1)
+
+	tests := []struct {
+		name   string
+		token  string
+		action string
+		valid  bool
+	}{
+		{"confirm", confirm, newsletterConfirm, true},
+		{"unsubscribe", unsubscribe, newsletterUnsubscribe, true},
+		{"confirm token used to unsubscribe", confirm, newsletterUnsubscribe, false},
+		{"unsubscribe token used to confirm", unsubscribe, newsletterConfirm, false},
+		{"expired", expired, newsletterConfirm, false},
+		{"signed with another key", other, newsletterConfirm, false},
+		{"tampered email", tampered, newsletterConfirm, false},
+		{"empty", "", newsletterConfirm, false},
+		{"garbage", "%zz", newsletterConfirm, false},
+	}
+
+	for _, test := range tests {
+		// When
+		email, err := a.checkNewsletterToken(test.token, test.action)
+
+		// Then
+		if test.valid {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %s\n", test.name, err)
+			}
+
+			if email != "ada@example.com" {
+				t.Fatalf("%s: result does not match expected: got %s, expected: %s\n", test.name, email, "ada@example.com")
+			}
+		} else if err == nil {
+			t.Fatalf("%s: expected an error, got %s\n", test.name, email)
+		}
+	}
+}
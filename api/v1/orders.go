@@ -0,0 +1,482 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid"
+	"github.com/nicolekellydesign/webby-api/database"
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/payment"
+)
+
+const (
+	// defaultCurrency is the currency prices are in if none is set.
+	defaultCurrency = "USD"
+
+	maxOrderItems         = 20
+	maxOrderQuantity      = 10
+	maxAddressFieldLength = 200
+
+	// orderRateLimit is how many orders can be made from an IP address
+	// within orderRateWindow.
+	orderRateLimit  = 10
+	orderRateWindow = time.Hour
+
+	// orderExpiry is how long a customer has to pay for an order before the
+	// prints held for it are let go.
+	orderExpiry = 30 * time.Minute
+)
+
+// countryPattern matches ISO 3166-1 alpha-2 country codes.
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// orderTransitions are the states that an order can be moved to by hand, and
+// the states it can be moved from.
+var orderTransitions = map[string][]string{
+	entities.OrderPaid:      {entities.OrderPending},
+	entities.OrderFulfilled: {entities.OrderPaid},
+	entities.OrderCancelled: {entities.OrderPending, entities.OrderPaid},
+}
+
+// AddOrder handles requests to order prints from the shop. The prints are held
+// for the order, and the order is handed to the payment provider. The
+// response has the URL where the customer pays.
+func (a API) AddOrder(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req OrderRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if a.config.Payments == nil {
+		WriteError(w, "the shop isn't taking orders right now", http.StatusServiceUnavailable)
+		return
+	}
+
+	if !a.orderLimiter.Allow(clientIP(r), time.Now()) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(orderRateWindow.Seconds())))
+		WriteError(w, "too many orders have been made, please try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	order, err := a.newOrder(&req)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := a.db.AddOrder(order)
+	if err != nil {
+		switch err {
+		case database.ErrNotForSale:
+			WriteError(w, "one of the prints in your order is no longer for sale", http.StatusBadRequest)
+		case database.ErrOutOfStock:
+			WriteError(w, "there aren't enough of one of the prints in your order left", http.StatusConflict)
+		default:
+			WriteError(w, dbError, http.StatusInternalServerError)
+			a.log.Errorf("error adding order to database: %s\n", err.Error())
+		}
+		return
+	}
+
+	session, err := a.config.Payments.CreatePayment(&payment.Request{
+		Reference:   order.Reference,
+		Amount:      int64(order.Total),
+		Currency:    order.Currency,
+		Email:       order.Email,
+		Description: "Print order " + order.Reference,
+		ReturnURL:   a.siteURL(r) + "/shop/orders/" + order.Reference,
+		ExpiresAt:   time.Now().Add(orderExpiry),
+	})
+	if err != nil {
+		a.cancelOrder(id)
+		WriteError(w, "unable to start the payment, please try again later", http.StatusBadGateway)
+		a.log.Errorf("error creating payment: %s\n", err.Error())
+		return
+	}
+
+	if err := a.db.SetOrderPayment(id, session.ID); err != nil {
+		// The customer never gets the checkout URL, so the order can't be
+		// paid for
+		a.cancelOrder(id)
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error setting order payment in database: %s\n", err.Error())
+		return
+	}
+
+	ret := OrderResponse{
+		Reference:   order.Reference,
+		Status:      entities.OrderPending,
+		Total:       order.Total,
+		Currency:    order.Currency,
+		CheckoutURL: session.CheckoutURL,
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// GetOrderByReference handles requests from a customer to check on their
+// order. If the order hasn't been paid for yet, the payment provider is asked
+// whether it has been first.
+func (a API) GetOrderByReference(w http.ResponseWriter, r *http.Request) {
+	reference := chi.URLParam(r, "reference")
+
+	ret, err := a.db.GetOrderByReference(reference)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "order not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting order from database: %s\n", err.Error())
+		return
+	}
+
+	if ret.Status == entities.OrderPending && ret.PaymentID.Valid {
+		changed, err := a.syncPayment(ret)
+		if err != nil {
+			a.log.Errorf("error checking order payment: %s\n", err.Error())
+		}
+
+		if changed {
+			ret, err = a.db.GetOrder(ret.ID)
+			if err != nil {
+				WriteError(w, dbError, http.StatusInternalServerError)
+				a.log.Errorf("error getting order from database: %s\n", err.Error())
+				return
+			}
+		}
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// GetOrders handles requests to get all orders, newest first. They can be
+// narrowed down to a state with the status query parameter.
+//
+// Requires a valid auth token.
+func (a API) GetOrders(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", entities.OrderPending, entities.OrderPaid, entities.OrderFulfilled, entities.OrderCancelled:
+	default:
+		WriteError(w, "status must be pending, paid, fulfilled, or cancelled", http.StatusBadRequest)
+		return
+	}
+
+	ret, err := a.db.GetOrders(status)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting orders from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// GetOrder handles requests to get an order by its ID, along with its items.
+//
+// Requires a valid auth token.
+func (a API) GetOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := a.db.GetOrder(uint(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "order not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting order from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// SetOrderStatus handles requests to move an order to a new state by hand,
+// like marking it fulfilled once it's been shipped. Cancelling an order puts
+// its prints back in stock; any refund has to be made with the payment
+// provider.
+//
+// Requires a valid auth token.
+func (a API) SetOrderStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var req OrderStatusRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in order status request: %s\n", err.Error())
+		return
+	}
+
+	from, ok := orderTransitions[req.Status]
+	if !ok {
+		WriteError(w, "status must be paid, fulfilled, or cancelled", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.SetOrderStatus(uint(id), req.Status, from...); err != nil {
+		if err != sql.ErrNoRows {
+			WriteError(w, dbError, http.StatusInternalServerError)
+			a.log.Errorf("error setting order status in database: %s\n", err.Error())
+			return
+		}
+
+		// Work out whether the order is missing or in the wrong state
+		order, err := a.db.GetOrder(uint(id))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				WriteError(w, "order not found", http.StatusNotFound)
+				return
+			}
+
+			WriteError(w, dbError, http.StatusInternalServerError)
+			a.log.Errorf("error getting order from database: %s\n", err.Error())
+			return
+		}
+
+		WriteError(w, fmt.Sprintf("a %s order can't be marked %s", order.Status, req.Status), http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// syncPayment asks the payment provider whether a pending order has been paid
+// for, and updates the order to match. Orders whose payment failed are
+// cancelled. It returns whether the order changed. If the provider can't be
+// asked, an error is returned and the order is left alone.
+func (a API) syncPayment(order *entities.Order) (bool, error) {
+	if a.config.Payments == nil || a.config.Payments.Name() != order.PaymentProvider {
+		return false, nil
+	}
+
+	status, err := a.config.Payments.GetStatus(order.PaymentID.String)
+	if err != nil {
+		return false, err
+	}
+
+	switch status {
+	case payment.StatusPaid:
+		status = entities.OrderPaid
+	case payment.StatusFailed:
+		status = entities.OrderCancelled
+	default:
+		return false, nil
+	}
+
+	if err := a.db.SetOrderStatus(order.ID, status, entities.OrderPending); err != nil {
+		// Another request got there first
+		if err == sql.ErrNoRows {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	if status == entities.OrderPaid {
+		body := fmt.Sprintf("Order %s from %s has been paid for.\n\n", order.Reference, order.Name)
+		for _, item := range order.Items {
+			body += fmt.Sprintf("%d x %s (%s, %s)\n", item.Quantity, item.Title, item.Size, item.Paper)
+		}
+
+		go a.notify("New print order from "+order.Name, &mail.Address{Name: order.Name, Address: order.Email}, body)
+	}
+
+	return true, nil
+}
+
+// cancelOrder cancels a pending order that can't be paid for, so the prints
+// held for it are let go.
+func (a API) cancelOrder(id uint) {
+	if err := a.db.SetOrderStatus(id, entities.OrderCancelled, entities.OrderPending); err != nil && err != sql.ErrNoRows {
+		a.log.Errorf("error cancelling order in database: %s\n", err.Error())
+	}
+}
+
+// ExpireOrders cancels pending orders that haven't been paid for within
+// orderExpiry, so the prints held for them are let go. The payment provider
+// is asked about each order first, in case it was paid for at the last
+// minute; if the provider can't be reached, the order is left for next time.
+// Orders the provider has no payment for can't be paid, so they're cancelled.
+func (a API) ExpireOrders() {
+	ids, err := a.db.GetStaleOrders(time.Now().Add(-orderExpiry))
+	if err != nil {
+		a.log.Errorf("error getting stale orders from database: %s\n", err.Error())
+		return
+	}
+
+	for _, id := range ids {
+		order, err := a.db.GetOrder(id)
+		if err != nil {
+			a.log.Errorf("error getting order from database: %s\n", err.Error())
+			continue
+		}
+
+		if order.PaymentID.Valid {
+			changed, err := a.syncPayment(order)
+			if err != nil && err != payment.ErrNotFound {
+				a.log.Errorf("error checking payment for stale order %s: %s\n", order.Reference, err.Error())
+				continue
+			}
+
+			if changed {
+				continue
+			}
+		}
+
+		a.cancelOrder(order.ID)
+	}
+}
+
+// newOrder creates an order from an order request, checking that the
+// customer's details are valid and that there's something to order. Lines
+// for the same variant are merged.
+func (a API) newOrder(req *OrderRequest) (*entities.Order, error) {
+	order := &entities.Order{
+		Name:            strings.TrimSpace(req.Name),
+		Email:           strings.TrimSpace(req.Email),
+		Address:         req.Address,
+		Currency:        a.currency(),
+		PaymentProvider: a.config.Payments.Name(),
+	}
+
+	if order.Name == "" {
+		return nil, errors.New("please enter your name")
+	}
+
+	if len([]rune(order.Name)) > maxContactNameLength {
+		return nil, fmt.Errorf("name can't be longer than %d characters", maxContactNameLength)
+	}
+
+	if !isEmail(order.Email) {
+		return nil, errors.New("please enter a valid email address")
+	}
+
+	if err := validateAddress(&order.Address); err != nil {
+		return nil, err
+	}
+
+	if len(req.Items) == 0 {
+		return nil, errors.New("an order needs at least one print")
+	}
+
+	items := make(map[uint]*entities.OrderItem, len(req.Items))
+	for _, line := range req.Items {
+		if line.Quantity < 1 {
+			return nil, errors.New("quantities must be at least 1")
+		}
+
+		item, ok := items[line.VariantID]
+		if !ok {
+			item = &entities.OrderItem{}
+			item.VariantID.Int32 = int32(line.VariantID)
+			item.VariantID.Valid = true
+
+			items[line.VariantID] = item
+			order.Items = append(order.Items, item)
+		}
+
+		item.Quantity += line.Quantity
+		if item.Quantity > maxOrderQuantity {
+			return nil, fmt.Errorf("you can't order more than %d of the same print", maxOrderQuantity)
+		}
+	}
+
+	if len(order.Items) > maxOrderItems {
+		return nil, fmt.Errorf("an order can't have more than %d different prints", maxOrderItems)
+	}
+
+	reference, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	order.Reference = reference.String()
+
+	return order, nil
+}
+
+// validateAddress checks that a shipping address has a first line, a city, a
+// postal code, and a valid country code, and that nothing is too long.
+// Whitespace is trimmed, and the country code is made uppercase.
+func validateAddress(address *entities.Address) error {
+	address.Line1 = strings.TrimSpace(address.Line1)
+	address.Line2 = strings.TrimSpace(address.Line2)
+	address.City = strings.TrimSpace(address.City)
+	address.Region = strings.TrimSpace(address.Region)
+	address.PostalCode = strings.TrimSpace(address.PostalCode)
+	address.Country = strings.ToUpper(strings.TrimSpace(address.Country))
+
+	if address.Line1 == "" || address.City == "" || address.PostalCode == "" {
+		return errors.New("please enter a full shipping address")
+	}
+
+	for _, field := range []string{address.Line1, address.Line2, address.City, address.Region, address.PostalCode} {
+		if len([]rune(field)) > maxAddressFieldLength {
+			return fmt.Errorf("address lines can't be longer than %d characters", maxAddressFieldLength)
+		}
+	}
+
+	if !countryPattern.MatchString(address.Country) {
+		return errors.New("country must be a two letter country code")
+	}
+
+	return nil
+}
+
+// currency is the currency that prices in the shop are in.
+func (a API) currency() string {
+	if a.config.Currency == "" {
+		return defaultCurrency
+	}
+
+	return a.config.Currency
+}
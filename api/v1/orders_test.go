@@ -0,0 +1,230 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/payment"
+)
+
+// shopAPI makes an API with a fake payment provider.
+func shopAPI() API {
+	return API{config: Config{Payments: payment.NewFake(), Currency: "EUR"}}
+}
+
+// validAddress returns a valid shipping address.
+func validAddress() entities.Address {
+	return entities.Address{
+		Line1:      "1 Main Street",
+		City:       "Dublin",
+		PostalCode: "D01 F5P2",
+		Country:    "ie",
+	}
+}
+
+// orderRequest makes an order request for the given items.
+func orderRequest(items ...OrderItemRequest) *OrderRequest {
+	return &OrderRequest{
+		Name:    " Ada ",
+		Email:   "ada@example.com",
+		Address: validAddress(),
+		Items:   items,
+	}
+}
+
+// TestNewOrder ensures that an order is made from a valid request, with the
+// shop's currency and payment provider.
+func TestNewOrder(t *testing.T) {
+	// Given
+	a := shopAPI()
+	req := orderRequest(OrderItemRequest{VariantID: 1, Quantity: 2})
+
+	// When
+	order, err := a.newOrder(req)
+
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err)
+	}
+
+	if order.Name != "Ada" || order.Currency != "EUR" || order.PaymentProvider != a.config.Payments.Name() {
+		t.Fatalf("result does not match expected: got %+v\n", order)
+	}
+
+	if order.Address.Country != "IE" {
+		t.Fatalf("result does not match expected: got %s, expected: %s\n", order.Address.Country, "IE")
+	}
+
+	if order.Reference == "" {
+		t.Fatalf("expected the order to have a reference\n")
+	}
+}
+
+// TestNewOrder_Merged ensures that lines for the same variant are merged,
+// keeping the order they were first given in.
+func TestNewOrder_Merged(t *testing.T) {
+	// Given
+	a := shopAPI()
+	req := orderRequest(
+		OrderItemRequest{VariantID: 2, Quantity: 1},
+		OrderItemRequest{VariantID: 1, Quantity: 3},
+		OrderItemRequest{VariantID: 2, Quantity: 4},
+	)
+
+	// When
+	order, err := a.newOrder(req)
+
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err)
+	}
+
+	if len(order.Items) != 2 {
+		t.Fatalf("result does not match expected: got %d items, expected: %d\n", len(order.Items), 2)
+	}
+
+	expected := []struct {
+		variant  int32
+		quantity int
+	}{{2, 5}, {1, 3}}
+
+	for i, item := range order.Items {
+		if !item.VariantID.Valid || item.VariantID.Int32 != expected[i].variant || item.Quantity != expected[i].quantity {
+			t.Fatalf("item %d does not match expected: got %+v, expected: %+v\n", i, item, expected[i])
+		}
+	}
+}
+
+// TestNewOrder_Limits ensures that orders that are empty, or have too many
+// prints, are rejected.
+func TestNewOrder_Limits(t *testing.T) {
+	tooMany := make([]OrderItemRequest, 0, maxOrderItems+1)
+	for i := 0; i <= maxOrderItems; i++ {
+		tooMany = append(tooMany, OrderItemRequest{VariantID: uint(i + 1), Quantity: 1})
+	}
+
+	enough := tooMany[:maxOrderItems]
+
+	tests := []struct {
+		name  string
+		items []OrderItemRequest
+		valid bool
+	}{
+		{"no items", nil, false},
+		{"zero quantity", []OrderItemRequest{{VariantID: 1, Quantity: 0}}, false},
+		{"negative quantity", []OrderItemRequest{{VariantID: 1, Quantity: -1}}, false},
+		{"most of one print", []OrderItemRequest{{VariantID: 1, Quantity: maxOrderQuantity}}, true},
+		{"too many of one print", []OrderItemRequest{{VariantID: 1, Quantity: maxOrderQuantity + 1}}, false},
+		{"too many once merged", []OrderItemRequest{{VariantID: 1, Quantity: maxOrderQuantity}, {VariantID: 1, Quantity: 1}}, false},
+		{"most different prints", enough, true},
+		{"too many different prints", tooMany, false},
+	}
+
+	for _, test := range tests {
+		// Given
+		a := shopAPI()
+		req := orderRequest(test.items...)
+
+		// When
+		_, err := a.newOrder(req)
+
+		// Then
+		if test.valid && err != nil {
+			t.Fatalf("%s: unexpected error: %s\n", test.name, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Fatalf("%s: expected an error\n", test.name)
+		}
+	}
+}
+
+// TestNewOrder_Customer ensures that orders need the customer's name and a
+// valid email address.
+func TestNewOrder_Customer(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(req *OrderRequest)
+		valid bool
+	}{
+		{"no name", func(req *OrderRequest) { req.Name = " " }, false},
+		{"long name", func(req *OrderRequest) { req.Name = strings.Repeat("a", maxContactNameLength+1) }, false},
+		{"bad email", func(req *OrderRequest) { req.Email = "ada" }, false},
+		{"bad address", func(req *OrderRequest) { req.Address.City = "" }, false},
+	}
+
+	for _, test := range tests {
+		// Given
+		a := shopAPI()
+		req := orderRequest(OrderItemRequest{VariantID: 1, Quantity: 1})
+		test.edit(req)
+
+		// When
+		_, err := a.newOrder(req)
+
+		// Then
+		if test.valid != (err == nil) {
+			t.Fatalf("%s: error does not match expected: got %v, expected valid: %v\n", test.name, err, test.valid)
+		}
+	}
+}
+
+// TestValidateAddress ensures that shipping addresses are complete, not too
+// long, and have a two letter country code.
+func TestValidateAddress(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(address *entities.Address)
+		valid bool
+	}{
+		{"valid", func(address *entities.Address) {}, true},
+		{"with optional lines", func(address *entities.Address) { address.Line2 = "Flat 2"; address.Region = "Leinster" }, true},
+		{"no first line", func(address *entities.Address) { address.Line1 = "  " }, false},
+		{"no city", func(address *entities.Address) { address.City = "" }, false},
+		{"no postal code", func(address *entities.Address) { address.PostalCode = "" }, false},
+		{"long line", func(address *entities.Address) { address.Line2 = strings.Repeat("a", maxAddressFieldLength+1) }, false},
+		{"longest line", func(address *entities.Address) { address.Line1 = strings.Repeat("é", maxAddressFieldLength) }, true},
+		{"country name", func(address *entities.Address) { address.Country = "Ireland" }, false},
+		{"three letter country", func(address *entities.Address) { address.Country = "IRL" }, false},
+		{"no country", func(address *entities.Address) { address.Country = "" }, false},
+	}
+
+	for _, test := range tests {
+		// Given
+		address := validAddress()
+		test.edit(&address)
+
+		// When
+		err := validateAddress(&address)
+
+		// Then
+		if test.valid != (err == nil) {
+			t.Fatalf("%s: error does not match expected: got %v, expected valid: %v\n", test.name, err, test.valid)
+		}
+	}
+}
+
+// TestValidateAddress_Trimmed ensures that whitespace is trimmed from an
+// address, and the country code is made uppercase.
+func TestValidateAddress_Trimmed(t *testing.T) {
+	// Given
+	address := entities.Address{
+		Line1:      " 1 Main Street ",
+		City:       " Dublin",
+		PostalCode: "D01 F5P2 ",
+		Country:    " ie ",
+	}
+
+	// When
+	err := validateAddress(&address)
+
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err)
+	}
+
+	if address.Line1 != "1 Main Street" || address.City != "Dublin" || address.PostalCode != "D01 F5P2" || address.Country != "IE" {
+		t.Fatalf("result does not match expected: got %+v\n", address)
+	}
+}
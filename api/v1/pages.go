@@ -0,0 +1,289 @@
+package v1
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// maxMetaDescriptionLength is the longest meta description a page can have.
+// Search engines cut descriptions off well before this.
+const maxMetaDescriptionLength = 320
+
+// GetPages handles requests to get all published pages.
+func (a API) GetPages(w http.ResponseWriter, r *http.Request) {
+	a.writePages(w, false)
+}
+
+// GetPage handles requests to get a published page by its slug.
+func (a API) GetPage(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+
+	ret, err := a.db.GetPageBySlug(slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "page not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting page from database: %s\n", err.Error())
+		return
+	}
+
+	// Drafts are hidden from the public
+	if !ret.Published {
+		WriteError(w, "page not found", http.StatusNotFound)
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// GetAllPages handles requests to get all pages, including drafts.
+//
+// Requires a valid auth token.
+func (a API) GetAllPages(w http.ResponseWriter, r *http.Request) {
+	a.writePages(w, true)
+}
+
+// writePages sends the list of pages, including drafts if asked for.
+func (a API) writePages(w http.ResponseWriter, drafts bool) {
+	ret, err := a.db.GetPages(drafts)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting pages from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// GetPageByID handles requests to get a page by its ID, whether or not it's
+// published.
+//
+// Requires a valid auth token.
+func (a API) GetPageByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := a.db.GetPage(uint(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "page not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting page from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// AddPage handles requests to create a new page.
+//
+// Requires a valid auth token.
+func (a API) AddPage(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var page entities.Page
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&page); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in add page request: %s\n", err.Error())
+		return
+	}
+
+	page.ID = 0
+	if status, err := a.validatePage(&page); err != nil {
+		WriteError(w, err.Error(), status)
+		return
+	}
+
+	id, err := a.db.AddPage(&page)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding page to database: %s\n", err.Error())
+		return
+	}
+
+	ret, err := a.db.GetPage(id)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting page from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// UpdatePage handles requests to change a page.
+//
+// Requires a valid auth token.
+func (a API) UpdatePage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var page entities.Page
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&page); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in page update request: %s\n", err.Error())
+		return
+	}
+
+	page.ID = uint(id)
+
+	if status, err := a.validatePage(&page); err != nil {
+		WriteError(w, err.Error(), status)
+		return
+	}
+
+	if err := a.db.UpdatePage(&page); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "page not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating page in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// RemovePage handles requests to remove a page.
+//
+// Requires a valid auth token.
+func (a API) RemovePage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !a.checkNotInMenu(w, entities.MenuLinkPage, uint(id)) {
+		return
+	}
+
+	if err := a.db.RemovePage(uint(id)); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error removing page from database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// validatePage checks that a page has a valid, unused slug, a title, and a
+// body in a known format, and that its share image exists. It returns the
+// HTTP status code to respond with if the page isn't valid.
+func (a API) validatePage(page *entities.Page) (int, error) {
+	page.Slug = strings.TrimSpace(page.Slug)
+	if !isSlug(page.Slug) {
+		return http.StatusBadRequest, errors.New("slug must be lowercase letters and numbers separated by hyphens")
+	}
+
+	page.Title = strings.TrimSpace(page.Title)
+	if page.Title == "" {
+		return http.StatusBadRequest, errors.New("a page needs a title")
+	}
+
+	// Only the body for the page's format is kept
+	switch page.Format {
+	case "", entities.PageMarkdown:
+		page.Format = entities.PageMarkdown
+		page.Blocks = nil
+	case entities.PageBlocks:
+		page.Body = ""
+		if err := validateBlocks(page.Blocks); err != nil {
+			return http.StatusBadRequest, err
+		}
+	default:
+		return http.StatusBadRequest, errors.New("page format must be 'markdown' or 'blocks'")
+	}
+
+	page.MetaTitle = strings.TrimSpace(page.MetaTitle)
+	page.MetaDescription = strings.TrimSpace(page.MetaDescription)
+	if len([]rune(page.MetaDescription)) > maxMetaDescriptionLength {
+		return http.StatusBadRequest, fmt.Errorf("meta description can't be longer than %d characters", maxMetaDescriptionLength)
+	}
+
+	page.ShareImage.Valid = page.ShareImage.Valid && page.ShareImage.String != ""
+	if page.ShareImage.Valid {
+		if err := a.checkImageFile(page.ShareImage.String); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("share image: %s", err.Error())
+		}
+	}
+
+	existing, err := a.db.GetPageBySlug(page.Slug)
+	if err != nil && err != sql.ErrNoRows {
+		a.log.Errorf("error getting page from database: %s\n", err.Error())
+		return http.StatusInternalServerError, errors.New(dbError)
+	}
+
+	if err == nil && existing.ID != page.ID {
+		return http.StatusConflict, errors.New("a page with that slug already exists")
+	}
+
+	return http.StatusOK, nil
+}
+
+// validateBlocks checks that every content block has a known type, and that
+// any data it has is a JSON object.
+func validateBlocks(blocks entities.Blocks) error {
+	for i, block := range blocks {
+		switch block.Type {
+		case entities.BlockHeading, entities.BlockParagraph, entities.BlockImage, entities.BlockGallery,
+			entities.BlockQuote, entities.BlockList, entities.BlockEmbed, entities.BlockDivider:
+		default:
+			return fmt.Errorf("block %d has an unknown type: %s", i, block.Type)
+		}
+
+		data := bytes.TrimSpace(block.Data)
+		if len(data) > 0 && !bytes.Equal(data, []byte("null")) && data[0] != '{' {
+			return fmt.Errorf("block %d data must be a JSON object", i)
+		}
+	}
+
+	return nil
+}
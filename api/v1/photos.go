@@ -2,12 +2,21 @@ package v1
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/patch"
 )
 
 // AddPhotos handles a request to add images to the photography database.
+// Images that look like images already on the site are reported back, or
+// rejected if the API is set to reject duplicates.
 func (a API) AddPhotos(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
@@ -19,15 +28,27 @@ func (a API) AddPhotos(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	duplicates, ok := a.checkDuplicates(w, files)
+	if !ok {
+		return
+	}
+
 	if err := a.db.AddPhotos(files); err != nil {
 		WriteError(w, dbError, http.StatusInternalServerError)
 		return
 	}
 
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&AddImagesResponse{Duplicates: duplicates})
 }
 
-// GetPhotos handles requests to get all photos from the database.
+// GetPhotos handles requests to get all photos from the database. While
+// watermarking is on, watermarked photos are sent without their file names
+// unless the request comes from a signed-in user.
 func (a API) GetPhotos(w http.ResponseWriter, r *http.Request) {
 	ret, err := a.db.GetPhotos()
 	if err != nil {
@@ -35,6 +56,17 @@ func (a API) GetPhotos(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	hide, err := a.hidesOriginals(r)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting watermark settings from database: %s\n", err.Error())
+		return
+	}
+
+	if hide {
+		hideOriginals(ret)
+	}
+
 	// Send back the response
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(200)
@@ -74,3 +106,152 @@ func (a API) RemovePhotos(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(200)
 }
+
+// PatchPhoto handles requests to edit the details of a single photo. The body
+// is a JSON Merge Patch (RFC 7386): members that are absent are left
+// unchanged, and members set to null are cleared.
+//
+// Requires a valid auth token.
+func (a API) PatchPhoto(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error reading photo patch body: %s\n", err.Error())
+		return
+	}
+
+	photos, err := a.db.GetPhotosByID([]uint{uint(id)})
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting photo from database: %s\n", err.Error())
+		return
+	}
+
+	if len(photos) == 0 {
+		WriteError(w, "photo not found", http.StatusNotFound)
+		return
+	}
+
+	updated, err := applyPhotoPatch(photos[0], body)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error applying photo patch: %s\n", err.Error())
+		return
+	}
+
+	if err := a.db.UpdatePhotos([]*entities.Photo{updated}); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating photo in database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(updated)
+}
+
+// PatchPhotos handles requests to apply the same changes to a selection of
+// photos. The changes are a JSON Merge Patch, applied to each photo in turn.
+// Either every photo is updated, or none are.
+//
+// Requires a valid auth token.
+func (a API) PatchPhotos(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req BulkPhotoPatchRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in bulk photo patch request: %s\n", err.Error())
+		return
+	}
+
+	if len(req.Changes) == 0 {
+		WriteError(w, "no changes given", http.StatusBadRequest)
+		return
+	}
+
+	photos, err := a.db.GetPhotosByID(req.IDs)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting photos from database: %s\n", err.Error())
+		return
+	}
+
+	// Make sure every photo in the selection exists
+	found := make(map[uint]bool, len(photos))
+	for _, photo := range photos {
+		found[photo.ID] = true
+	}
+
+	for _, id := range req.IDs {
+		if !found[id] {
+			WriteError(w, "no photo with ID "+strconv.FormatUint(uint64(id), 10), http.StatusBadRequest)
+			return
+		}
+	}
+
+	updated := make([]*entities.Photo, 0, len(photos))
+	for _, photo := range photos {
+		p, err := applyPhotoPatch(photo, req.Changes)
+		if err != nil {
+			WriteError(w, err.Error(), http.StatusBadRequest)
+			a.log.Errorf("error applying photo patch: %s\n", err.Error())
+			return
+		}
+
+		updated = append(updated, p)
+	}
+
+	if err := a.db.UpdatePhotos(updated); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating photos in database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&updated)
+}
+
+// applyPhotoPatch applies a merge patch to a photo, returning the updated
+// photo. The ID and file name can't be changed by a patch.
+func applyPhotoPatch(photo *entities.Photo, body []byte) (*entities.Photo, error) {
+	current, err := json.Marshal(photo)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := patch.Merge(current, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated entities.Photo
+	if err := json.Unmarshal(merged, &updated); err != nil {
+		return nil, err
+	}
+
+	updated.ID = photo.ID
+	updated.Filename = photo.Filename
+	updated.Title = strings.TrimSpace(updated.Title)
+	updated.Caption = strings.TrimSpace(updated.Caption)
+	updated.AltText = strings.TrimSpace(updated.AltText)
+	updated.Location = strings.TrimSpace(updated.Location)
+
+	return &updated, nil
+}
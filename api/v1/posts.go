@@ -0,0 +1,383 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/database"
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/feed"
+)
+
+const (
+	// maxExcerptLength is the longest excerpt a post can have.
+	maxExcerptLength = 500
+
+	// feedSize is how many of the newest posts are in the feeds.
+	feedSize = 20
+)
+
+// GetPosts handles requests to get all published posts, newest first. The
+// posts can be narrowed down to a tag with the tag query parameter.
+func (a API) GetPosts(w http.ResponseWriter, r *http.Request) {
+	a.writePosts(w, r, false)
+}
+
+// GetPost handles requests to get a published post by its slug.
+func (a API) GetPost(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+
+	ret, err := a.db.GetPostBySlug(slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "post not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting post from database: %s\n", err.Error())
+		return
+	}
+
+	// Drafts and scheduled posts are hidden from the public
+	if !ret.Published || ret.PublishedAt.Time.After(time.Now()) {
+		WriteError(w, "post not found", http.StatusNotFound)
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// GetAllPosts handles requests to get all posts, including drafts and
+// scheduled posts.
+//
+// Requires a valid auth token.
+func (a API) GetAllPosts(w http.ResponseWriter, r *http.Request) {
+	a.writePosts(w, r, true)
+}
+
+// writePosts sends the list of posts, including drafts if asked for.
+func (a API) writePosts(w http.ResponseWriter, r *http.Request, drafts bool) {
+	filter := database.PostFilter{
+		Drafts: drafts,
+		Tag:    strings.TrimSpace(r.URL.Query().Get("tag")),
+	}
+
+	ret, err := a.db.GetPosts(filter)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting posts from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// GetPostByID handles requests to get a post by its ID, whether or not it's
+// published.
+//
+// Requires a valid auth token.
+func (a API) GetPostByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := a.db.GetPost(uint(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "post not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting post from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// AddPost handles requests to create a new post.
+//
+// Requires a valid auth token.
+func (a API) AddPost(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var post entities.Post
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&post); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in add post request: %s\n", err.Error())
+		return
+	}
+
+	post.ID = 0
+	if status, err := a.validatePost(&post); err != nil {
+		WriteError(w, err.Error(), status)
+		return
+	}
+
+	id, err := a.db.AddPost(&post)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding post to database: %s\n", err.Error())
+		return
+	}
+
+	ret, err := a.db.GetPost(id)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting post from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// UpdatePost handles requests to change a post.
+//
+// Requires a valid auth token.
+func (a API) UpdatePost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var post entities.Post
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&post); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in post update request: %s\n", err.Error())
+		return
+	}
+
+	post.ID = uint(id)
+
+	if status, err := a.validatePost(&post); err != nil {
+		WriteError(w, err.Error(), status)
+		return
+	}
+
+	if err := a.db.UpdatePost(&post); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "post not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating post in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// RemovePost handles requests to remove a post.
+//
+// Requires a valid auth token.
+func (a API) RemovePost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.RemovePost(uint(id)); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error removing post from database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// GetRSSFeed handles requests for the newest published posts as an RSS 2.0
+// feed.
+func (a API) GetRSSFeed(w http.ResponseWriter, r *http.Request) {
+	a.writeFeed(w, r, feed.RSSContentType, feed.WriteRSS)
+}
+
+// GetAtomFeed handles requests for the newest published posts as an Atom
+// feed.
+func (a API) GetAtomFeed(w http.ResponseWriter, r *http.Request) {
+	a.writeFeed(w, r, feed.AtomContentType, feed.WriteAtom)
+}
+
+// GetJSONFeed handles requests for the newest published posts as a JSON
+// Feed.
+func (a API) GetJSONFeed(w http.ResponseWriter, r *http.Request) {
+	a.writeFeed(w, r, feed.JSONContentType, feed.WriteJSON)
+}
+
+// writeFeed sends the newest published posts as a feed in the format of the
+// given writer.
+func (a API) writeFeed(w http.ResponseWriter, r *http.Request, contentType string, write func(io.Writer, *feed.Feed) error) {
+	posts, err := a.db.GetPosts(database.PostFilter{Limit: feedSize})
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting posts from database: %s\n", err.Error())
+		return
+	}
+
+	cv, err := a.db.GetCV()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting CV from database: %s\n", err.Error())
+		return
+	}
+
+	settings, err := a.db.GetSettings()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting settings from database: %s\n", err.Error())
+		return
+	}
+
+	// Fall back to a generic title until the site title has been set
+	title := settings.SiteTitle
+	if title == "" {
+		title = "Blog"
+	}
+
+	site := a.siteURL(r)
+	covers := make([]string, 0, len(posts))
+	for _, post := range posts {
+		if post.CoverImage.Valid {
+			covers = append(covers, post.CoverImage.String)
+		}
+	}
+
+	images, err := a.publicImageURLs(site, covers)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting photos from database: %s\n", err.Error())
+		return
+	}
+
+	f := &feed.Feed{
+		Title:       title,
+		Description: settings.MetaDescription,
+		Author:      cv.Name,
+		Link:        site + "/blog",
+		FeedURL:     site + r.URL.Path,
+		Items:       make([]*feed.Item, 0, len(posts)),
+	}
+
+	for _, post := range posts {
+		item := &feed.Item{
+			Title:     post.Title,
+			Link:      site + "/blog/" + post.Slug,
+			Summary:   post.Excerpt,
+			Content:   post.Body,
+			Tags:      post.Tags,
+			Published: post.PublishedAt.Time,
+			Updated:   post.UpdatedAt,
+		}
+
+		if post.CoverImage.Valid {
+			item.Image = images[post.CoverImage.String]
+		}
+
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+
+		f.Items = append(f.Items, item)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(200)
+
+	if err := write(w, f); err != nil {
+		a.log.Errorf("error writing feed: %s\n", err.Error())
+	}
+}
+
+// validatePost checks that a post has a valid, unused slug and a title, and
+// that its cover image exists. Published posts without a publish date are
+// published now. It returns the HTTP status code to respond with if the post
+// isn't valid.
+func (a API) validatePost(post *entities.Post) (int, error) {
+	post.Slug = strings.TrimSpace(post.Slug)
+	if !isSlug(post.Slug) {
+		return http.StatusBadRequest, errors.New("slug must be lowercase letters and numbers separated by hyphens")
+	}
+
+	post.Title = strings.TrimSpace(post.Title)
+	if post.Title == "" {
+		return http.StatusBadRequest, errors.New("a post needs a title")
+	}
+
+	post.Excerpt = strings.TrimSpace(post.Excerpt)
+	if len([]rune(post.Excerpt)) > maxExcerptLength {
+		return http.StatusBadRequest, fmt.Errorf("excerpt can't be longer than %d characters", maxExcerptLength)
+	}
+
+	post.CoverImage.Valid = post.CoverImage.Valid && post.CoverImage.String != ""
+	if post.CoverImage.Valid {
+		if err := a.checkImageFile(post.CoverImage.String); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("cover image: %s", err.Error())
+		}
+	}
+
+	// Tags are matched exactly, so only keep one of each
+	seen := make(map[string]bool, len(post.Tags))
+	tags := make([]string, 0, len(post.Tags))
+	for _, tag := range trimStrings(post.Tags) {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	post.Tags = tags
+
+	if post.Published && !post.PublishedAt.Valid {
+		post.PublishedAt.Time = time.Now()
+		post.PublishedAt.Valid = true
+	}
+
+	existing, err := a.db.GetPostBySlug(post.Slug)
+	if err != nil && err != sql.ErrNoRows {
+		a.log.Errorf("error getting post from database: %s\n", err.Error())
+		return http.StatusInternalServerError, errors.New(dbError)
+	}
+
+	if err == nil && existing.ID != post.ID {
+		return http.StatusConflict, errors.New("a post with that slug already exists")
+	}
+
+	return http.StatusOK, nil
+}
@@ -0,0 +1,849 @@
+package v1
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gofrs/uuid"
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+const (
+	// maxProofingCommentLength is the longest comment a client can leave on a
+	// proofing photo.
+	maxProofingCommentLength = 2000
+
+	// proofingIPAttempts is how many times an IP address can try a gallery
+	// password within proofingIPWindow.
+	proofingIPAttempts = 10
+	proofingIPWindow   = 15 * time.Minute
+
+	// proofingGalleryAttempts is how many times a single gallery's password
+	// can be tried, from anywhere, within proofingGalleryWindow.
+	proofingGalleryAttempts = 50
+	proofingGalleryWindow   = time.Hour
+)
+
+// proofingGalleryKey is the request context key for the proofing gallery a
+// client is viewing.
+type proofingGalleryKey struct{}
+
+// proofingRouter sets up the routes a client uses to view a proofing gallery
+// through its share link.
+func (a API) proofingRouter() http.Handler {
+	r := chi.NewRouter()
+	r.Use(middleware.AllowContentType("application/json"))
+
+	r.Post("/access", a.AccessProofingGallery)
+
+	r.Group(func(r chi.Router) {
+		r.Use(a.proofingOnly)
+
+		r.Get("/", a.GetSharedProofingGallery)
+		r.Get("/photos/{photoID}/image", a.GetProofingPhotoImage)
+		r.Put("/photos/{photoID}/favourite", a.SetProofingFavourite)
+		r.Post("/photos/{photoID}/comments", a.AddProofingComment)
+	})
+
+	return r
+}
+
+// proofingOnly returns a middleware handler to check that the share link is
+// for a proofing gallery that hasn't expired, and that the client has opened
+// it with the gallery's password if it has one.
+func (a API) proofingOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gallery, ok := a.sharedProofingGallery(w, r)
+		if !ok {
+			return
+		}
+
+		if gallery.HasPassword {
+			cookie, err := r.Cookie(proofingCookieName)
+			if err != nil {
+				WriteError(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			session, err := a.db.GetProofingSession(cookie.Value)
+			if err != nil && err != sql.ErrNoRows {
+				WriteError(w, dbError, http.StatusInternalServerError)
+				a.log.Errorf("error getting proofing session from database: %s\n", err.Error())
+				return
+			}
+
+			if !sessionOpensGallery(session, gallery) {
+				WriteError(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			// If the session has expired, remove it from the database
+			if session.Expired() {
+				if err := a.db.RemoveProofingSession(session.Token); err != nil {
+					WriteError(w, dbError, http.StatusInternalServerError)
+					a.log.Errorf("error removing proofing session from database: %s\n", err.Error())
+					return
+				}
+
+				WriteError(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), proofingGalleryKey{}, gallery)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// sharedProofingGallery gets the proofing gallery for the share token in the
+// request URL. If there is no such gallery, or it has expired, an error is
+// written to the response and false is returned.
+func (a API) sharedProofingGallery(w http.ResponseWriter, r *http.Request) (*entities.ProofingGallery, bool) {
+	gallery, err := a.db.GetProofingGalleryByToken(chi.URLParam(r, "token"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "gallery not found", http.StatusNotFound)
+			return nil, false
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting proofing gallery from database: %s\n", err.Error())
+		return nil, false
+	}
+
+	if gallery.Expired() {
+		WriteError(w, "this gallery has expired", http.StatusGone)
+		return nil, false
+	}
+
+	return gallery, true
+}
+
+// proofingCookieName is the name of the cookie holding a client's proofing
+// session. Each cookie is scoped to the path of its gallery, so a client can
+// have several galleries open at once.
+const proofingCookieName = "proofing_session"
+
+// sessionOpensGallery checks if a proofing session was made for a gallery.
+// It doesn't check if the session has expired.
+func sessionOpensGallery(session *entities.ProofingSession, gallery *entities.ProofingGallery) bool {
+	return session != nil && session.GalleryID == gallery.ID
+}
+
+// proofingCookie makes the cookie for a proofing session. The cookie is only
+// sent back for requests under the gallery's share link, which is the path
+// of the access request without its last segment.
+func proofingCookie(r *http.Request, session *entities.ProofingSession) *http.Cookie {
+	return &http.Cookie{
+		Name:     proofingCookieName,
+		Value:    session.Token,
+		Path:     path.Dir(r.URL.Path),
+		MaxAge:   session.MaxAge,
+		HttpOnly: true,
+		Secure:   true,
+	}
+}
+
+// allowProofingAttempt checks if another password attempt is allowed for a
+// gallery, both from the client's IP address and for the gallery as a whole.
+// If it is, the attempt is counted.
+func (a API) allowProofingAttempt(r *http.Request, gallery *entities.ProofingGallery, now time.Time) bool {
+	if !a.proofingIPLimiter.Allow(clientIP(r), now) {
+		return false
+	}
+
+	return a.proofingGalleryLimiter.Allow(strconv.FormatUint(uint64(gallery.ID), 10), now)
+}
+
+// AccessProofingGallery handles a client opening a password protected
+// proofing gallery. If the password matches, a session cookie is set that
+// gives access to the gallery. Password attempts are limited per IP address
+// and per gallery.
+func (a API) AccessProofingGallery(w http.ResponseWriter, r *http.Request) {
+	gallery, ok := a.sharedProofingGallery(w, r)
+	if !ok {
+		return
+	}
+
+	// Galleries without a password can be viewed without a session
+	if !gallery.HasPassword {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var req ProofingAccessRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding proofing access body: %s\n", err.Error())
+		return
+	}
+
+	if !a.allowProofingAttempt(r, gallery, time.Now()) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(proofingIPWindow.Seconds())))
+		WriteError(w, "too many attempts, please try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	valid, err := a.db.CheckProofingPassword(gallery.ID, req.Password)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error checking proofing gallery password: %s\n", err.Error())
+		return
+	}
+
+	if !valid {
+		WriteError(w, "Invalid password", http.StatusUnauthorized)
+		return
+	}
+
+	session, err := entities.NewProofingSession(gallery.ID)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error creating new proofing session: %s\n", err.Error())
+		return
+	}
+
+	if err := a.db.AddProofingSession(session); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding proofing session to database: %s\n", err.Error())
+		return
+	}
+
+	http.SetCookie(w, proofingCookie(r, session))
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetSharedProofingGallery handles a client's request to view a proofing
+// gallery with its photos, favourites, and comments.
+func (a API) GetSharedProofingGallery(w http.ResponseWriter, r *http.Request) {
+	gallery := r.Context().Value(proofingGalleryKey{}).(*entities.ProofingGallery)
+
+	// The client already has the share link, so don't send it back
+	gallery.ShareToken = ""
+
+	// Photos are only served through the gallery, so clients don't need to
+	// know what the files are called
+	for _, photo := range gallery.Photos {
+		photo.FileName = ""
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(gallery)
+}
+
+// GetProofingPhotoImage handles a client's request for the image file of a
+// photo in a proofing gallery.
+func (a API) GetProofingPhotoImage(w http.ResponseWriter, r *http.Request) {
+	photo, ok := proofingPhoto(w, r)
+	if !ok {
+		return
+	}
+
+	if photo.StoredName == "" {
+		WriteError(w, "photo not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private")
+	http.ServeFile(w, r, filepath.Join(a.proofingDir(), photo.StoredName))
+}
+
+// SetProofingFavourite handles a client marking or unmarking a photo in a
+// proofing gallery as a favourite.
+func (a API) SetProofingFavourite(w http.ResponseWriter, r *http.Request) {
+	photo, ok := proofingPhoto(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+
+	var req ProofingFavouriteRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in proofing favourite request: %s\n", err.Error())
+		return
+	}
+
+	gallery := r.Context().Value(proofingGalleryKey{}).(*entities.ProofingGallery)
+	if err := a.db.SetProofingFavourite(gallery.ID, photo.ID, req.Favourite); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "photo not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error setting proofing favourite in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// AddProofingComment handles a client leaving a comment on a photo in a
+// proofing gallery.
+func (a API) AddProofingComment(w http.ResponseWriter, r *http.Request) {
+	photo, ok := proofingPhoto(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+
+	var req ProofingCommentRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in proofing comment request: %s\n", err.Error())
+		return
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		WriteError(w, "a comment can't be empty", http.StatusBadRequest)
+		return
+	}
+
+	if len([]rune(body)) > maxProofingCommentLength {
+		WriteError(w, fmt.Sprintf("a comment can't be longer than %d characters", maxProofingCommentLength), http.StatusBadRequest)
+		return
+	}
+
+	gallery := r.Context().Value(proofingGalleryKey{}).(*entities.ProofingGallery)
+	comment, err := a.db.AddProofingComment(gallery.ID, photo.ID, body)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "photo not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding proofing comment to database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(comment)
+}
+
+// proofingPhoto gets the photo in the request URL from the proofing gallery
+// in the request context. If the photo isn't in the gallery, an error is
+// written to the response and false is returned.
+func proofingPhoto(w http.ResponseWriter, r *http.Request) (*entities.ProofingPhoto, bool) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "photoID"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	gallery := r.Context().Value(proofingGalleryKey{}).(*entities.ProofingGallery)
+	for _, photo := range gallery.Photos {
+		if photo.ID == uint(id) {
+			return photo, true
+		}
+	}
+
+	WriteError(w, "photo not found", http.StatusNotFound)
+	return nil, false
+}
+
+// GetProofingGalleries handles requests to get all proofing galleries.
+//
+// Requires a valid auth token.
+func (a API) GetProofingGalleries(w http.ResponseWriter, r *http.Request) {
+	ret, err := a.db.GetProofingGalleries()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting proofing galleries from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// GetProofingGallery handles requests to get a proofing gallery with its
+// photos, favourites, and comments.
+//
+// Requires a valid auth token.
+func (a API) GetProofingGallery(w http.ResponseWriter, r *http.Request) {
+	gallery, ok := a.proofingGallery(w, r)
+	if !ok {
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(gallery)
+}
+
+// AddProofingGallery handles requests to create a new proofing gallery. A
+// share token is generated for the gallery, and sent back with it.
+//
+// Requires a valid auth token.
+func (a API) AddProofingGallery(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req ProofingGalleryRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in add proofing gallery request: %s\n", err.Error())
+		return
+	}
+
+	gallery, err := newProofingGallery(&req)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	token, err := entities.NewShareToken()
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error creating share token: %s\n", err.Error())
+		return
+	}
+
+	gallery.ShareToken = token
+
+	id, err := a.db.AddProofingGallery(gallery, req.Password)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding proofing gallery to database: %s\n", err.Error())
+		return
+	}
+
+	ret, err := a.db.GetProofingGallery(id)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting proofing gallery from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// UpdateProofingGallery handles requests to change the details of a proofing
+// gallery. The share token stays the same.
+//
+// Requires a valid auth token.
+func (a API) UpdateProofingGallery(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var req ProofingGalleryRequest
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&req); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in proofing gallery update request: %s\n", err.Error())
+		return
+	}
+
+	gallery, err := newProofingGallery(&req)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	gallery.ID = uint(id)
+
+	if err := a.db.UpdateProofingGallery(gallery); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "gallery not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating proofing gallery in database: %s\n", err.Error())
+		return
+	}
+
+	if req.Password != "" || req.RemovePassword {
+		if err := a.db.SetProofingPassword(gallery.ID, req.Password); err != nil {
+			WriteError(w, dbError, http.StatusInternalServerError)
+			a.log.Errorf("error setting proofing gallery password in database: %s\n", err.Error())
+			return
+		}
+	}
+
+	w.WriteHeader(200)
+}
+
+// RemoveProofingGallery handles requests to remove a proofing gallery, along
+// with its photos, favourites, and comments.
+//
+// Requires a valid auth token.
+func (a API) RemoveProofingGallery(w http.ResponseWriter, r *http.Request) {
+	gallery, ok := a.proofingGallery(w, r)
+	if !ok {
+		return
+	}
+
+	if err := a.db.RemoveProofingGallery(gallery.ID); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error removing proofing gallery from database: %s\n", err.Error())
+		return
+	}
+
+	a.removeProofingFiles(gallery.Photos)
+
+	w.WriteHeader(200)
+}
+
+// AddProofingPhotos handles requests to upload photos to a proofing gallery.
+// The body is a multipart form with the images set to the `files` key. The
+// photos are stored privately, and clients can only get them through the
+// gallery's share link.
+//
+// Requires a valid auth token.
+func (a API) AddProofingPhotos(w http.ResponseWriter, r *http.Request) {
+	gallery, ok := a.proofingGallery(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseMultipartForm(8 * 1024 * 1024); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error parsing multipart form: %s\n", err.Error())
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	files := r.MultipartForm.File["files"]
+	if len(files) == 0 {
+		WriteError(w, "no photos were uploaded", http.StatusBadRequest)
+		return
+	}
+
+	// Photos are removed by name, so names can't be used twice in a gallery
+	names := make(map[string]bool, len(gallery.Photos)+len(files))
+	for _, photo := range gallery.Photos {
+		names[photo.FileName] = true
+	}
+
+	for _, header := range files {
+		name := filepath.Base(header.Filename)
+		if name == "." || name == string(filepath.Separator) {
+			WriteError(w, "every photo needs a file name", http.StatusBadRequest)
+			return
+		}
+
+		if names[name] {
+			WriteError(w, "the gallery already has a photo named "+name, http.StatusConflict)
+			return
+		}
+
+		names[name] = true
+	}
+
+	if err := os.MkdirAll(a.proofingDir(), 0700); err != nil {
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error creating proofing photo directory: %s\n", err.Error())
+		return
+	}
+
+	photos := make([]*entities.ProofingPhoto, 0, len(files))
+	for _, header := range files {
+		photo, status, err := a.saveProofingPhoto(header)
+		if err != nil {
+			a.removeProofingFiles(photos)
+			WriteError(w, err.Error(), status)
+			return
+		}
+
+		photos = append(photos, photo)
+	}
+
+	if err := a.db.AddProofingPhotos(gallery.ID, photos); err != nil {
+		a.removeProofingFiles(photos)
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding proofing photos to database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// RemoveProofingPhotos handles requests to remove photos from a proofing
+// gallery, along with their files and comments. The body is a JSON array of
+// the names the photos were uploaded with.
+//
+// Requires a valid auth token.
+func (a API) RemoveProofingPhotos(w http.ResponseWriter, r *http.Request) {
+	gallery, ok := a.proofingGallery(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+
+	var files []string
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&files); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding image files to remove: %s\n", err.Error())
+		return
+	}
+
+	if err := a.db.RemoveProofingPhotos(gallery.ID, files); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error removing proofing photos from database: %s\n", err.Error())
+		return
+	}
+
+	remove := make(map[string]bool, len(files))
+	for _, file := range files {
+		remove[file] = true
+	}
+
+	removed := make([]*entities.ProofingPhoto, 0, len(files))
+	for _, photo := range gallery.Photos {
+		if remove[photo.FileName] {
+			removed = append(removed, photo)
+		}
+	}
+
+	a.removeProofingFiles(removed)
+
+	w.WriteHeader(200)
+}
+
+// ExportProofingSelection handles requests to export the photos a client has
+// marked as favourites in a proofing gallery, along with their comments, as a
+// CSV file.
+//
+// Requires a valid auth token.
+func (a API) ExportProofingSelection(w http.ResponseWriter, r *http.Request) {
+	gallery, ok := a.proofingGallery(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"proofing-%d-selection.csv\"", gallery.ID))
+	w.WriteHeader(200)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"file_name", "comments"})
+	for _, photo := range gallery.Photos {
+		if !photo.Favourite {
+			continue
+		}
+
+		comments := make([]string, 0, len(photo.Comments))
+		for _, comment := range photo.Comments {
+			comments = append(comments, comment.Body)
+		}
+
+		writer.Write([]string{csvCell(photo.FileName), csvCell(strings.Join(comments, "\n"))})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		a.log.Errorf("error writing proofing selection: %s\n", err.Error())
+	}
+}
+
+// proofingDir is the private directory that proofing photos are stored in.
+func (a API) proofingDir() string {
+	return filepath.Join(a.privateDir, "proofing")
+}
+
+// saveProofingPhoto checks that an uploaded file is an image, and saves it
+// under a random name. The type of the file is worked out from its contents,
+// not from what the client says it is. It returns the HTTP status code to
+// respond with if the file can't be saved.
+func (a API) saveProofingPhoto(header *multipart.FileHeader) (*entities.ProofingPhoto, int, error) {
+	name := filepath.Base(header.Filename)
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	defer file.Close()
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, http.StatusBadRequest, err
+	}
+
+	contentType := http.DetectContentType(sniff[:n])
+	ext, ok := attachmentTypes[contentType]
+	if !ok || !strings.HasPrefix(contentType, "image/") {
+		return nil, http.StatusBadRequest, fmt.Errorf("%s isn't a JPEG, PNG, GIF, or WebP image", name)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	photo := &entities.ProofingPhoto{
+		FileName:   name,
+		StoredName: id.String() + ext,
+	}
+
+	if err := saveFile(file, filepath.Join(a.proofingDir(), photo.StoredName)); err != nil {
+		a.log.Errorf("error saving proofing photo: %s\n", err.Error())
+		return nil, http.StatusInternalServerError, errors.New("unable to save photo")
+	}
+
+	return photo, http.StatusOK, nil
+}
+
+// removeProofingFiles removes the stored files of proofing photos.
+func (a API) removeProofingFiles(photos []*entities.ProofingPhoto) {
+	for _, photo := range photos {
+		if photo.StoredName == "" {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(a.proofingDir(), photo.StoredName)); err != nil && !os.IsNotExist(err) {
+			a.log.Warnf("unable to remove proofing photo: %s\n", err.Error())
+		}
+	}
+}
+
+// StoreProofingPhotos copies the files of proofing photos that were added
+// before photos were stored privately from the images directory into the
+// private proofing directory. The copies in the images directory are left in
+// place, in case anything else uses them.
+func (a API) StoreProofingPhotos() error {
+	photos, err := a.db.GetUnstoredProofingPhotos()
+	if err != nil || len(photos) == 0 {
+		return err
+	}
+
+	if err := os.MkdirAll(a.proofingDir(), 0700); err != nil {
+		return err
+	}
+
+	stored := 0
+	for _, photo := range photos {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return err
+		}
+
+		storedName := id.String() + strings.ToLower(filepath.Ext(photo.FileName))
+		if err := copyFile(filepath.Join(a.imageDir, photo.FileName), filepath.Join(a.proofingDir(), storedName)); err != nil {
+			a.log.Warnf("unable to store proofing photo '%s': %s\n", photo.FileName, err.Error())
+			continue
+		}
+
+		if err := a.db.SetProofingStoredName(photo.ID, storedName); err != nil {
+			os.Remove(filepath.Join(a.proofingDir(), storedName))
+			return err
+		}
+
+		stored++
+	}
+
+	a.log.Infof("Copied %d proofing photos into private storage. The originals in the images directory can be removed if nothing else uses them.\n", stored)
+	return nil
+}
+
+// csvCell makes a value safe to open in a spreadsheet. Values that start with
+// a character a spreadsheet would treat as the start of a formula are
+// prefixed with a single quote, so they're shown as text instead of run.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// proofingGallery gets the proofing gallery with the ID in the request URL.
+// If there is no such gallery, an error is written to the response and false
+// is returned.
+func (a API) proofingGallery(w http.ResponseWriter, r *http.Request) (*entities.ProofingGallery, bool) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	gallery, err := a.db.GetProofingGallery(uint(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "gallery not found", http.StatusNotFound)
+			return nil, false
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting proofing gallery from database: %s\n", err.Error())
+		return nil, false
+	}
+
+	return gallery, true
+}
+
+// newProofingGallery creates a proofing gallery from a request, checking that
+// it has a title.
+func newProofingGallery(req *ProofingGalleryRequest) (*entities.ProofingGallery, error) {
+	gallery := &entities.ProofingGallery{
+		Title:      strings.TrimSpace(req.Title),
+		ClientName: strings.TrimSpace(req.ClientName),
+		ExpiresAt:  req.ExpiresAt,
+	}
+
+	if gallery.Title == "" {
+		return nil, errors.New("a gallery needs a title")
+	}
+
+	// A zero expiry date means the link never expires
+	gallery.ExpiresAt.Valid = gallery.ExpiresAt.Valid && !gallery.ExpiresAt.Time.IsZero()
+
+	return gallery, nil
+}
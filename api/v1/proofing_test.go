@@ -0,0 +1,215 @@
+package v1
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/ratelimit"
+)
+
+// TestSessionOpensGallery ensures that a proofing session only opens the
+// gallery it was made for.
+func TestSessionOpensGallery(t *testing.T) {
+	gallery := &entities.ProofingGallery{ID: 2}
+
+	tests := []struct {
+		name     string
+		session  *entities.ProofingSession
+		expected bool
+	}{
+		{"no session", nil, false},
+		{"other gallery", &entities.ProofingSession{GalleryID: 3}, false},
+		{"same gallery", &entities.ProofingSession{GalleryID: 2}, true},
+	}
+
+	for _, test := range tests {
+		// When
+		result := sessionOpensGallery(test.session, gallery)
+
+		// Then
+		if result != test.expected {
+			t.Fatalf("%s: result does not match expected: got %v, expected: %v\n", test.name, result, test.expected)
+		}
+	}
+}
+
+// TestProofingCookie ensures that a proofing session cookie is scoped to the
+// gallery's share link.
+func TestProofingCookie(t *testing.T) {
+	// Given
+	r := httptest.NewRequest("POST", "/api/v1/proofing/abc123/access", nil)
+	session := &entities.ProofingSession{Token: "token", GalleryID: 2, MaxAge: 60}
+
+	// When
+	cookie := proofingCookie(r, session)
+
+	// Then
+	if cookie.Name != proofingCookieName || cookie.Value != "token" {
+		t.Fatalf("result does not match expected: got %s=%s, expected: %s=%s\n", cookie.Name, cookie.Value, proofingCookieName, "token")
+	}
+
+	if cookie.Path != "/api/v1/proofing/abc123" {
+		t.Fatalf("result does not match expected: got %s, expected: %s\n", cookie.Path, "/api/v1/proofing/abc123")
+	}
+
+	if cookie.MaxAge != 60 || !cookie.HttpOnly || !cookie.Secure {
+		t.Fatalf("result does not match expected: got %+v\n", cookie)
+	}
+}
+
+// TestAllowProofingAttempt ensures that password attempts are limited both
+// per IP address and per gallery.
+func TestAllowProofingAttempt(t *testing.T) {
+	// Given
+	a := API{
+		proofingIPLimiter:      ratelimit.New(2, time.Minute),
+		proofingGalleryLimiter: ratelimit.New(3, time.Minute),
+	}
+	gallery := &entities.ProofingGallery{ID: 1}
+	other := &entities.ProofingGallery{ID: 2}
+	now := time.Now()
+
+	from := func(ip string) bool {
+		r := httptest.NewRequest("POST", "/api/v1/proofing/abc/access", nil)
+		r.RemoteAddr = ip + ":1234"
+		return a.allowProofingAttempt(r, gallery, now)
+	}
+
+	tests := []struct {
+		name     string
+		ip       string
+		expected bool
+	}{
+		{"first attempt", "1.1.1.1", true},
+		{"second attempt", "1.1.1.1", true},
+		{"over the IP limit", "1.1.1.1", false},
+		{"another IP", "2.2.2.2", true},
+		{"over the gallery limit", "3.3.3.3", false},
+	}
+
+	for _, test := range tests {
+		// When
+		result := from(test.ip)
+
+		// Then
+		if result != test.expected {
+			t.Fatalf("%s: result does not match expected: got %v, expected: %v\n", test.name, result, test.expected)
+		}
+	}
+
+	// Other galleries have their own limit
+	r := httptest.NewRequest("POST", "/api/v1/proofing/def/access", nil)
+	r.RemoteAddr = "4.4.4.4:1234"
+	if !a.allowProofingAttempt(r, other, now) {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", false, true)
+	}
+}
+
+// TestCSVCell ensures that values a spreadsheet would run as a formula are
+// made into text, and other values are left alone.
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"Love this one", "Love this one"},
+		{"photo-1.jpg", "photo-1.jpg"},
+		{"=HYPERLINK(\"http://example.com\")", "'=HYPERLINK(\"http://example.com\")"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+	}
+
+	for _, test := range tests {
+		// When
+		result := csvCell(test.value)
+
+		// Then
+		if result != test.expected {
+			t.Fatalf("result for %q does not match expected: got %q, expected: %q\n", test.value, result, test.expected)
+		}
+	}
+}
+
+// uploadedFile makes the header of an uploaded file with the given name and
+// contents, as if it was sent in a multipart form.
+func uploadedFile(t *testing.T, name string, contents []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("files", name)
+	if err != nil {
+		t.Fatalf("unexpected error creating form: %s\n", err)
+	}
+
+	part.Write(contents)
+	writer.Close()
+
+	r := httptest.NewRequest("POST", "/", &body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	if err := r.ParseMultipartForm(1024 * 1024); err != nil {
+		t.Fatalf("unexpected error parsing form: %s\n", err)
+	}
+
+	return r.MultipartForm.File["files"][0]
+}
+
+// TestSaveProofingPhoto ensures that uploaded photos are stored privately
+// under a random name, keeping the name they were uploaded with.
+func TestSaveProofingPhoto(t *testing.T) {
+	// Given
+	a := API{privateDir: t.TempDir()}
+	if err := os.MkdirAll(a.proofingDir(), 0700); err != nil {
+		t.Fatalf("unexpected error: %s\n", err)
+	}
+
+	var contents bytes.Buffer
+	png.Encode(&contents, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	header := uploadedFile(t, "../IMG_0001.png", contents.Bytes())
+
+	// When
+	photo, status, err := a.saveProofingPhoto(header)
+
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %s (%d)\n", err, status)
+	}
+
+	if photo.FileName != "IMG_0001.png" {
+		t.Fatalf("result does not match expected: got %s, expected: %s\n", photo.FileName, "IMG_0001.png")
+	}
+
+	if photo.StoredName == photo.FileName || filepath.Ext(photo.StoredName) != ".png" {
+		t.Fatalf("stored name does not match expected: got %s\n", photo.StoredName)
+	}
+
+	if _, err := os.Stat(filepath.Join(a.privateDir, "proofing", photo.StoredName)); err != nil {
+		t.Fatalf("expected the photo to be stored privately, got: %s\n", err)
+	}
+}
+
+// TestSaveProofingPhoto_NotImage ensures that files that aren't images can't
+// be added to a proofing gallery, whatever they're called.
+func TestSaveProofingPhoto_NotImage(t *testing.T) {
+	// Given
+	a := API{privateDir: t.TempDir()}
+	header := uploadedFile(t, "photo.png", []byte("%PDF-1.4 not a photo"))
+
+	// When
+	_, status, err := a.saveProofingPhoto(header)
+
+	// Then
+	if err == nil || status != http.StatusBadRequest {
+		t.Fatalf("result does not match expected: got %v (%d), expected a %d error\n", err, status, http.StatusBadRequest)
+	}
+}
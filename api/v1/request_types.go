@@ -1,20 +1,131 @@
 package v1
 
+import (
+	"encoding/json"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
 // AddUserRequest is the username and password to create a new user with.
 type AddUserRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
 
+// AddDownloadLinkRequest holds the file to create a signed download link for,
+// how many seconds the link lasts, and how many times it can be used.
+type AddDownloadLinkRequest struct {
+	Directory    string     `json:"directory"`
+	FileName     string     `json:"fileName"`
+	ExpiresIn    int64      `json:"expiresIn,omitempty"`
+	MaxDownloads db.NullInt `json:"maxDownloads"`
+}
+
+// BulkPhotoPatchRequest holds the IDs of the photos to edit, and the JSON Merge
+// Patch to apply to each of them.
+type BulkPhotoPatchRequest struct {
+	IDs     []uint          `json:"ids"`
+	Changes json.RawMessage `json:"changes"`
+}
+
 // ChangeThumbnailRequest holds the file name of the thumbnail to update a
 // project with.
 type ChangeThumbnailRequest struct {
 	Thumbnail string `json:"thumbnail"`
 }
 
+// CloneProjectRequest holds the name for a cloned project, and whether its
+// image files should be copied instead of shared with the original.
+type CloneProjectRequest struct {
+	Name      string `json:"name"`
+	CopyFiles bool   `json:"copyFiles"`
+}
+
+// ContactRequest is a message sent through the contact form. Website is a
+// honeypot field that's hidden from people, so only bots fill it in. Token is
+// the contact form token that was given out when the form was opened.
+type ContactRequest struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+	Website string `json:"website"`
+	Token   string `json:"token"`
+}
+
+// ContactReadRequest marks a contact message as read or unread.
+type ContactReadRequest struct {
+	Read bool `json:"read"`
+}
+
+// ContactArchiveRequest moves a contact message to or from the archive.
+type ContactArchiveRequest struct {
+	Archived bool `json:"archived"`
+}
+
 // LoginRequest is the username and password expected from the login endpoint.
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Extended bool   `json:"extended,omitempty"`
 }
+
+// NewsletterTokenRequest holds a signed newsletter token from a link in an
+// email.
+type NewsletterTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// OrderRequest holds a customer's details and the prints they want to order.
+type OrderRequest struct {
+	Name    string             `json:"name"`
+	Email   string             `json:"email"`
+	Address entities.Address   `json:"address"`
+	Items   []OrderItemRequest `json:"items"`
+}
+
+// OrderItemRequest is a print variant to order, and how many of it.
+type OrderItemRequest struct {
+	VariantID uint `json:"variantId"`
+	Quantity  int  `json:"quantity"`
+}
+
+// OrderStatusRequest moves an order to a new state.
+type OrderStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// ProofingGalleryRequest holds the details to create or update a proofing
+// gallery with. When updating, an empty password keeps the current one unless
+// RemovePassword is set.
+type ProofingGalleryRequest struct {
+	Title          string      `json:"title"`
+	ClientName     string      `json:"clientName"`
+	Password       string      `json:"password,omitempty"`
+	RemovePassword bool        `json:"removePassword,omitempty"`
+	ExpiresAt      db.NullTime `json:"expiresAt"`
+}
+
+// ProofingAccessRequest is the password a client sends to open a password
+// protected proofing gallery.
+type ProofingAccessRequest struct {
+	Password string `json:"password"`
+}
+
+// ProofingFavouriteRequest marks or unmarks a proofing photo as a favourite.
+type ProofingFavouriteRequest struct {
+	Favourite bool `json:"favourite"`
+}
+
+// ProofingCommentRequest holds a comment to leave on a proofing photo.
+type ProofingCommentRequest struct {
+	Body string `json:"body"`
+}
+
+// SubscribeRequest is an email address to sign up for the newsletter with.
+// Website is a honeypot field, like on the contact form.
+type SubscribeRequest struct {
+	Email   string `json:"email"`
+	Website string `json:"website"`
+}
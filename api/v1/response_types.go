@@ -1,7 +1,47 @@
 package v1
 
+import (
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/seo"
+)
+
+// AddImagesResponse is sent after images are added to the photography gallery
+// or to a project. It lists any of the new images that look like images that
+// were already on the site.
+type AddImagesResponse struct {
+	Duplicates []*entities.DuplicateImage `json:"duplicates"`
+}
+
 // CheckSessionResponse is sent when a client is trying to check
 // if they have a valid session.
 type CheckSessionResponse struct {
 	Valid bool `json:"valid"`
 }
+
+// ContactTokenResponse holds the token to send the contact form with.
+type ContactTokenResponse struct {
+	Token string `json:"token"`
+}
+
+// OrderResponse is sent after an order is made. The customer pays for the
+// order at the checkout URL.
+type OrderResponse struct {
+	Reference   string `json:"reference"`
+	Status      string `json:"status"`
+	Total       int    `json:"total"`
+	Currency    string `json:"currency"`
+	CheckoutURL string `json:"checkoutUrl"`
+}
+
+// ProjectSEOResponse holds the metadata to render in a project's page. The
+// tags go in the page's head as meta tags, and the JSON-LD goes in a script
+// tag with the application/ld+json type.
+type ProjectSEOResponse struct {
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Canonical   string            `json:"canonical"`
+	Image       string            `json:"image"`
+	NoIndex     bool              `json:"noIndex"`
+	Tags        []seo.Tag         `json:"tags"`
+	JSONLD      *seo.CreativeWork `json:"jsonLd"`
+}
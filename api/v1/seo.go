@@ -0,0 +1,208 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/seo"
+)
+
+// seoDescriptionLength is how long descriptions made from a project's caption
+// can be. Search engines and social networks cut them off around here.
+const seoDescriptionLength = 160
+
+// GetProjectSEO handles requests for the metadata to render in a project's
+// page: the page title, meta tags for search engines and social networks,
+// and schema.org JSON-LD. The project's SEO overrides are used where they're
+// set, falling back to the project's own fields and then the site settings.
+func (a API) GetProjectSEO(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	project, err := a.db.GetProject(name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "project not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting project from database: %s\n", err.Error())
+		return
+	}
+
+	// Drafts aren't visible to the public
+	if !project.Published {
+		WriteError(w, "project not found", http.StatusNotFound)
+		return
+	}
+
+	settings, err := a.db.GetSettings()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting settings from database: %s\n", err.Error())
+		return
+	}
+
+	cv, err := a.db.GetCV()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting CV from database: %s\n", err.Error())
+		return
+	}
+
+	site := a.siteURL(r)
+	files := append([]string{project.ShareImage.String, project.Thumbnail, settings.ShareImage.String}, project.Images...)
+	images, err := a.publicImageURLs(site, files)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting photos from database: %s\n", err.Error())
+		return
+	}
+
+	ret := projectSEO(site, images, project, settings, cv)
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// projectSEO works out the metadata for a project's page. Images are linked
+// with the URLs in images, keyed by file name.
+func projectSEO(site string, images map[string]string, project *entities.GalleryItem, settings *entities.Settings, cv *entities.CV) *ProjectSEOResponse {
+	title := project.MetaTitle
+	if title == "" {
+		title = project.Title
+	}
+
+	description := project.MetaDescription
+	if description == "" {
+		description = seo.Truncate(project.Caption, seoDescriptionLength)
+	}
+	if description == "" {
+		description = settings.MetaDescription
+	}
+
+	image := ""
+	switch {
+	case project.ShareImage.Valid:
+		image = images[project.ShareImage.String]
+	case project.Thumbnail != "":
+		image = images[project.Thumbnail]
+	case settings.ShareImage.Valid:
+		image = images[settings.ShareImage.String]
+	}
+
+	canonical := site + "/gallery/" + project.Name
+	if project.CanonicalURL.Valid {
+		canonical = project.CanonicalURL.String
+	}
+
+	// The document title has the site title on the end, like most sites do
+	documentTitle := title
+	if settings.SiteTitle != "" && settings.SiteTitle != title {
+		documentTitle = title + " | " + settings.SiteTitle
+	}
+
+	work := &seo.CreativeWork{
+		Context:     seo.Context,
+		Type:        "CreativeWork",
+		Name:        title,
+		Description: description,
+		URL:         canonical,
+		Keywords:    strings.Join(project.Services, ", "),
+	}
+
+	if image != "" {
+		work.Image = append(work.Image, image)
+	}
+	for _, file := range project.Images {
+		work.Image = append(work.Image, images[file])
+	}
+
+	if cv.Name != "" {
+		work.Creator = &seo.Agent{Type: "Person", Name: cv.Name, URL: cv.Website}
+		if work.Creator.URL == "" {
+			work.Creator.URL = site + "/"
+		}
+	}
+
+	for _, credit := range project.Credits {
+		agent := &seo.Agent{Type: "Person", Name: credit.Name, URL: credit.URL.String}
+		if credit.Kind == entities.ContributorOrganisation {
+			agent.Type = "Organization"
+		}
+
+		work.Contributors = append(work.Contributors, agent)
+	}
+
+	// A project is finished in its last year
+	switch {
+	case project.YearEnd.Valid:
+		work.DateCreated = strconv.Itoa(int(project.YearEnd.Int32))
+	case project.YearStart.Valid:
+		work.DateCreated = strconv.Itoa(int(project.YearStart.Int32))
+	}
+
+	if !project.UpdatedAt.IsZero() {
+		work.DateModified = project.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+
+	if project.LiveURL.Valid {
+		work.SameAs = []string{project.LiveURL.String}
+	}
+
+	return &ProjectSEOResponse{
+		Title:       documentTitle,
+		Description: description,
+		Canonical:   canonical,
+		Image:       image,
+		NoIndex:     project.NoIndex,
+		Tags: seo.Tags(&seo.Page{
+			Title:       title,
+			Description: description,
+			URL:         canonical,
+			Image:       image,
+			SiteName:    settings.SiteTitle,
+			Type:        "article",
+			NoIndex:     project.NoIndex,
+		}),
+		JSONLD: work,
+	}
+}
+
+// validateProjectSEO checks the SEO overrides of a project, and that its share
+// image exists. Whitespace is trimmed, and empty overrides are cleared.
+func (a API) validateProjectSEO(project *entities.GalleryItem) error {
+	project.MetaTitle = strings.TrimSpace(project.MetaTitle)
+	project.MetaDescription = strings.TrimSpace(project.MetaDescription)
+	if len([]rune(project.MetaDescription)) > maxMetaDescriptionLength {
+		return fmt.Errorf("meta description can't be longer than %d characters", maxMetaDescriptionLength)
+	}
+
+	project.ShareImage.String = strings.TrimSpace(project.ShareImage.String)
+	project.ShareImage.Valid = project.ShareImage.String != ""
+	if project.ShareImage.Valid {
+		if err := a.checkImageFile(project.ShareImage.String); err != nil {
+			return fmt.Errorf("share image: %s", err.Error())
+		}
+	}
+
+	project.CanonicalURL.String = strings.TrimSpace(project.CanonicalURL.String)
+	project.CanonicalURL.Valid = project.CanonicalURL.String != ""
+	if project.CanonicalURL.Valid && !isHTTPURL(project.CanonicalURL.String) {
+		return errors.New("canonical URL must be an http or https URL")
+	}
+
+	return nil
+}
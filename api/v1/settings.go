@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+const (
+	// maxSiteTitleLength is the longest the site title can be.
+	maxSiteTitleLength = 100
+
+	// maxFooterTextLength is the longest the footer text can be.
+	maxFooterTextLength = 1000
+
+	// maxRobotsTxtLength is the longest the robots.txt rules can be. Google
+	// stops reading robots.txt files at 500KiB, but rules for a portfolio
+	// never need to be anywhere near that.
+	maxRobotsTxtLength = 10000
+)
+
+// colorPattern matches hex colours like #fff or #ffffff.
+var colorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// GetPublicSettings handles requests to get the site-wide settings that the
+// public site needs.
+func (a API) GetPublicSettings(w http.ResponseWriter, r *http.Request) {
+	ret, err := a.db.GetSettings()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting settings from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret.PublicSettings)
+}
+
+// GetSettings handles requests to get all of the site-wide settings.
+//
+// Requires a valid auth token.
+func (a API) GetSettings(w http.ResponseWriter, r *http.Request) {
+	ret, err := a.db.GetSettings()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting settings from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// UpdateSettings handles requests to replace the site-wide settings.
+//
+// Requires a valid auth token.
+func (a API) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var settings entities.Settings
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&settings); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in settings update request: %s\n", err.Error())
+		return
+	}
+
+	if err := a.validateSettings(&settings); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.UpdateSettings(&settings); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating settings in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// validateSettings checks that the site-wide settings are usable. Whitespace
+// is trimmed from the text settings.
+func (a API) validateSettings(settings *entities.Settings) error {
+	settings.SiteTitle = strings.TrimSpace(settings.SiteTitle)
+	settings.MetaDescription = strings.TrimSpace(settings.MetaDescription)
+	settings.PrimaryColor = strings.TrimSpace(settings.PrimaryColor)
+	settings.SecondaryColor = strings.TrimSpace(settings.SecondaryColor)
+	settings.FooterText = strings.TrimSpace(settings.FooterText)
+	settings.NotificationEmail = strings.TrimSpace(settings.NotificationEmail)
+	settings.RobotsTxt = strings.TrimSpace(settings.RobotsTxt)
+	settings.UpdatedAt = time.Time{}
+
+	if settings.SiteTitle == "" {
+		return errors.New("the site needs a title")
+	}
+
+	if len([]rune(settings.SiteTitle)) > maxSiteTitleLength {
+		return fmt.Errorf("site title can't be longer than %d characters", maxSiteTitleLength)
+	}
+
+	if len([]rune(settings.MetaDescription)) > maxMetaDescriptionLength {
+		return fmt.Errorf("meta description can't be longer than %d characters", maxMetaDescriptionLength)
+	}
+
+	settings.ShareImage.Valid = settings.ShareImage.Valid && settings.ShareImage.String != ""
+	if settings.ShareImage.Valid {
+		if err := a.checkImageFile(settings.ShareImage.String); err != nil {
+			return fmt.Errorf("share image: %s", err.Error())
+		}
+	}
+
+	for _, color := range []string{settings.PrimaryColor, settings.SecondaryColor} {
+		if color != "" && !colorPattern.MatchString(color) {
+			return errors.New("colours must be hex colours like #1a2b3c: " + color)
+		}
+	}
+
+	if len([]rune(settings.FooterText)) > maxFooterTextLength {
+		return fmt.Errorf("footer text can't be longer than %d characters", maxFooterTextLength)
+	}
+
+	if settings.NotificationEmail != "" && !isEmail(settings.NotificationEmail) {
+		return errors.New("notification email is not a valid email address")
+	}
+
+	if len(settings.RobotsTxt) > maxRobotsTxtLength {
+		return fmt.Errorf("robots.txt rules can't be longer than %d characters", maxRobotsTxtLength)
+	}
+
+	return nil
+}
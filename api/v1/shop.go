@@ -0,0 +1,482 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+const (
+	maxPrintTitleLength       = 200
+	maxPrintDescriptionLength = 2000
+	maxPrintOptionLength      = 100
+
+	// maxPrintPrice is the highest price a print can have, in the minor unit
+	// of the currency. It keeps order totals well inside what the database
+	// can hold.
+	maxPrintPrice = 10000000
+)
+
+// GetPrintProducts handles requests to get the prints for sale in the shop,
+// in display order.
+func (a API) GetPrintProducts(w http.ResponseWriter, r *http.Request) {
+	a.writePrintProducts(w, r, false)
+}
+
+// GetPrintProduct handles requests to get a print for sale in the shop by
+// its ID.
+func (a API) GetPrintProduct(w http.ResponseWriter, r *http.Request) {
+	a.writePrintProduct(w, r, false)
+}
+
+// GetAllPrintProducts handles requests to get all prints in the shop,
+// including unpublished ones.
+//
+// Requires a valid auth token.
+func (a API) GetAllPrintProducts(w http.ResponseWriter, r *http.Request) {
+	a.writePrintProducts(w, r, true)
+}
+
+// GetPrintProductByID handles requests to get a print in the shop by its ID,
+// whether or not it's published.
+//
+// Requires a valid auth token.
+func (a API) GetPrintProductByID(w http.ResponseWriter, r *http.Request) {
+	a.writePrintProduct(w, r, true)
+}
+
+// writePrintProducts sends the list of prints in the shop, including
+// unpublished ones if asked for.
+func (a API) writePrintProducts(w http.ResponseWriter, r *http.Request, unpublished bool) {
+	ret, err := a.db.GetPrintProducts(unpublished)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting print products from database: %s\n", err.Error())
+		return
+	}
+
+	if err := a.setPrintImages(r, ret); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting print images from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// writePrintProduct sends the print with the ID in the URL, as long as it's
+// published or unpublished prints are asked for.
+func (a API) writePrintProduct(w http.ResponseWriter, r *http.Request, unpublished bool) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ret, err := a.db.GetPrintProduct(uint(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "print not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting print product from database: %s\n", err.Error())
+		return
+	}
+
+	if !ret.Published && !unpublished {
+		WriteError(w, "print not found", http.StatusNotFound)
+		return
+	}
+
+	if err := a.setPrintImages(r, []*entities.PrintProduct{ret}); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting print images from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// AddPrintProduct handles requests to put a photo up for sale as a print,
+// along with the variants it's sold in.
+//
+// Requires a valid auth token.
+func (a API) AddPrintProduct(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var product entities.PrintProduct
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&product); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in add print product request: %s\n", err.Error())
+		return
+	}
+
+	product.ID = 0
+	product.Sold = 0
+	if status, err := a.validatePrintProduct(&product); err != nil {
+		WriteError(w, err.Error(), status)
+		return
+	}
+
+	for _, variant := range product.Variants {
+		if variant == nil {
+			WriteError(w, "variants can't be null", http.StatusBadRequest)
+			return
+		}
+
+		if err := validatePrintVariant(variant); err != nil {
+			WriteError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	id, err := a.db.AddPrintProduct(&product)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding print product to database: %s\n", err.Error())
+		return
+	}
+
+	ret, err := a.db.GetPrintProduct(id)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting print product from database: %s\n", err.Error())
+		return
+	}
+
+	if err := a.setPrintImages(r, []*entities.PrintProduct{ret}); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting print images from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// UpdatePrintProduct handles requests to change the details of a print in
+// the shop. Its variants are changed separately.
+//
+// Requires a valid auth token.
+func (a API) UpdatePrintProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var product entities.PrintProduct
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&product); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in print product update request: %s\n", err.Error())
+		return
+	}
+
+	existing, err := a.db.GetPrintProduct(uint(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "print not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting print product from database: %s\n", err.Error())
+		return
+	}
+
+	product.ID = existing.ID
+	product.Sold = existing.Sold
+
+	if status, err := a.validatePrintProduct(&product); err != nil {
+		WriteError(w, err.Error(), status)
+		return
+	}
+
+	if err := a.db.UpdatePrintProduct(&product); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "print not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating print product in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// RemovePrintProduct handles requests to take a print out of the shop. Orders
+// for it are kept.
+//
+// Requires a valid auth token.
+func (a API) RemovePrintProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.RemovePrintProduct(uint(id)); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error removing print product from database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// AddPrintVariant handles requests to add a size and paper that a print is
+// sold in.
+//
+// Requires a valid auth token.
+func (a API) AddPrintVariant(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var variant entities.PrintVariant
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&variant); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in add print variant request: %s\n", err.Error())
+		return
+	}
+
+	if err := validatePrintVariant(&variant); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := a.db.GetPrintProduct(uint(id)); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "print not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting print product from database: %s\n", err.Error())
+		return
+	}
+
+	variant.ProductID = uint(id)
+
+	variantID, err := a.db.AddPrintVariant(&variant)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding print variant to database: %s\n", err.Error())
+		return
+	}
+
+	variant.ID = variantID
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&variant)
+}
+
+// UpdatePrintVariant handles requests to change a size and paper that a
+// print is sold in, like its price or stock.
+//
+// Requires a valid auth token.
+func (a API) UpdatePrintVariant(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	variantID, err := strconv.ParseUint(chi.URLParam(r, "variantID"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var variant entities.PrintVariant
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&variant); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in print variant update request: %s\n", err.Error())
+		return
+	}
+
+	variant.ID = uint(variantID)
+	variant.ProductID = uint(id)
+
+	if err := validatePrintVariant(&variant); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.UpdatePrintVariant(&variant); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "print variant not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating print variant in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// RemovePrintVariant handles requests to stop selling a print in a size and
+// paper. Orders for it are kept.
+//
+// Requires a valid auth token.
+func (a API) RemovePrintVariant(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	variantID, err := strconv.ParseUint(chi.URLParam(r, "variantID"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.RemovePrintVariant(uint(id), uint(variantID)); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error removing print variant from database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// validatePrintProduct checks that a print has a title and is of a photo that
+// exists, and that a limited edition isn't smaller than the number of prints
+// already sold. An edition size of 0 makes it an open edition. It returns the
+// HTTP status code to respond with if the print isn't valid.
+func (a API) validatePrintProduct(product *entities.PrintProduct) (int, error) {
+	product.Title = strings.TrimSpace(product.Title)
+	if product.Title == "" {
+		return http.StatusBadRequest, errors.New("a print needs a title")
+	}
+
+	if len([]rune(product.Title)) > maxPrintTitleLength {
+		return http.StatusBadRequest, fmt.Errorf("title can't be longer than %d characters", maxPrintTitleLength)
+	}
+
+	product.Description = strings.TrimSpace(product.Description)
+	if len([]rune(product.Description)) > maxPrintDescriptionLength {
+		return http.StatusBadRequest, fmt.Errorf("description can't be longer than %d characters", maxPrintDescriptionLength)
+	}
+
+	product.EditionSize.Valid = product.EditionSize.Valid && product.EditionSize.Int32 != 0
+	if product.EditionSize.Valid {
+		if product.EditionSize.Int32 < 0 {
+			return http.StatusBadRequest, errors.New("edition size can't be negative")
+		}
+
+		if int(product.EditionSize.Int32) < product.Sold {
+			return http.StatusConflict, fmt.Errorf("%d prints have already been sold, so the edition can't be smaller than that", product.Sold)
+		}
+	}
+
+	photos, err := a.db.GetPhotosByID([]uint{product.PhotoID})
+	if err != nil {
+		a.log.Errorf("error getting photos from database: %s\n", err.Error())
+		return http.StatusInternalServerError, errors.New(dbError)
+	}
+
+	if len(photos) == 0 {
+		return http.StatusBadRequest, errors.New("photo not found")
+	}
+
+	return http.StatusOK, nil
+}
+
+// validatePrintVariant checks that a print variant has a size, a paper, and a
+// setPrintImages sets the public image URL of each print's photo. While
+// watermarking is on, the file names of watermarked photos are hidden from
+// everyone but admins, the same as the photography gallery.
+func (a API) setPrintImages(r *http.Request, products []*entities.PrintProduct) error {
+	hide, err := a.hidesOriginals(r)
+	if err != nil {
+		return err
+	}
+
+	files := make([]string, len(products))
+	for i, product := range products {
+		files[i] = product.Photo
+	}
+
+	images, err := a.publicImageURLs(a.siteURL(r), files)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		product.Image = images[product.Photo]
+		if hide && product.PhotoWatermark {
+			product.Photo = ""
+		}
+	}
+
+	return nil
+}
+
+// price, and that its stock isn't negative.
+func validatePrintVariant(variant *entities.PrintVariant) error {
+	variant.Size = strings.TrimSpace(variant.Size)
+	variant.Paper = strings.TrimSpace(variant.Paper)
+
+	if variant.Size == "" || variant.Paper == "" {
+		return errors.New("a print variant needs a size and a paper")
+	}
+
+	if len([]rune(variant.Size)) > maxPrintOptionLength || len([]rune(variant.Paper)) > maxPrintOptionLength {
+		return fmt.Errorf("size and paper can't be longer than %d characters", maxPrintOptionLength)
+	}
+
+	if variant.Price <= 0 || variant.Price > maxPrintPrice {
+		return fmt.Errorf("price must be between 1 and %d", maxPrintPrice)
+	}
+
+	if variant.Stock != nil && *variant.Stock < 0 {
+		return errors.New("stock can't be negative")
+	}
+
+	return nil
+}
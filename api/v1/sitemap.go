@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/sitemap"
+)
+
+// sitemapPaths are where each kind of page in the sitemap lives on the public
+// site.
+var sitemapPaths = map[string]string{
+	entities.SitemapProject: "/gallery/",
+	entities.SitemapPage:    "/pages/",
+	entities.SitemapAlbum:   "/albums/",
+	entities.SitemapPost:    "/blog/",
+}
+
+// GetSitemap handles requests for the sitemap of the public site, listing
+// the home page and every published project, page, album, and post. The
+// sitemap is cached until the list of pages changes.
+func (a API) GetSitemap(w http.ResponseWriter, r *http.Request) {
+	version, err := a.db.GetSitemapVersion()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting sitemap version from database: %s\n", err.Error())
+		return
+	}
+
+	file, err := a.sitemapFile(a.siteURL(r), version)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error writing sitemap: %s\n", err.Error())
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error getting info for sitemap: %s\n", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", sitemap.ContentType)
+	http.ServeContent(w, r, "sitemap.xml", info.ModTime(), file)
+}
+
+// GetRobots handles requests for the robots.txt file of the public site. The
+// rules come from the site settings, and point crawlers to the sitemap.
+func (a API) GetRobots(w http.ResponseWriter, r *http.Request) {
+	settings, err := a.db.GetSettings()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting settings from database: %s\n", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", sitemap.RobotsContentType)
+	w.WriteHeader(200)
+
+	io.WriteString(w, sitemap.Robots(settings.RobotsTxt, a.siteURL(r)+"/sitemap.xml"))
+}
+
+// sitemapDir is the directory that sitemaps are cached in.
+func (a API) sitemapDir() string {
+	return filepath.Join(a.cacheDir, "sitemap")
+}
+
+// sitemapFile opens the sitemap for a version of the site's pages, writing
+// it if there's no cached copy. Cached copies are named after a hash of the
+// site URL and the version, so any change to the pages makes a new sitemap.
+func (a API) sitemapFile(site, version string) (*os.File, error) {
+	sum := sha256.Sum256([]byte(site + "\n" + version))
+	name := hex.EncodeToString(sum[:8]) + ".xml"
+
+	return cachedFile(a.sitemapDir(), name, "*.xml", func(w io.Writer) error {
+		entries, err := a.db.GetSitemapEntries()
+		if err != nil {
+			return err
+		}
+
+		home := &sitemap.URL{Loc: site + "/"}
+		urls := []*sitemap.URL{home}
+		for _, entry := range entries {
+			urls = append(urls, &sitemap.URL{
+				Loc:     site + sitemapPaths[entry.Kind] + entry.Slug,
+				LastMod: entry.UpdatedAt,
+			})
+
+			// The home page shows the newest content, so it changes with it
+			if entry.UpdatedAt.After(home.LastMod) {
+				home.LastMod = entry.UpdatedAt
+			}
+		}
+
+		return sitemap.Write(w, urls)
+	})
+}
@@ -0,0 +1,220 @@
+package v1
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/database"
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+const (
+	maxTestimonialQuoteLength = 2000
+	maxTestimonialNameLength  = 100
+)
+
+// GetTestimonials handles requests to get testimonials in display order. They
+// can be narrowed down to a project with the project query parameter, and to
+// featured testimonials with the featured query parameter.
+func (a API) GetTestimonials(w http.ResponseWriter, r *http.Request) {
+	a.writeTestimonials(w, r, false)
+}
+
+// GetAllTestimonials handles requests to get testimonials, including links to
+// unpublished projects.
+//
+// Requires a valid auth token.
+func (a API) GetAllTestimonials(w http.ResponseWriter, r *http.Request) {
+	a.writeTestimonials(w, r, true)
+}
+
+// writeTestimonials sends the list of testimonials that match the query
+// parameters, including links to unpublished projects if asked for.
+func (a API) writeTestimonials(w http.ResponseWriter, r *http.Request, drafts bool) {
+	filter := database.TestimonialFilter{
+		Drafts:  drafts,
+		Project: strings.TrimSpace(r.URL.Query().Get("project")),
+	}
+
+	if value := r.URL.Query().Get("featured"); value != "" {
+		featured, err := strconv.ParseBool(value)
+		if err != nil {
+			WriteError(w, "featured must be true or false", http.StatusBadRequest)
+			return
+		}
+
+		filter.Featured = featured
+	}
+
+	ret, err := a.db.GetTestimonials(filter)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting testimonials from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(&ret)
+}
+
+// AddTestimonial handles requests to add a new testimonial.
+//
+// Requires a valid auth token.
+func (a API) AddTestimonial(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var testimonial entities.Testimonial
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&testimonial); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in add testimonial request: %s\n", err.Error())
+		return
+	}
+
+	if status, err := a.validateTestimonial(&testimonial); err != nil {
+		WriteError(w, err.Error(), status)
+		return
+	}
+
+	id, err := a.db.AddTestimonial(&testimonial)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error adding testimonial to database: %s\n", err.Error())
+		return
+	}
+
+	ret, err := a.db.GetTestimonial(id)
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting testimonial from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// UpdateTestimonial handles requests to change a testimonial.
+//
+// Requires a valid auth token.
+func (a API) UpdateTestimonial(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var testimonial entities.Testimonial
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&testimonial); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in testimonial update request: %s\n", err.Error())
+		return
+	}
+
+	testimonial.ID = uint(id)
+
+	if status, err := a.validateTestimonial(&testimonial); err != nil {
+		WriteError(w, err.Error(), status)
+		return
+	}
+
+	if err := a.db.UpdateTestimonial(&testimonial); err != nil {
+		if err == sql.ErrNoRows {
+			WriteError(w, "testimonial not found", http.StatusNotFound)
+			return
+		}
+
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating testimonial in database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// RemoveTestimonial handles requests to remove a testimonial.
+//
+// Requires a valid auth token.
+func (a API) RemoveTestimonial(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.RemoveTestimonial(uint(id)); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error removing testimonial from database: %s\n", err.Error())
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+// validateTestimonial checks that a testimonial has a quote and the name of
+// the person who gave it, and that its avatar and project exist. It returns
+// the HTTP status code to respond with if the testimonial isn't valid.
+func (a API) validateTestimonial(testimonial *entities.Testimonial) (int, error) {
+	testimonial.Quote = strings.TrimSpace(testimonial.Quote)
+	testimonial.Name = strings.TrimSpace(testimonial.Name)
+	testimonial.Role = strings.TrimSpace(testimonial.Role)
+	testimonial.Company = strings.TrimSpace(testimonial.Company)
+	testimonial.CreatedAt = time.Time{}
+
+	if testimonial.Quote == "" {
+		return http.StatusBadRequest, errors.New("a testimonial needs a quote")
+	}
+
+	if len([]rune(testimonial.Quote)) > maxTestimonialQuoteLength {
+		return http.StatusBadRequest, fmt.Errorf("quote can't be longer than %d characters", maxTestimonialQuoteLength)
+	}
+
+	if testimonial.Name == "" {
+		return http.StatusBadRequest, errors.New("a testimonial needs the name of the person who gave it")
+	}
+
+	for _, field := range []string{testimonial.Name, testimonial.Role, testimonial.Company} {
+		if len([]rune(field)) > maxTestimonialNameLength {
+			return http.StatusBadRequest, fmt.Errorf("name, role, and company can't be longer than %d characters", maxTestimonialNameLength)
+		}
+	}
+
+	testimonial.Avatar.Valid = testimonial.Avatar.Valid && testimonial.Avatar.String != ""
+	if testimonial.Avatar.Valid {
+		if err := a.checkImageFile(testimonial.Avatar.String); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("avatar: %s", err.Error())
+		}
+	}
+
+	testimonial.Project.Valid = testimonial.Project.Valid && testimonial.Project.String != ""
+	if testimonial.Project.Valid {
+		if _, err := a.db.GetProject(testimonial.Project.String); err != nil {
+			if err == sql.ErrNoRows {
+				return http.StatusBadRequest, errors.New("project not found: " + testimonial.Project.String)
+			}
+
+			a.log.Errorf("error getting project from database: %s\n", err.Error())
+			return http.StatusInternalServerError, errors.New(dbError)
+		}
+	}
+
+	return http.StatusOK, nil
+}
@@ -33,21 +33,36 @@ func (a API) Upload(w http.ResponseWriter, r *http.Request) {
 		outPath = filepath.Join(a.resourcesDir, header.Filename)
 	}
 
-	// Create our out file
-	out, err := os.Create(outPath)
-	if err != nil {
+	if err := saveFile(file, outPath); err != nil {
 		WriteError(w, err.Error(), http.StatusInternalServerError)
-		a.log.Errorf("error creating new file: %s\n", err.Error())
+		a.log.Errorf("error saving uploaded file: %s\n", err.Error())
 		return
 	}
-	defer out.Close()
 
-	// Copy the file from the requset to the out file
-	if _, err := io.Copy(out, file); err != nil {
-		WriteError(w, err.Error(), http.StatusInternalServerError)
-		a.log.Errorf("error copying to file: %s\n", err.Error())
-		return
+	w.WriteHeader(200)
+}
+
+// saveFile copies an uploaded file to the given path, replacing any file
+// that's already there. If the copy fails, the partly written file is
+// removed.
+func saveFile(src io.Reader, path string) error {
+	// Create our out file
+	out, err := os.Create(path)
+	if err != nil {
+		return err
 	}
 
-	w.WriteHeader(200)
+	// Copy the file from the request to the out file
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		os.Remove(path)
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSaveFile_FailedCopy ensures that a partly written file is removed when
+// the upload can't be read.
+func TestSaveFile_FailedCopy(t *testing.T) {
+	// Given
+	path := filepath.Join(t.TempDir(), "upload.png")
+	src := io.MultiReader(strings.NewReader("partial"), &failingReader{})
+
+	// When
+	err := saveFile(src, path)
+
+	// Then
+	if err == nil {
+		t.Fatalf("expected an error\n")
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the partial file to be removed, got: %v\n", err)
+	}
+}
+
+// failingReader is a reader that always fails.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// isHTTPURL checks if a string is an absolute HTTP or HTTPS URL.
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// trimStrings trims the whitespace from every string in a list, dropping any
+// that end up empty.
+func trimStrings(list []string) []string {
+	ret := make([]string, 0, len(list))
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s != "" {
+			ret = append(ret, s)
+		}
+	}
+
+	return ret
+}
+
+// slugPattern matches lowercase words made of letters and numbers, separated
+// by single hyphens.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// isSlug checks if a string can be used as a slug in a URL.
+func isSlug(s string) bool {
+	return slugPattern.MatchString(s)
+}
+
+// isEmail checks if a string is a bare email address, without a display name
+// or surrounding whitespace.
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// checkPatchMembers checks that a merge patch is a JSON object, and that it
+// doesn't touch any of the given members, which can't be changed by a patch.
+func checkPatchMembers(body []byte, fixed ...string) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil || members == nil {
+		return errors.New("patch must be a JSON object")
+	}
+
+	for _, name := range fixed {
+		if _, ok := members[name]; ok {
+			return fmt.Errorf("%s can't be changed by a patch", name)
+		}
+	}
+
+	return nil
+}
+
+// checkImageFile checks that a file name is a plain name, and that the file
+// is in the images directory.
+func (a API) checkImageFile(name string) error {
+	if filepath.Base(name) != name {
+		return errors.New("invalid file name")
+	}
+
+	if _, err := os.Stat(filepath.Join(a.imageDir, name)); err != nil {
+		return errors.New("file not found")
+	}
+
+	return nil
+}
+
+// siteURL gets the address of the public site, without a trailing slash. If
+// no address is set, it's worked out from the request. X-Forwarded-Proto is
+// only there if the request came from a trusted proxy, since the realip
+// middleware removes it from everything else.
+func (a API) siteURL(r *http.Request) string {
+	if a.config.SiteURL != "" {
+		return strings.TrimSuffix(a.config.SiteURL, "/")
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,303 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"image"
+	// Registered so that GIF photos can be decoded
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/db"
+	"github.com/nicolekellydesign/webby-api/internal/watermark"
+)
+
+// GetPhotoImage handles requests for the public version of a photo. If
+// watermarking is enabled and the photo hasn't opted out, a watermarked copy
+// is sent; the original file is never changed. Watermarked copies are cached
+// until the photo or the watermark settings change.
+func (a API) GetPhotoImage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	photos, err := a.db.GetPhotosByID([]uint{uint(id)})
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting photo from database: %s\n", err.Error())
+		return
+	}
+
+	if len(photos) == 0 {
+		WriteError(w, "photo not found", http.StatusNotFound)
+		return
+	}
+
+	photo := photos[0]
+	original := filepath.Join(a.imageDir, photo.Filename)
+
+	settings, err := a.db.GetWatermark()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting watermark settings from database: %s\n", err.Error())
+		return
+	}
+
+	if !settings.Enabled || !photo.Watermark {
+		http.ServeFile(w, r, original)
+		return
+	}
+
+	file, err := a.watermarkedPhoto(photo, settings)
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error watermarking photo: %s\n", err.Error())
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		WriteError(w, err.Error(), http.StatusInternalServerError)
+		a.log.Errorf("error getting info for watermarked photo: %s\n", err.Error())
+		return
+	}
+
+	// A new copy is still open under its temporary name, so the type is
+	// sniffed from the contents instead
+	http.ServeContent(w, r, "", info.ModTime(), file)
+}
+
+// hidesOriginals checks if the original file names of watermarked photos
+// should be left out of a response, so that the originals can't be fetched
+// from the images directory. They're hidden from everyone but signed-in users
+// while watermarking is turned on.
+func (a API) hidesOriginals(r *http.Request) (bool, error) {
+	settings, err := a.db.GetWatermark()
+	if err != nil {
+		return false, err
+	}
+
+	return settings.Enabled && !a.isAdmin(r), nil
+}
+
+// hideOriginals clears the file names of watermarked photos.
+func hideOriginals(photos []*entities.Photo) {
+	for _, photo := range photos {
+		if photo.Watermark {
+			photo.Filename = ""
+		}
+	}
+}
+
+// hideAlbumCover clears the file name of an album's cover photo if the cover
+// is watermarked.
+func hideAlbumCover(album *entities.Album) {
+	if album.CoverWatermark {
+		album.CoverPhoto = db.NullString{}
+	}
+}
+
+// publicImageURLs works out the public URL of each of the given image files
+// on a site. Files are normally linked in the images directory, but while
+// watermarking is on, watermarked photos are linked through GetPhotoImage so
+// that the originals aren't given away.
+func (a API) publicImageURLs(site string, files []string) (map[string]string, error) {
+	ret := make(map[string]string, len(files))
+	for _, file := range files {
+		ret[file] = site + "/images/" + file
+	}
+
+	settings, err := a.db.GetWatermark()
+	if err != nil {
+		return nil, err
+	}
+
+	if !settings.Enabled {
+		return ret, nil
+	}
+
+	photos, err := a.db.GetPhotosByFileName(files)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, photo := range photos {
+		if photo.Watermark {
+			ret[photo.Filename] = site + "/api/v1/photos/" + strconv.FormatUint(uint64(photo.ID), 10) + "/image"
+		}
+	}
+
+	return ret, nil
+}
+
+// GetWatermark handles requests to get the watermark settings.
+//
+// Requires a valid auth token.
+func (a API) GetWatermark(w http.ResponseWriter, r *http.Request) {
+	ret, err := a.db.GetWatermark()
+	if err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error getting watermark settings from database: %s\n", err.Error())
+		return
+	}
+
+	// Send back the response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(200)
+
+	encoder := json.NewEncoder(w)
+	encoder.Encode(ret)
+}
+
+// UpdateWatermark handles requests to change the watermark settings. Any
+// cached watermarked photos are removed so they're made again with the new
+// settings.
+//
+// Requires a valid auth token.
+func (a API) UpdateWatermark(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var settings entities.Watermark
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&settings); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		a.log.Errorf("error decoding JSON body in watermark update request: %s\n", err.Error())
+		return
+	}
+
+	if err := a.validateWatermark(&settings); err != nil {
+		WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.db.UpdateWatermark(&settings); err != nil {
+		WriteError(w, dbError, http.StatusInternalServerError)
+		a.log.Errorf("error updating watermark settings in database: %s\n", err.Error())
+		return
+	}
+
+	if err := os.RemoveAll(a.watermarkDir()); err != nil {
+		a.log.Warnf("unable to clear watermarked photos: %s\n", err.Error())
+	}
+
+	w.WriteHeader(200)
+}
+
+// validateWatermark checks that watermark settings are usable, filling in
+// defaults for the position, opacity, and scale if they aren't set.
+func (a API) validateWatermark(settings *entities.Watermark) error {
+	settings.Text = strings.TrimSpace(settings.Text)
+	settings.Image.String = strings.TrimSpace(settings.Image.String)
+	settings.Image.Valid = settings.Image.String != ""
+
+	if settings.Position == "" {
+		settings.Position = watermark.BottomRight
+	}
+
+	if !watermark.ValidPosition(settings.Position) {
+		return errors.New("position must be one of top-left, top-right, bottom-left, bottom-right, or center")
+	}
+
+	if settings.Opacity <= 0 || settings.Opacity > 1 {
+		return errors.New("opacity must be greater than 0 and at most 1")
+	}
+
+	if settings.Scale <= 0 || settings.Scale > 1 {
+		return errors.New("scale must be greater than 0 and at most 1")
+	}
+
+	if settings.Enabled && !settings.Image.Valid && settings.Text == "" {
+		return errors.New("a watermark needs an image or text")
+	}
+
+	if settings.Image.Valid {
+		if _, err := os.Stat(filepath.Join(a.imageDir, settings.Image.String)); err != nil {
+			return errors.New("watermark image does not exist")
+		}
+	}
+
+	return nil
+}
+
+// watermarkDir is the directory that watermarked photos are cached in.
+func (a API) watermarkDir() string {
+	return filepath.Join(a.cacheDir, "watermarked")
+}
+
+// watermarkedPhoto opens a watermarked copy of a photo, making it if there's
+// no cached copy. Cached copies are named after the photo's ID and a hash of
+// when the photo and the watermark settings last changed, so changing either
+// makes a new copy.
+func (a API) watermarkedPhoto(photo *entities.Photo, settings *entities.Watermark) (*os.File, error) {
+	original := filepath.Join(a.imageDir, photo.Filename)
+	originalInfo, err := os.Stat(original)
+	if err != nil {
+		return nil, err
+	}
+
+	id := strconv.FormatUint(uint64(photo.ID), 10)
+	sum := sha256.Sum256([]byte(originalInfo.ModTime().String() + "\n" + settings.UpdatedAt.String()))
+	name := id + "-" + hex.EncodeToString(sum[:8])
+
+	// JPEGs stay JPEGs, and everything else is saved as a PNG
+	ext := strings.ToLower(filepath.Ext(photo.Filename))
+	if ext == ".jpg" || ext == ".jpeg" {
+		name += ".jpg"
+	} else {
+		name += ".png"
+	}
+
+	return cachedFile(a.watermarkDir(), name, id+"-*", func(w io.Writer) error {
+		img, err := decodeImage(original)
+		if err != nil {
+			return err
+		}
+
+		var mark image.Image
+		if settings.Image.Valid {
+			if mark, err = decodeImage(filepath.Join(a.imageDir, settings.Image.String)); err != nil {
+				return err
+			}
+		} else {
+			mark = watermark.Text(settings.Text)
+		}
+
+		marked := watermark.Apply(img, mark, watermark.Options{
+			Position: settings.Position,
+			Opacity:  settings.Opacity,
+			Scale:    settings.Scale,
+		})
+
+		if filepath.Ext(name) == ".jpg" {
+			return jpeg.Encode(w, marked, &jpeg.Options{Quality: 90})
+		}
+
+		return png.Encode(w, marked)
+	})
+}
+
+// decodeImage opens and decodes the image file at the given path.
+func decodeImage(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	return img, err
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/DataDrake/cli-ng/v2/cmd"
 	"github.com/nicolekellydesign/webby-api/database"
+	"github.com/nicolekellydesign/webby-api/internal/signing"
 	"github.com/nicolekellydesign/webby-api/server"
 )
 
@@ -74,11 +75,24 @@ func ServeFunc(root *cmd.Root, c *cmd.Sub) {
 		log.Fatalf("Unable to connect to the database: %s\n", err)
 	}
 
+	// Without a signing key, use a random one. Download links then stop
+	// working when the server restarts. Email needs a key of its own, so
+	// this only happens when it isn't set up.
+	if len(apiConfig.SigningKey) == 0 {
+		key, err := signing.NewKey()
+		if err != nil {
+			log.Fatalf("Unable to generate a signing key: %s\n", err)
+		}
+
+		log.Warnf("'%s' is not set, download links will not work after a restart\n", envSigningKey)
+		apiConfig.SigningKey = key
+	}
+
 	errs := make(chan error, 1)
 
 	// Start our API endpoint listener
 	log.Infoln("Starting the API endpoint listener")
-	server := server.New(5000, db, log, rootDir, errs)
+	server := server.New(5000, db, log, rootDir, apiConfig, errs)
 
 	go server.Serve()
 	log.Infoln("Now listening on 'localhost:5000'")
@@ -2,12 +2,20 @@ package main
 
 import (
 	log2 "log"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/DataDrake/cli-ng/v2/cmd"
 	"github.com/DataDrake/waterlog"
 	"github.com/DataDrake/waterlog/format"
 	"github.com/DataDrake/waterlog/level"
+	v1 "github.com/nicolekellydesign/webby-api/api/v1"
+	"github.com/nicolekellydesign/webby-api/internal/mailer"
+	"github.com/nicolekellydesign/webby-api/internal/payment"
+	"github.com/nicolekellydesign/webby-api/internal/realip"
 )
 
 const (
@@ -15,6 +23,21 @@ const (
 	envPasswordKey = "WEBBY_DB_PASSWORD"
 	envNameKey     = "WEBBY_DB_NAME"
 	envRootKey     = "WEBBY_ROOT"
+
+	envRejectDuplicatesKey = "WEBBY_REJECT_DUPLICATES"
+	envSigningKey          = "WEBBY_SIGNING_KEY"
+	envSiteURLKey          = "WEBBY_SITE_URL"
+
+	envSMTPHostKey     = "WEBBY_SMTP_HOST"
+	envSMTPPortKey     = "WEBBY_SMTP_PORT"
+	envSMTPUsernameKey = "WEBBY_SMTP_USERNAME"
+	envSMTPPasswordKey = "WEBBY_SMTP_PASSWORD"
+	envSMTPFromKey     = "WEBBY_SMTP_FROM"
+
+	envPaymentProviderKey = "WEBBY_PAYMENT_PROVIDER"
+	envShopCurrencyKey    = "WEBBY_SHOP_CURRENCY"
+
+	envTrustedProxiesKey = "WEBBY_TRUSTED_PROXIES"
 )
 
 var (
@@ -23,6 +46,8 @@ var (
 	dbName     string
 	rootDir    string
 
+	apiConfig v1.Config
+
 	log *waterlog.WaterLog
 )
 
@@ -64,6 +89,95 @@ func init() {
 	if !found {
 		log.Fatalf("required environment variable '%s' not set\n", envRootKey)
 	}
+
+	// Optional settings
+	if value, found := os.LookupEnv(envRejectDuplicatesKey); found {
+		reject, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Fatalf("environment variable '%s' must be true or false\n", envRejectDuplicatesKey)
+		}
+
+		apiConfig.RejectDuplicates = reject
+	}
+
+	if value, found := os.LookupEnv(envSigningKey); found && value != "" {
+		apiConfig.SigningKey = []byte(value)
+	}
+
+	if value, found := os.LookupEnv(envSiteURLKey); found && value != "" {
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			log.Fatalf("environment variable '%s' must be an absolute http or https URL\n", envSiteURLKey)
+		}
+
+		apiConfig.SiteURL = value
+	}
+
+	if value, found := os.LookupEnv(envSMTPHostKey); found && value != "" {
+		smtpConfig := mailer.Config{
+			Host:     value,
+			Port:     587,
+			Username: os.Getenv(envSMTPUsernameKey),
+			Password: os.Getenv(envSMTPPasswordKey),
+			From:     os.Getenv(envSMTPFromKey),
+		}
+
+		if value, found := os.LookupEnv(envSMTPPortKey); found && value != "" {
+			port, err := strconv.Atoi(value)
+			if err != nil || port < 1 || port > 65535 {
+				log.Fatalf("environment variable '%s' must be a port number\n", envSMTPPortKey)
+			}
+
+			smtpConfig.Port = port
+		}
+
+		m, err := mailer.New(smtpConfig)
+		if err != nil {
+			log.Fatalf("unable to set up email with environment variable '%s': %s\n", envSMTPFromKey, err)
+		}
+
+		// Links in email can't come from the request, since anyone can set
+		// its host to a site of their own
+		if apiConfig.SiteURL == "" {
+			log.Fatalf("environment variable '%s' is required when '%s' is set\n", envSiteURLKey, envSMTPHostKey)
+		}
+
+		// Links in email are signed, and have to keep working for as long as
+		// someone might open the email, so a random key won't do
+		if len(apiConfig.SigningKey) == 0 {
+			log.Fatalf("environment variable '%s' is required when '%s' is set\n", envSigningKey, envSMTPHostKey)
+		}
+
+		apiConfig.Mailer = m
+	}
+
+	if value, found := os.LookupEnv(envPaymentProviderKey); found && value != "" {
+		switch value {
+		case "fake":
+			log.Warnf("using the fake payment provider, so orders are paid for without taking any money\n")
+			apiConfig.Payments = payment.NewFake()
+		default:
+			log.Fatalf("environment variable '%s' must be one of: fake\n", envPaymentProviderKey)
+		}
+	}
+
+	if value, found := os.LookupEnv(envShopCurrencyKey); found && value != "" {
+		value = strings.ToUpper(value)
+		if !regexp.MustCompile(`^[A-Z]{3}$`).MatchString(value) {
+			log.Fatalf("environment variable '%s' must be a three letter currency code\n", envShopCurrencyKey)
+		}
+
+		apiConfig.Currency = value
+	}
+
+	if value, found := os.LookupEnv(envTrustedProxiesKey); found && value != "" {
+		trusted, err := realip.ParseTrusted(value)
+		if err != nil {
+			log.Fatalf("environment variable '%s' must be a comma separated list of IP addresses or CIDR ranges: %s\n", envTrustedProxiesKey, err)
+		}
+
+		apiConfig.TrustedProxies = trusted
+	}
 }
 
 func main() {
@@ -0,0 +1,227 @@
+package database
+
+import (
+	"encoding/json"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/patch"
+)
+
+// aboutColumns are the columns of the about page info that are stored in the
+// about table itself.
+const aboutColumns = "portrait, statement, resume, contact_email, location"
+
+// GetAbout fetches the about page info from the database, along with its
+// sections, social links, clients, and awards.
+func (db DB) GetAbout() (*entities.About, error) {
+	var ret entities.About
+	if err := db.db.Get(&ret, "SELECT "+aboutColumns+" FROM about WHERE id = 1;"); err != nil {
+		return nil, err
+	}
+
+	ret.Sections = make([]*entities.AboutSection, 0)
+	if err := db.db.Select(&ret.Sections, "SELECT title, body FROM about_sections ORDER BY position, id;"); err != nil {
+		return nil, err
+	}
+
+	ret.SocialLinks = make([]*entities.SocialLink, 0)
+	if err := db.db.Select(&ret.SocialLinks, "SELECT platform, url, label FROM social_links ORDER BY position, id;"); err != nil {
+		return nil, err
+	}
+
+	ret.Clients = make([]*entities.AboutClient, 0)
+	if err := db.db.Select(&ret.Clients, "SELECT name, url FROM about_clients ORDER BY position, id;"); err != nil {
+		return nil, err
+	}
+
+	ret.Awards = make([]*entities.Award, 0)
+	if err := db.db.Select(&ret.Awards, "SELECT title, issuer, year, url, description FROM awards ORDER BY position, id;"); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// PatchAbout applies a JSON Merge Patch to the about page info. The row is
+// locked while the patch is applied, so concurrent edits can't overwrite each
+// other. Only the info in the about table itself is changed; the lists on the
+// about page have their own setters.
+func (db DB) PatchAbout(body []byte) error {
+	tx := db.db.MustBegin()
+
+	var current entities.About
+	if err := tx.Get(&current, "SELECT "+aboutColumns+" FROM about WHERE id = 1 FOR UPDATE;"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	doc, err := json.Marshal(&current)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	merged, err := patch.Merge(doc, body)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var ret entities.About
+	if err := json.Unmarshal(merged, &ret); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query := `
+	UPDATE
+		about
+	SET
+		portrait = $1,
+		statement = $2,
+		resume = $3,
+		contact_email = $4,
+		location = $5,
+		updated_at = NOW()
+	WHERE
+		id = 1;
+	`
+
+	if _, err := tx.Exec(query, ret.Portrait, ret.Statement, ret.Resume, ret.ContactEmail, ret.Location); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// ImportAbout saves about page info from before it was stored in the
+// database. The info is only saved if the about page has never been edited,
+// so importing more than once is safe. It returns whether the info was saved.
+func (db DB) ImportAbout(about *entities.About) (bool, error) {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		about
+	SET
+		portrait = $1,
+		statement = $2,
+		resume = $3,
+		updated_at = NOW()
+	WHERE
+		id = 1 AND updated_at IS NULL;
+	`
+
+	res, err := tx.Exec(query, about.Portrait, about.Statement, about.Resume)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// SetAboutSections replaces the sections of the about page. The sections are
+// shown in the order they're given.
+func (db DB) SetAboutSections(sections []*entities.AboutSection) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM about_sections;")
+
+	query := "INSERT INTO about_sections (title, body, position) VALUES ($1, $2, $3);"
+	for i, section := range sections {
+		if _, err := tx.Exec(query, section.Title, section.Body, i); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// SetSocialLinks replaces the social links on the about page. The links are
+// shown in the order they're given.
+func (db DB) SetSocialLinks(links []*entities.SocialLink) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM social_links;")
+
+	query := "INSERT INTO social_links (platform, url, label, position) VALUES ($1, $2, $3, $4);"
+	for i, link := range links {
+		if _, err := tx.Exec(query, link.Platform, link.URL, link.Label, i); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// SetAboutClients replaces the selected clients on the about page. The
+// clients are shown in the order they're given.
+func (db DB) SetAboutClients(clients []*entities.AboutClient) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM about_clients;")
+
+	query := "INSERT INTO about_clients (name, url, position) VALUES ($1, $2, $3);"
+	for i, client := range clients {
+		if _, err := tx.Exec(query, client.Name, client.URL, i); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// SetAwards replaces the awards on the about page. The awards are shown in
+// the order they're given.
+func (db DB) SetAwards(awards []*entities.Award) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM awards;")
+
+	query := "INSERT INTO awards (title, issuer, year, url, description, position) VALUES ($1, $2, $3, $4, $5, $6);"
+	for i, award := range awards {
+		if _, err := tx.Exec(query, award.Title, award.Issuer, award.Year, award.URL, award.Description, i); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// albumQuery selects albums along with their cover photo and the number of
+// photos in them. If an album has no cover photo set, the first photo in the
+// album is used.
+const albumQuery = `
+	SELECT
+		albums.id, albums.slug, albums.title, albums.description, albums.cover_photo_id, albums.position,
+		cover.id AS cover_image_id, cover.file_name AS cover_photo, COALESCE(cover.watermark, FALSE) AS cover_watermark,
+		(SELECT COUNT(*) FROM album_photos WHERE album_photos.album_id = albums.id) AS photo_count
+	FROM albums
+	LEFT JOIN photos AS cover ON cover.id = COALESCE(albums.cover_photo_id, (
+		SELECT album_photos.photo_id FROM album_photos
+		WHERE album_photos.album_id = albums.id
+		ORDER BY album_photos.position
+		LIMIT 1
+	))
+`
+
+// AddAlbum inserts a new photo album into the database, returning the new
+// album's ID.
+func (db DB) AddAlbum(album *entities.Album) (uint, error) {
+	tx := db.db.MustBegin()
+
+	query := `INSERT INTO albums (
+		slug,
+		title,
+		description,
+		cover_photo_id,
+		position
+	) VALUES ($1, $2, $3, $4, $5) RETURNING id;`
+
+	var id uint
+	if err := tx.QueryRowx(query, album.Slug, album.Title, album.Description, album.CoverPhotoID, album.Position).Scan(&id); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetAlbums fetches all photo albums from the database in display order.
+func (db DB) GetAlbums() ([]*entities.Album, error) {
+	ret := make([]*entities.Album, 0)
+	if err := db.db.Select(&ret, albumQuery+"ORDER BY albums.position, albums.id;"); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetAlbum fetches a photo album with the given slug from the database, along
+// with its photos in display order.
+func (db DB) GetAlbum(slug string) (*entities.Album, error) {
+	var album entities.Album
+	if err := db.db.Get(&album, albumQuery+"WHERE albums.slug = $1;", slug); err != nil {
+		return nil, err
+	}
+
+	photos := make([]*entities.Photo, 0)
+
+	query := `
+		SELECT ` + photoColumns + `
+		FROM album_photos
+		JOIN photos ON photos.id = album_photos.photo_id
+		WHERE album_photos.album_id = $1
+		ORDER BY album_photos.position;
+	`
+
+	if err := db.db.Select(&photos, query, album.ID); err != nil {
+		return nil, err
+	}
+
+	album.Photos = photos
+	return &album, nil
+}
+
+// UpdateAlbum sets the slug, title, description, cover photo, and position of
+// an existing album. If there is no album with the ID, sql.ErrNoRows is
+// returned.
+func (db DB) UpdateAlbum(album *entities.Album) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		albums
+	SET
+		slug = $1,
+		title = $2,
+		description = $3,
+		cover_photo_id = $4,
+		position = $5,
+		updated_at = NOW()
+	WHERE
+		id = $6;
+	`
+
+	res, err := tx.Exec(query, album.Slug, album.Title, album.Description, album.CoverPhotoID, album.Position, album.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// RemoveAlbum deletes a photo album from the database. The photos in it are
+// not removed.
+func (db DB) RemoveAlbum(id uint) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM albums WHERE id=$1;", id)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// SetAlbumPhotos replaces the photos in an album. The photos are displayed in
+// the order they are given. If there is no album with the ID, sql.ErrNoRows is
+// returned.
+func (db DB) SetAlbumPhotos(albumID uint, photoIDs []uint) error {
+	tx := db.db.MustBegin()
+
+	var exists bool
+	if err := tx.Get(&exists, "SELECT EXISTS (SELECT 1 FROM albums WHERE id=$1);", albumID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if !exists {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	tx.MustExec("DELETE FROM album_photos WHERE album_id=$1;", albumID)
+	tx.MustExec("UPDATE albums SET updated_at=NOW() WHERE id=$1;", albumID)
+
+	query := "INSERT INTO album_photos (album_id, photo_id, position) VALUES ($1, $2, $3);"
+	for i, photoID := range photoIDs {
+		if _, err := tx.Exec(query, albumID, photoID, i); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// contactMessageColumns are the columns selected for contact messages.
+const contactMessageColumns = "id, name, email, subject, message, read, archived, created_at"
+
+// AddContactMessage inserts a new message from the contact form into the
+// database, returning the new message's ID.
+func (db DB) AddContactMessage(message *entities.ContactMessage) (uint, error) {
+	tx := db.db.MustBegin()
+
+	query := `INSERT INTO contact_messages (
+		name,
+		email,
+		subject,
+		message
+	) VALUES ($1, $2, $3, $4) RETURNING id;`
+
+	var id uint
+	if err := tx.QueryRowx(query, message.Name, message.Email, message.Subject, message.Message).Scan(&id); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetContactMessages fetches contact messages from the database, newest
+// first. Either the archived messages or the ones in the inbox are fetched.
+func (db DB) GetContactMessages(archived bool) ([]*entities.ContactMessage, error) {
+	ret := make([]*entities.ContactMessage, 0)
+
+	query := "SELECT " + contactMessageColumns + " FROM contact_messages WHERE archived = $1 ORDER BY created_at DESC, id DESC;"
+	if err := db.db.Select(&ret, query, archived); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetContactMessage fetches the contact message with the given ID from the
+// database.
+func (db DB) GetContactMessage(id uint) (*entities.ContactMessage, error) {
+	var ret entities.ContactMessage
+	if err := db.db.Get(&ret, "SELECT "+contactMessageColumns+" FROM contact_messages WHERE id = $1;", id); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// SetContactMessageRead marks a contact message as read or unread.
+func (db DB) SetContactMessageRead(id uint, read bool) error {
+	return db.setContactMessageFlag(id, "read", read)
+}
+
+// SetContactMessageArchived moves a contact message to or from the archive.
+func (db DB) SetContactMessageArchived(id uint, archived bool) error {
+	return db.setContactMessageFlag(id, "archived", archived)
+}
+
+// setContactMessageFlag sets one of the flag columns of a contact message.
+func (db DB) setContactMessageFlag(id uint, column string, value bool) error {
+	tx := db.db.MustBegin()
+	res := tx.MustExec("UPDATE contact_messages SET "+column+" = $1 WHERE id = $2;", value, id)
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// RemoveContactMessage deletes a contact message from the database.
+func (db DB) RemoveContactMessage(id uint) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM contact_messages WHERE id = $1;", id)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
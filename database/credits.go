@@ -0,0 +1,126 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// AddContributor inserts a new contributor into the database, returning the
+// new contributor's ID.
+func (db DB) AddContributor(contributor *entities.Contributor) (uint, error) {
+	tx := db.db.MustBegin()
+
+	query := "INSERT INTO contributors (name, kind, url) VALUES ($1, $2, $3) RETURNING id;"
+
+	var id uint
+	if err := tx.QueryRowx(query, contributor.Name, contributor.Kind, contributor.URL).Scan(&id); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetContributors fetches all contributors from the database, sorted by name.
+func (db DB) GetContributors() ([]*entities.Contributor, error) {
+	ret := make([]*entities.Contributor, 0)
+	if err := db.db.Select(&ret, "SELECT id, name, kind, url FROM contributors ORDER BY name, id;"); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// UpdateContributor sets the name, kind, and URL of an existing contributor.
+// If there is no contributor with the ID, sql.ErrNoRows is returned.
+func (db DB) UpdateContributor(contributor *entities.Contributor) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		contributors
+	SET
+		name = $1,
+		kind = $2,
+		url = $3
+	WHERE
+		id = $4;
+	`
+
+	res := tx.MustExec(query, contributor.Name, contributor.Kind, contributor.URL, contributor.ID)
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// RemoveContributor deletes a contributor from the database, along with all
+// of their credits.
+func (db DB) RemoveContributor(id uint) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM contributors WHERE id=$1;", id)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// GetProjectCredits fetches the credits for a project in display order.
+func (db DB) GetProjectCredits(galleryID string) ([]*entities.Credit, error) {
+	ret := make([]*entities.Credit, 0)
+
+	query := `
+		SELECT
+			contributors.id AS contributor_id, contributors.name, contributors.kind, contributors.url,
+			project_credits.role
+		FROM project_credits
+		JOIN contributors ON contributors.id = project_credits.contributor_id
+		WHERE project_credits.gallery_id = $1
+		ORDER BY project_credits.position, project_credits.id;
+	`
+
+	if err := db.db.Select(&ret, query, galleryID); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// SetProjectCredits replaces all of the credits for a project. The credits are
+// displayed in the order they are given.
+func (db DB) SetProjectCredits(galleryID string, credits []*entities.Credit) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM project_credits WHERE gallery_id=$1;", galleryID)
+	tx.MustExec("UPDATE gallery_items SET updated_at=NOW() WHERE id=$1;", galleryID)
+
+	query := "INSERT INTO project_credits (gallery_id, contributor_id, role, position) VALUES ($1, $2, $3, $4);"
+	for i, credit := range credits {
+		if _, err := tx.Exec(query, galleryID, credit.ContributorID, credit.Role, i); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// recorder is a minimal database/sql driver that supports transactions and
+// records the arguments of every statement it's sent.
+type recorder struct {
+	args [][]driver.Value
+}
+
+// Connect implements driver.Connector.
+func (f *recorder) Connect(context.Context) (driver.Conn, error) {
+	return &recorderConn{f}, nil
+}
+
+// Driver implements driver.Connector.
+func (f *recorder) Driver() driver.Driver {
+	return nil
+}
+
+type recorderConn struct {
+	f *recorder
+}
+
+func (c *recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *recorderConn) Close() error {
+	return nil
+}
+
+func (c *recorderConn) Begin() (driver.Tx, error) {
+	return recorderTx{}, nil
+}
+
+// record saves the values of a statement's arguments.
+func (c *recorderConn) record(args []driver.NamedValue) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	c.f.args = append(c.f.args, values)
+}
+
+// QueryContext implements driver.QueryerContext. Every query returns a
+// single row with an ID of 1.
+func (c *recorderConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(args)
+	return &fakeRows{columns: []string{"id"}, values: [][]driver.Value{{int64(1)}}}, nil
+}
+
+// ExecContext implements driver.ExecerContext. Every statement affects a
+// single row.
+func (c *recorderConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(args)
+	return driver.RowsAffected(1), nil
+}
+
+type recorderTx struct{}
+
+func (recorderTx) Commit() error {
+	return nil
+}
+
+func (recorderTx) Rollback() error {
+	return nil
+}
+
+// TestContributorURL_Null ensures that contributors without a URL are stored
+// with a NULL URL rather than an empty string.
+func TestContributorURL_Null(t *testing.T) {
+	tests := []struct {
+		name     string
+		save     func(DB, *entities.Contributor) error
+		url      db.NullString
+		expected driver.Value
+	}{
+		{"add without URL", addContributor, db.NullString{}, nil},
+		{"add with URL", addContributor, db.NullString{String: "https://example.com", Valid: true}, "https://example.com"},
+		{"update without URL", DB.UpdateContributor, db.NullString{}, nil},
+		{"update with URL", DB.UpdateContributor, db.NullString{String: "https://example.com", Valid: true}, "https://example.com"},
+	}
+
+	for _, test := range tests {
+		// Given
+		f := &recorder{}
+		database := DB{sqlx.NewDb(sql.OpenDB(f), "pgx")}
+		contributor := &entities.Contributor{ID: 1, Name: "Ada", Kind: entities.ContributorPerson, URL: test.url}
+
+		// When
+		err := test.save(database, contributor)
+
+		// Then
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s\n", test.name, err)
+		}
+
+		if len(f.args) != 1 {
+			t.Fatalf("%s: wrong number of statements: got %d, expected: 1\n", test.name, len(f.args))
+		}
+
+		if url := f.args[0][2]; url != test.expected {
+			t.Fatalf("%s: stored URL does not match expected: got %#v, expected: %#v\n", test.name, url, test.expected)
+		}
+	}
+}
+
+// addContributor adds a contributor, dropping the new ID.
+func addContributor(database DB, contributor *entities.Contributor) error {
+	_, err := database.AddContributor(contributor)
+	return err
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// GetCV fetches the CV from the database. Lists that have nothing in them are
+// empty instead of nil.
+func (db DB) GetCV() (*entities.CV, error) {
+	var row struct {
+		Data      []byte    `db:"data"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+
+	if err := db.db.Get(&row, "SELECT data, updated_at FROM cv WHERE id = 1;"); err != nil {
+		return nil, err
+	}
+
+	var ret entities.CV
+	if err := json.Unmarshal(row.Data, &ret); err != nil {
+		return nil, err
+	}
+
+	ret.UpdatedAt = row.UpdatedAt
+
+	if ret.Experience == nil {
+		ret.Experience = make([]*entities.CVExperience, 0)
+	}
+
+	if ret.Education == nil {
+		ret.Education = make([]*entities.CVEducation, 0)
+	}
+
+	if ret.Skills == nil {
+		ret.Skills = make([]*entities.CVSkill, 0)
+	}
+
+	if ret.Exhibitions == nil {
+		ret.Exhibitions = make([]*entities.CVExhibition, 0)
+	}
+
+	if ret.Publications == nil {
+		ret.Publications = make([]*entities.CVPublication, 0)
+	}
+
+	return &ret, nil
+}
+
+// UpdateCV replaces the CV in the database.
+func (db DB) UpdateCV(cv *entities.CV) error {
+	data, err := json.Marshal(cv)
+	if err != nil {
+		return err
+	}
+
+	tx := db.db.MustBegin()
+	tx.MustExec("UPDATE cv SET data = $1, updated_at = NOW() WHERE id = 1;", data)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -3,6 +3,8 @@ package database
 import (
 	"database/sql"
 	"embed"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -16,6 +18,7 @@ import (
 	_ "github.com/jackc/pgx/v4/stdlib"
 	"github.com/jmoiron/sqlx"
 	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/patch"
 )
 
 // DB holds our database connection.
@@ -85,16 +88,120 @@ func (db DB) AddPhotos(files []string) error {
 	return nil
 }
 
+// photoColumns are the photos columns that make up a Photo.
+const photoColumns = `
+	photos.id,
+	photos.file_name,
+	photos.title,
+	photos.caption,
+	photos.alt_text,
+	photos.location,
+	photos.taken_at,
+	photos.watermark`
+
 // GetPhotos fetches all photos from the database.
 func (db DB) GetPhotos() ([]*entities.Photo, error) {
 	ret := make([]*entities.Photo, 0)
-	if err := db.db.Select(&ret, "SELECT file_name FROM photos;"); err != nil {
+	if err := db.db.Select(&ret, "SELECT "+photoColumns+" FROM photos ORDER BY photos.id;"); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetPhotosByID fetches the photos with the given IDs from the database.
+// IDs that don't match a photo are skipped.
+func (db DB) GetPhotosByID(ids []uint) ([]*entities.Photo, error) {
+	ret := make([]*entities.Photo, 0)
+	if len(ids) == 0 {
+		return ret, nil
+	}
+
+	query, args, err := sqlx.In("SELECT "+photoColumns+" FROM photos WHERE photos.id IN (?) ORDER BY photos.id;", ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.db.Select(&ret, db.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// CountPhotos returns how many of the given photo IDs belong to a photo.
+func (db DB) CountPhotos(ids []uint) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query, args, err := sqlx.In("SELECT COUNT(*) FROM photos WHERE id IN (?);", ids)
+	if err != nil {
+		return 0, err
+	}
+
+	var ret int
+	if err := db.db.Get(&ret, db.db.Rebind(query), args...); err != nil {
+		return 0, err
+	}
+
+	return ret, nil
+}
+
+// GetPhotosByFileName fetches the photos with the given file names. File
+// names that don't belong to a photo are skipped.
+func (db DB) GetPhotosByFileName(files []string) ([]*entities.Photo, error) {
+	ret := make([]*entities.Photo, 0)
+	if len(files) == 0 {
+		return ret, nil
+	}
+
+	query, args, err := sqlx.In("SELECT "+photoColumns+" FROM photos WHERE photos.file_name IN (?) ORDER BY photos.id;", files)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.db.Select(&ret, db.db.Rebind(query), args...); err != nil {
 		return nil, err
 	}
 
 	return ret, nil
 }
 
+// UpdatePhotos sets the title, caption, alt text, location, taken date, and
+// watermark flag for each of the given photos in a single transaction.
+func (db DB) UpdatePhotos(photos []*entities.Photo) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		photos
+	SET
+		title = $1,
+		caption = $2,
+		alt_text = $3,
+		location = $4,
+		taken_at = $5,
+		watermark = $6
+	WHERE
+		id = $7;
+	`
+
+	for _, photo := range photos {
+		if _, err := tx.Exec(query, photo.Title, photo.Caption, photo.AltText, photo.Location, photo.TakenAt, photo.Watermark, photo.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
 // RemovePhotos removes photos in the list of files from the
 // database.
 func (db DB) RemovePhotos(files []string) error {
@@ -125,7 +232,13 @@ func (db DB) RemovePhotos(files []string) error {
 	return nil
 }
 
-// AddGalleryItem adds a new gallery item to the database.
+// ErrProjectExists is returned when a project is added with a name that's
+// already taken.
+var ErrProjectExists = errors.New("a project with that name already exists")
+
+// AddGalleryItem adds a new gallery item to the database, along with any
+// images and credits it has. If the name is already taken, ErrProjectExists
+// is returned.
 func (db DB) AddGalleryItem(item entities.GalleryItem) error {
 	tx := db.db.MustBegin()
 
@@ -135,10 +248,50 @@ func (db DB) AddGalleryItem(item entities.GalleryItem) error {
 		caption,
 		project_info,
 		thumbnail,
-		video_key
-	) VALUES ($1, $2, $3, $4, $5, $6);`
+		video_key,
+		published,
+		year_start,
+		year_end,
+		client,
+		services,
+		links,
+		live_url,
+		meta_title,
+		meta_description,
+		share_image,
+		canonical_url,
+		no_index
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+	ON CONFLICT (id) DO NOTHING;`
+
+	res, err := tx.Exec(sql, item.Name, item.Title, item.Caption, item.ProjectInfo, item.Thumbnail, item.VideoKey.String, item.Published,
+		item.YearStart, item.YearEnd, item.Client, item.Services, item.Links, item.LiveURL, item.MetaTitle, item.MetaDescription,
+		item.ShareImage, item.CanonicalURL, item.NoIndex)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if added, err := res.RowsAffected(); err != nil || added == 0 {
+		tx.Rollback()
+		if err != nil {
+			return err
+		}
 
-	tx.MustExec(sql, item.Name, item.Title, item.Caption, item.ProjectInfo, item.Thumbnail, item.VideoKey.String)
+		return ErrProjectExists
+	}
+
+	for _, file := range item.Images {
+		tx.MustExec("INSERT INTO project_images (gallery_id, file_name) VALUES ($1, $2);", item.Name, file)
+	}
+
+	query := "INSERT INTO project_credits (gallery_id, contributor_id, role, position) VALUES ($1, $2, $3, $4);"
+	for i, credit := range item.Credits {
+		if _, err := tx.Exec(query, item.Name, credit.ContributorID, credit.Role, i); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
 
 	if err := tx.Commit(); err != nil {
 		tx.Rollback()
@@ -151,7 +304,7 @@ func (db DB) AddGalleryItem(item entities.GalleryItem) error {
 // ChangeProjectThumbnail sets a new thumbnail for a project.
 func (db DB) ChangeProjectThumbnail(name, newThumb string) error {
 	tx := db.db.MustBegin()
-	tx.MustExec("UPDATE gallery_items SET thumbnail=$1 WHERE id=$2;", newThumb, name)
+	tx.MustExec("UPDATE gallery_items SET thumbnail=$1, updated_at=NOW() WHERE id=$2;", newThumb, name)
 
 	if err := tx.Commit(); err != nil {
 		tx.Rollback()
@@ -165,29 +318,117 @@ func (db DB) ChangeProjectThumbnail(name, newThumb string) error {
 func (db DB) GetProject(name string) (*entities.GalleryItem, error) {
 	var project entities.GalleryItem
 
-	query := "SELECT title, caption, project_info, thumbnail, video_key FROM gallery_items WHERE id=$1;"
+	query := "SELECT " + galleryItemColumns + " FROM gallery_items WHERE id=$1;"
 	if err := db.db.Get(&project, query, name); err != nil {
 		return nil, err
 	}
 
-	project.Name = name
-
 	images := make([]string, 0)
-	query = "SELECT file_name FROM project_images WHERE gallery_id=$1;"
+	query = "SELECT file_name FROM project_images WHERE gallery_id=$1 ORDER BY id;"
 
 	if err := db.db.Select(&images, query, name); err != nil {
 		return nil, err
 	}
 
 	project.Images = images
+
+	credits, err := db.GetProjectCredits(name)
+	if err != nil {
+		return nil, err
+	}
+
+	project.Credits = credits
+
+	testimonials, err := db.GetTestimonials(TestimonialFilter{Drafts: true, Project: name})
+	if err != nil {
+		return nil, err
+	}
+
+	project.Testimonials = testimonials
 	return &project, nil
 }
 
-// UpdateProject sets the title, caption, project info, and embed URL fields for a project
-// with the same name in the database.
+// UpdateProject sets the title, caption, project info, video key, metadata,
+// and SEO fields for a project with the same name in the database.
 func (db DB) UpdateProject(project *entities.GalleryItem) error {
 	tx := db.db.MustBegin()
 
+	if err := updateProject(tx, project); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// PatchProject applies a JSON Merge Patch to a project's fields and its
+// published state. The row is locked while the patch is applied, so
+// concurrent edits can't overwrite each other. The patched project is passed
+// to check before it's saved, and nothing is saved if check returns an error.
+//
+// The images, credits, and testimonials of the project aren't changed.
+func (db DB) PatchProject(name string, body []byte, check func(*entities.GalleryItem) error) error {
+	tx := db.db.MustBegin()
+
+	var current entities.GalleryItem
+	query := "SELECT " + galleryItemColumns + " FROM gallery_items WHERE id=$1 FOR UPDATE;"
+	if err := tx.Get(&current, query, name); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	doc, err := json.Marshal(&current)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	merged, err := patch.Merge(doc, body)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var updated entities.GalleryItem
+	if err := json.Unmarshal(merged, &updated); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// The name is the project's ID, so it can't be changed by a patch
+	updated.Name = name
+
+	if err := check(&updated); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := updateProject(tx, &updated); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE gallery_items SET published=$1 WHERE id=$2;", updated.Published, name); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// updateProject saves the fields of a project that can be edited, as part of
+// a transaction.
+func updateProject(tx *sqlx.Tx, project *entities.GalleryItem) error {
 	sql := `
 	UPDATE
 		gallery_items
@@ -195,12 +436,34 @@ func (db DB) UpdateProject(project *entities.GalleryItem) error {
 		title = $1,
 		caption = $2,
 		project_info = $3,
-		video_key = $4
+		video_key = $4,
+		year_start = $5,
+		year_end = $6,
+		client = $7,
+		services = $8,
+		links = $9,
+		live_url = $10,
+		meta_title = $11,
+		meta_description = $12,
+		share_image = $13,
+		canonical_url = $14,
+		no_index = $15,
+		updated_at = NOW()
 	WHERE
-		id = $5;
+		id = $16;
 	`
 
-	tx.MustExec(sql, project.Title, project.Caption, project.ProjectInfo, project.VideoKey.String, project.Name)
+	_, err := tx.Exec(sql, project.Title, project.Caption, project.ProjectInfo, project.VideoKey.String,
+		project.YearStart, project.YearEnd, project.Client, project.Services, project.Links, project.LiveURL,
+		project.MetaTitle, project.MetaDescription, project.ShareImage, project.CanonicalURL, project.NoIndex, project.Name)
+	return err
+}
+
+// SetProjectPublished sets whether a project is publicly visible.
+func (db DB) SetProjectPublished(name string, published bool) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("UPDATE gallery_items SET published=$1, updated_at=NOW() WHERE id=$2;", published, name)
+
 	if err := tx.Commit(); err != nil {
 		tx.Rollback()
 		return err
@@ -209,40 +472,125 @@ func (db DB) UpdateProject(project *entities.GalleryItem) error {
 	return nil
 }
 
-// GetGalleryItems returns all gallery items from the database.
-func (db DB) GetGalleryItems() ([]*entities.GalleryItem, error) {
+// galleryItemColumns are the gallery_items columns that make up a
+// GalleryItem.
+const galleryItemColumns = `
+	id,
+	title,
+	caption,
+	project_info,
+	thumbnail,
+	video_key,
+	published,
+	year_start,
+	year_end,
+	client,
+	services,
+	links,
+	live_url,
+	updated_at,
+	meta_title,
+	meta_description,
+	share_image,
+	canonical_url,
+	no_index`
+
+// GalleryFilter narrows down which gallery items are returned. The zero value
+// returns every published item.
+type GalleryFilter struct {
+	// Drafts includes unpublished items.
+	Drafts bool
+	// Year only includes items whose year range covers this year.
+	Year int
+	// Client only includes items for this client, ignoring case.
+	Client string
+	// Service only includes items that list this service.
+	Service string
+}
+
+// GetGalleryItems returns the gallery items from the database that match the
+// given filter.
+//
+// The images for every item are fetched in a single batched query, so the
+// number of queries stays the same no matter how many items there are.
+func (db DB) GetGalleryItems(filter GalleryFilter) ([]*entities.GalleryItem, error) {
 	items := make([]*entities.GalleryItem, 0)
 
-	query := `SELECT
-		id,
-		title,
-		caption,
-		project_info,
-		thumbnail,
-		video_key
-	FROM gallery_items;`
+	args := []interface{}{filter.Drafts}
+	conditions := []string{"(published OR $1)"}
+
+	if filter.Year != 0 {
+		args = append(args, filter.Year)
+		conditions = append(conditions, fmt.Sprintf("year_start <= $%d AND COALESCE(year_end, year_start) >= $%d", len(args), len(args)))
+	}
+
+	if filter.Client != "" {
+		args = append(args, filter.Client)
+		conditions = append(conditions, fmt.Sprintf("LOWER(client) = LOWER($%d)", len(args)))
+	}
+
+	if filter.Service != "" {
+		args = append(args, filter.Service)
+		conditions = append(conditions, fmt.Sprintf("services ? $%d", len(args)))
+	}
 
-	if err := db.db.Select(&items, query); err != nil {
+	query := "SELECT " + galleryItemColumns + " FROM gallery_items WHERE " + strings.Join(conditions, " AND ") + ";"
+
+	if err := db.db.Select(&items, query, args...); err != nil {
 		return nil, err
 	}
 
-	// Get the project images for each gallery item
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	ids := make([]string, 0, len(items))
 	for _, item := range items {
-		images := make([]string, 0)
-		query := `SELECT
-			file_name
-		FROM project_images WHERE gallery_id=$1;`
+		ids = append(ids, item.Name)
+	}
 
-		if err := db.db.Select(&images, query, item.Name); err != nil {
-			continue
-		}
+	images, err := db.getProjectImages(ids)
+	if err != nil {
+		return nil, err
+	}
 
-		item.Images = images
+	for _, item := range items {
+		item.Images = images[item.Name]
+		if item.Images == nil {
+			item.Images = make([]string, 0)
+		}
 	}
 
 	return items, nil
 }
 
+// projectImage is a single row of the project_images table.
+type projectImage struct {
+	GalleryID string `db:"gallery_id"`
+	FileName  string `db:"file_name"`
+}
+
+// getProjectImages fetches the images for all of the given projects in one
+// query, returning them grouped by project ID.
+func (db DB) getProjectImages(ids []string) (map[string][]string, error) {
+	query, args, err := sqlx.In("SELECT gallery_id, file_name FROM project_images WHERE gallery_id IN (?) ORDER BY id;", ids)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]projectImage, 0)
+	if err := db.db.Select(&rows, db.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string][]string, len(ids))
+	for _, row := range rows {
+		ret[row.GalleryID] = append(ret[row.GalleryID], row.FileName)
+	}
+
+	return ret, nil
+}
+
 // RemoveGalleryItem delets a gallery item from the database.
 func (db DB) RemoveGalleryItem(name string) error {
 	tx := db.db.MustBegin()
@@ -263,6 +611,7 @@ func (db DB) AddProjectImages(galleryID string, files []string) error {
 	for _, file := range files {
 		tx.MustExec("INSERT INTO project_images (gallery_id, file_name) VALUES ($1, $2);", galleryID, file)
 	}
+	tx.MustExec("UPDATE gallery_items SET updated_at=NOW() WHERE id=$1;", galleryID)
 
 	if err := tx.Commit(); err != nil {
 		tx.Rollback()
@@ -293,6 +642,7 @@ func (db DB) RemoveProjectImages(galleryID string, files []string) error {
 
 	tx := db.db.MustBegin()
 	tx.MustExec(sb.String(), args...)
+	tx.MustExec("UPDATE gallery_items SET updated_at=NOW() WHERE id=$1;", galleryID)
 
 	if err := tx.Commit(); err != nil {
 		tx.Rollback()
@@ -302,6 +652,35 @@ func (db DB) RemoveProjectImages(galleryID string, files []string) error {
 	return nil
 }
 
+// GetProjectFilesInUse returns which of the given files are still used by a
+// project, either as one of its images or as its thumbnail.
+func (db DB) GetProjectFilesInUse(files []string) (map[string]bool, error) {
+	ret := make(map[string]bool)
+	if len(files) == 0 {
+		return ret, nil
+	}
+
+	query, args, err := sqlx.In(`
+	SELECT file_name FROM project_images WHERE file_name IN (?)
+	UNION
+	SELECT thumbnail FROM gallery_items WHERE thumbnail IN (?);
+	`, files, files)
+	if err != nil {
+		return nil, err
+	}
+
+	var used []string
+	if err := db.db.Select(&used, db.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	for _, file := range used {
+		ret[file] = true
+	}
+
+	return ret, nil
+}
+
 // AddUser inserts a new user into the database.
 func (db DB) AddUser(username, password string, protected bool) error {
 	tx := db.db.MustBegin()
@@ -0,0 +1,218 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// fakeGallery is a minimal database/sql driver that serves canned gallery
+// rows and counts how many queries it has been sent.
+type fakeGallery struct {
+	items      int
+	images     int
+	queries    int
+	failImages bool
+}
+
+// Connect implements driver.Connector.
+func (f *fakeGallery) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{f}, nil
+}
+
+// Driver implements driver.Connector.
+func (f *fakeGallery) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct {
+	f *fakeGallery
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions not supported")
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.f.queries++
+
+	switch {
+	case strings.Contains(query, "FROM gallery_items"):
+		values := make([][]driver.Value, 0, c.f.items)
+		for i := 0; i < c.f.items; i++ {
+			id := fmt.Sprintf("project-%d", i)
+			values = append(values, []driver.Value{id, "Title", "Caption", "Info", id + "-thumb.png", nil, true, nil, nil, nil, "[]", "[]", nil,
+				time.Time{}, "", "", nil, nil, false})
+		}
+
+		return &fakeRows{
+			columns: []string{
+				"id", "title", "caption", "project_info", "thumbnail", "video_key", "published",
+				"year_start", "year_end", "client", "services", "links", "live_url",
+				"updated_at", "meta_title", "meta_description", "share_image", "canonical_url", "no_index",
+			},
+			values: values,
+		}, nil
+	case strings.Contains(query, "FROM project_images"):
+		if c.f.failImages {
+			return nil, errors.New("project images unavailable")
+		}
+
+		values := make([][]driver.Value, 0, len(args)*c.f.images)
+		for _, arg := range args {
+			for i := 0; i < c.f.images; i++ {
+				values = append(values, []driver.Value{arg.Value, fmt.Sprintf("%s-%d.png", arg.Value, i)})
+			}
+		}
+
+		return &fakeRows{
+			columns: []string{"gallery_id", "file_name"},
+			values:  values,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// newFakeDB creates a DB backed by the given fake driver.
+func newFakeDB(f *fakeGallery) DB {
+	return DB{sqlx.NewDb(sql.OpenDB(f), "pgx")}
+}
+
+// TestGetGalleryItems_QueryCount ensures that loading the gallery always takes
+// the same number of queries, regardless of how many items there are.
+func TestGetGalleryItems_QueryCount(t *testing.T) {
+	for _, size := range []int{1, 10, 100} {
+		// Given
+		f := &fakeGallery{items: size, images: 3}
+		db := newFakeDB(f)
+
+		// When
+		items, err := db.GetGalleryItems(GalleryFilter{})
+
+		// Then
+		if err != nil {
+			t.Fatalf("error getting gallery items: %s\n", err.Error())
+		}
+
+		if len(items) != size {
+			t.Fatalf("wrong number of items: got %d, expected: %d\n", len(items), size)
+		}
+
+		for _, item := range items {
+			if len(item.Images) != 3 {
+				t.Fatalf("wrong number of images for %s: got %d, expected: 3\n", item.Name, len(item.Images))
+			}
+		}
+
+		if f.queries != 2 {
+			t.Fatalf("wrong number of queries for %d items: got %d, expected: 2\n", size, f.queries)
+		}
+	}
+}
+
+// TestGetGalleryItems_NoImages ensures that items without images get an empty
+// images slice instead of nil.
+func TestGetGalleryItems_NoImages(t *testing.T) {
+	// Given
+	db := newFakeDB(&fakeGallery{items: 2})
+
+	// When
+	items, err := db.GetGalleryItems(GalleryFilter{})
+
+	// Then
+	if err != nil {
+		t.Fatalf("error getting gallery items: %s\n", err.Error())
+	}
+
+	for _, item := range items {
+		if item.Images == nil {
+			t.Fatalf("images for %s is nil, expected an empty slice\n", item.Name)
+		}
+	}
+}
+
+// TestGetGalleryItems_ImageError ensures that an error fetching project images
+// is returned instead of being skipped.
+func TestGetGalleryItems_ImageError(t *testing.T) {
+	// Given
+	db := newFakeDB(&fakeGallery{items: 2, failImages: true})
+
+	// When
+	items, err := db.GetGalleryItems(GalleryFilter{})
+
+	// Then
+	if err == nil {
+		t.Fatal("expected an error when project images can't be fetched")
+	}
+
+	if items != nil {
+		t.Fatalf("expected no items on error, got %d\n", len(items))
+	}
+}
+
+// BenchmarkGetGalleryItems loads galleries of increasing size and reports the
+// number of queries each load takes.
+func BenchmarkGetGalleryItems(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("items=%d", size), func(b *testing.B) {
+			f := &fakeGallery{items: size, images: 5}
+			db := newFakeDB(f)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := db.GetGalleryItems(GalleryFilter{}); err != nil {
+					b.Fatalf("error getting gallery items: %s\n", err.Error())
+				}
+			}
+			b.StopTimer()
+
+			perOp := float64(f.queries) / float64(b.N)
+			if perOp != 2 {
+				b.Fatalf("query count grows with gallery size: %.2f queries/op for %d items\n", perOp, size)
+			}
+
+			b.ReportMetric(perOp, "queries/op")
+		})
+	}
+}
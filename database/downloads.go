@@ -0,0 +1,120 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// downloadLinkColumns are the columns selected for a download link.
+const downloadLinkColumns = "id, directory, file_name, max_downloads, downloads, requests, expires_at, revoked, created_at"
+
+// AddDownloadLink inserts a new download link into the database, returning
+// the link with its ID and creation time set.
+func (db DB) AddDownloadLink(link *entities.DownloadLink) (*entities.DownloadLink, error) {
+	tx := db.db.MustBegin()
+
+	query := `INSERT INTO download_links (
+		directory,
+		file_name,
+		max_downloads,
+		expires_at
+	) VALUES ($1, $2, $3, $4) RETURNING ` + downloadLinkColumns + ";"
+
+	var ret entities.DownloadLink
+	if err := tx.Get(&ret, query, link.Directory, link.FileName, link.MaxDownloads, link.ExpiresAt); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// GetDownloadLinks fetches all download links from the database, newest
+// first.
+func (db DB) GetDownloadLinks() ([]*entities.DownloadLink, error) {
+	ret := make([]*entities.DownloadLink, 0)
+	if err := db.db.Select(&ret, "SELECT "+downloadLinkColumns+" FROM download_links ORDER BY created_at DESC, id DESC;"); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// RevokeDownloadLink stops a download link from being used again. If there
+// is no link with the ID, sql.ErrNoRows is returned.
+func (db DB) RevokeDownloadLink(id uint) error {
+	tx := db.db.MustBegin()
+
+	res := tx.MustExec("UPDATE download_links SET revoked=TRUE WHERE id=$1;", id)
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// UseDownloadLink counts a request against a link for the given file. Every
+// request is counted, and requests that don't resume an earlier download are
+// counted as downloads as well.
+//
+// A link with a download limit allows requestsPerDownload requests for each
+// download, so downloads can be resumed a few times but the file can't be
+// fetched over and over with ranged requests. Resuming is only allowed once
+// the link has been used for a download.
+//
+// The counts are only changed if the link hasn't been revoked, hasn't
+// expired, and is within its limits, so a single-use link can't be used
+// twice even by concurrent requests. If the link can't be used,
+// sql.ErrNoRows is returned.
+func (db DB) UseDownloadLink(id uint, directory, fileName string, resume bool, requestsPerDownload int) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		download_links
+	SET
+		downloads = downloads + CASE WHEN $4 THEN 0 ELSE 1 END,
+		requests = requests + 1
+	WHERE
+		id = $1
+		AND directory = $2
+		AND file_name = $3
+		AND NOT revoked
+		AND expires_at > NOW()
+		AND (NOT $4 OR downloads > 0)
+		AND (
+			max_downloads IS NULL
+			OR (($4 OR downloads < max_downloads) AND requests < max_downloads * $5)
+		);
+	`
+
+	res, err := tx.Exec(query, id, directory, fileName, resume, requestsPerDownload)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package database
+
+import "github.com/nicolekellydesign/webby-api/entities"
+
+// enquiryQuery selects commission enquiries along with how many attachments
+// they have.
+const enquiryQuery = `
+	SELECT
+		enquiries.id, enquiries.name, enquiries.email, enquiries.project_type, enquiries.budget_min,
+		enquiries.budget_max, enquiries.deadline, enquiries.description, enquiries.created_at,
+		(SELECT COUNT(*) FROM enquiry_attachments WHERE enquiry_attachments.enquiry_id = enquiries.id) AS attachment_count
+	FROM enquiries
+`
+
+// AddEnquiry inserts a new commission enquiry and its attachments into the
+// database, returning the new enquiry's ID.
+func (db DB) AddEnquiry(enquiry *entities.Enquiry) (uint, error) {
+	tx := db.db.MustBegin()
+
+	query := `INSERT INTO enquiries (
+		name,
+		email,
+		project_type,
+		budget_min,
+		budget_max,
+		deadline,
+		description
+	) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id;`
+
+	var id uint
+	if err := tx.QueryRowx(query, enquiry.Name, enquiry.Email, enquiry.ProjectType, enquiry.BudgetMin,
+		enquiry.BudgetMax, enquiry.Deadline, enquiry.Description).Scan(&id); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	query = `INSERT INTO enquiry_attachments (
+		enquiry_id,
+		file_name,
+		stored_name,
+		content_type,
+		size,
+		position
+	) VALUES ($1, $2, $3, $4, $5, $6);`
+
+	for i, attachment := range enquiry.Attachments {
+		if _, err := tx.Exec(query, id, attachment.FileName, attachment.StoredName, attachment.ContentType, attachment.Size, i); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetEnquiries fetches all commission enquiries from the database, newest
+// first. Their attachments aren't included.
+func (db DB) GetEnquiries() ([]*entities.Enquiry, error) {
+	ret := make([]*entities.Enquiry, 0)
+	if err := db.db.Select(&ret, enquiryQuery+"ORDER BY enquiries.created_at DESC, enquiries.id DESC;"); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetEnquiry fetches the commission enquiry with the given ID from the
+// database, along with its attachments.
+func (db DB) GetEnquiry(id uint) (*entities.Enquiry, error) {
+	var ret entities.Enquiry
+	if err := db.db.Get(&ret, enquiryQuery+"WHERE enquiries.id = $1;", id); err != nil {
+		return nil, err
+	}
+
+	ret.Attachments = make([]*entities.EnquiryAttachment, 0)
+
+	query := "SELECT id, file_name, stored_name, content_type, size FROM enquiry_attachments WHERE enquiry_id = $1 ORDER BY position, id;"
+	if err := db.db.Select(&ret.Attachments, query, id); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// GetEnquiryAttachment fetches an attachment of a commission enquiry from the
+// database.
+func (db DB) GetEnquiryAttachment(enquiryID, id uint) (*entities.EnquiryAttachment, error) {
+	var ret entities.EnquiryAttachment
+
+	query := "SELECT id, file_name, stored_name, content_type, size FROM enquiry_attachments WHERE enquiry_id = $1 AND id = $2;"
+	if err := db.db.Get(&ret, query, enquiryID, id); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// RemoveEnquiry deletes a commission enquiry and its attachments from the
+// database. The attachment files aren't removed.
+func (db DB) RemoveEnquiry(id uint) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM enquiries WHERE id = $1;", id)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import "github.com/nicolekellydesign/webby-api/entities"
+
+// GetImageUses fetches every place an image file is used on the site: in the
+// photography gallery, in a project, or as a project thumbnail.
+func (db DB) GetImageUses() ([]*entities.ImageUse, error) {
+	ret := make([]*entities.ImageUse, 0)
+
+	query := `
+		SELECT file_name, 'photo' AS kind, NULL AS project FROM photos
+		UNION ALL
+		SELECT file_name, 'project' AS kind, gallery_id AS project FROM project_images
+		UNION ALL
+		SELECT thumbnail AS file_name, 'thumbnail' AS kind, id AS project FROM gallery_items
+		ORDER BY file_name, kind, project;
+	`
+
+	if err := db.db.Select(&ret, query); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetImageHashes fetches all stored image hashes, keyed by file name.
+func (db DB) GetImageHashes() (map[string]*entities.ImageHash, error) {
+	rows := make([]*entities.ImageHash, 0)
+	if err := db.db.Select(&rows, "SELECT file_name, hash, size, mod_time FROM image_hashes;"); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]*entities.ImageHash, len(rows))
+	for _, row := range rows {
+		ret[row.FileName] = row
+	}
+
+	return ret, nil
+}
+
+// SetImageHashes stores the hashes for image files, replacing any hashes that
+// were already stored for the same files.
+func (db DB) SetImageHashes(hashes []*entities.ImageHash) error {
+	tx := db.db.MustBegin()
+
+	query := `
+		INSERT INTO image_hashes (file_name, hash, size, mod_time) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (file_name) DO UPDATE SET hash = $2, size = $3, mod_time = $4;
+	`
+
+	for _, hash := range hashes {
+		tx.MustExec(query, hash.FileName, hash.Hash, hash.Size, hash.ModTime)
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// menuLinkColumns are the columns of the menu_items table that hold each kind
+// of internal link.
+var menuLinkColumns = map[string]string{
+	entities.MenuLinkProject: "project_id",
+	entities.MenuLinkPage:    "page_id",
+	entities.MenuLinkAlbum:   "album_id",
+}
+
+// GetMenu fetches the items in a menu from the database, nested under their
+// parents in display order. Pages and albums are linked by their current slug.
+func (db DB) GetMenu(menu string) ([]*entities.MenuItem, error) {
+	var rows []struct {
+		entities.MenuItem
+		ID       uint          `db:"id"`
+		ParentID sql.NullInt32 `db:"parent_id"`
+	}
+
+	query := `
+	SELECT
+		menu_items.id, menu_items.parent_id, menu_items.label, menu_items.link_type,
+		COALESCE(menu_items.project_id, pages.slug, albums.slug, menu_items.url) AS target,
+		menu_items.new_tab
+	FROM
+		menu_items
+	LEFT JOIN pages ON pages.id = menu_items.page_id
+	LEFT JOIN albums ON albums.id = menu_items.album_id
+	WHERE
+		menu_items.menu = $1
+	ORDER BY
+		menu_items.position, menu_items.id;
+	`
+
+	if err := db.db.Select(&rows, query, menu); err != nil {
+		return nil, err
+	}
+
+	items := make(map[uint]*entities.MenuItem, len(rows))
+	for i := range rows {
+		item := &rows[i].MenuItem
+		item.Children = make([]*entities.MenuItem, 0)
+		items[rows[i].ID] = item
+	}
+
+	// Rows are in display order, so appending keeps every level in order
+	ret := make([]*entities.MenuItem, 0)
+	for _, row := range rows {
+		item := items[row.ID]
+		if parent, ok := items[uint(row.ParentID.Int32)]; row.ParentID.Valid && ok {
+			parent.Children = append(parent.Children, item)
+		} else {
+			ret = append(ret, item)
+		}
+	}
+
+	return ret, nil
+}
+
+// SetMenu replaces all of the items in a menu.
+func (db DB) SetMenu(menu string, items []*entities.MenuItem) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM menu_items WHERE menu = $1;", menu)
+
+	if err := addMenuItems(tx, menu, sql.NullInt32{}, items); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// addMenuItems inserts menu items under a parent, along with everything
+// nested under them. Pages and albums are looked up by slug and stored by ID,
+// so that changing their slug doesn't break the menu.
+func addMenuItems(tx *sqlx.Tx, menu string, parent sql.NullInt32, items []*entities.MenuItem) error {
+	query := `INSERT INTO menu_items (
+		menu,
+		parent_id,
+		position,
+		label,
+		link_type,
+		project_id,
+		page_id,
+		album_id,
+		url,
+		new_tab
+	) VALUES (
+		$1, $2, $3, $4, $5,
+		$6,
+		(SELECT id FROM pages WHERE slug = $7),
+		(SELECT id FROM albums WHERE slug = $8),
+		$9, $10
+	) RETURNING id;`
+
+	for i, item := range items {
+		var project, page, album, url sql.NullString
+		target := sql.NullString{String: item.Target, Valid: true}
+
+		switch item.LinkType {
+		case entities.MenuLinkProject:
+			project = target
+		case entities.MenuLinkPage:
+			page = target
+		case entities.MenuLinkAlbum:
+			album = target
+		case entities.MenuLinkURL:
+			url = target
+		}
+
+		var id int32
+		if err := tx.QueryRowx(query, menu, parent, i, item.Label, item.LinkType, project, page, album, url, item.NewTab).Scan(&id); err != nil {
+			return err
+		}
+
+		if err := addMenuItems(tx, menu, sql.NullInt32{Int32: id, Valid: true}, item.Children); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MenuLinkExists checks if the target of an internal menu link exists.
+func (db DB) MenuLinkExists(linkType, target string) (bool, error) {
+	var query string
+	switch linkType {
+	case entities.MenuLinkProject:
+		query = "SELECT EXISTS (SELECT 1 FROM gallery_items WHERE id = $1);"
+	case entities.MenuLinkPage:
+		query = "SELECT EXISTS (SELECT 1 FROM pages WHERE slug = $1);"
+	case entities.MenuLinkAlbum:
+		query = "SELECT EXISTS (SELECT 1 FROM albums WHERE slug = $1);"
+	default:
+		return false, nil
+	}
+
+	var exists bool
+	if err := db.db.Get(&exists, query, target); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// GetMenusLinkingTo fetches the names of the menus that link to a project,
+// page, or album. Projects are given by name, and pages and albums by ID.
+func (db DB) GetMenusLinkingTo(linkType string, id interface{}) ([]string, error) {
+	ret := make([]string, 0)
+
+	column, ok := menuLinkColumns[linkType]
+	if !ok {
+		return ret, nil
+	}
+
+	query := "SELECT DISTINCT menu FROM menu_items WHERE " + column + " = $1 ORDER BY menu;"
+	if err := db.db.Select(&ret, query, id); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
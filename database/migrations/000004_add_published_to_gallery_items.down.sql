@@ -0,0 +1 @@
+ALTER TABLE gallery_items DROP COLUMN IF EXISTS published;
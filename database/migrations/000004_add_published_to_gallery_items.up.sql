@@ -0,0 +1 @@
+ALTER TABLE gallery_items ADD COLUMN IF NOT EXISTS published BOOL NOT NULL DEFAULT TRUE;
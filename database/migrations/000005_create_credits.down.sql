@@ -0,0 +1,2 @@
+DROP TABLE project_credits,
+contributors;
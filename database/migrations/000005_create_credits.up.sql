@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS contributors (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL DEFAULT 'person',
+    url TEXT,
+    CONSTRAINT contributors_kind_check CHECK (kind IN ('person', 'organisation'))
+);
+CREATE TABLE IF NOT EXISTS project_credits (
+    id SERIAL PRIMARY KEY,
+    gallery_id TEXT NOT NULL,
+    contributor_id INTEGER NOT NULL,
+    role TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT fk_gallery FOREIGN KEY(gallery_id) REFERENCES gallery_items(id) ON DELETE CASCADE,
+    CONSTRAINT fk_contributor FOREIGN KEY(contributor_id) REFERENCES contributors(id) ON DELETE CASCADE
+);
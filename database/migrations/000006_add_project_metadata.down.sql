@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS gallery_items_years_idx;
+ALTER TABLE gallery_items
+    DROP COLUMN IF EXISTS year_start,
+    DROP COLUMN IF EXISTS year_end,
+    DROP COLUMN IF EXISTS client,
+    DROP COLUMN IF EXISTS services,
+    DROP COLUMN IF EXISTS links,
+    DROP COLUMN IF EXISTS live_url;
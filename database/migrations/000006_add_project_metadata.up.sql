@@ -0,0 +1,8 @@
+ALTER TABLE gallery_items
+    ADD COLUMN IF NOT EXISTS year_start INTEGER,
+    ADD COLUMN IF NOT EXISTS year_end INTEGER,
+    ADD COLUMN IF NOT EXISTS client TEXT,
+    ADD COLUMN IF NOT EXISTS services JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS links JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS live_url TEXT;
+CREATE INDEX IF NOT EXISTS gallery_items_years_idx ON gallery_items (year_start, year_end);
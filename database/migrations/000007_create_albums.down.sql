@@ -0,0 +1,2 @@
+DROP TABLE album_photos,
+albums;
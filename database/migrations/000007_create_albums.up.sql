@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS albums (
+    id SERIAL PRIMARY KEY,
+    slug TEXT UNIQUE NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    cover_photo_id INTEGER,
+    position INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT fk_cover_photo FOREIGN KEY(cover_photo_id) REFERENCES photos(id) ON DELETE SET NULL
+);
+CREATE TABLE IF NOT EXISTS album_photos (
+    album_id INTEGER NOT NULL,
+    photo_id INTEGER NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (album_id, photo_id),
+    CONSTRAINT fk_album FOREIGN KEY(album_id) REFERENCES albums(id) ON DELETE CASCADE,
+    CONSTRAINT fk_photo FOREIGN KEY(photo_id) REFERENCES photos(id) ON DELETE CASCADE
+);
@@ -0,0 +1,6 @@
+ALTER TABLE photos
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS caption,
+    DROP COLUMN IF EXISTS alt_text,
+    DROP COLUMN IF EXISTS location,
+    DROP COLUMN IF EXISTS taken_at;
@@ -0,0 +1,6 @@
+ALTER TABLE photos
+    ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS caption TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS alt_text TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS location TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS taken_at TIMESTAMPTZ;
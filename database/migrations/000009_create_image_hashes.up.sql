@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS image_hashes (
+    file_name TEXT UNIQUE NOT NULL PRIMARY KEY,
+    hash BIGINT NOT NULL,
+    size BIGINT NOT NULL,
+    mod_time TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,2 @@
+ALTER TABLE photos DROP COLUMN IF EXISTS watermark;
+DROP TABLE watermark;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS watermark (
+    id INTEGER PRIMARY KEY DEFAULT 1,
+    enabled BOOL NOT NULL DEFAULT FALSE,
+    image TEXT,
+    text TEXT NOT NULL DEFAULT '',
+    position TEXT NOT NULL DEFAULT 'bottom-right',
+    opacity DOUBLE PRECISION NOT NULL DEFAULT 0.3,
+    scale DOUBLE PRECISION NOT NULL DEFAULT 0.2,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT watermark_single_row CHECK (id = 1)
+);
+INSERT INTO watermark (id) VALUES (1) ON CONFLICT DO NOTHING;
+ALTER TABLE photos ADD COLUMN IF NOT EXISTS watermark BOOL NOT NULL DEFAULT TRUE;
@@ -0,0 +1,4 @@
+DROP TABLE proofing_sessions,
+proofing_comments,
+proofing_photos,
+proofing_galleries;
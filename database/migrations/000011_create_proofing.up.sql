@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS proofing_galleries (
+    id SERIAL PRIMARY KEY,
+    title TEXT NOT NULL,
+    client_name TEXT NOT NULL DEFAULT '',
+    share_token TEXT UNIQUE NOT NULL,
+    pwdhash TEXT,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS proofing_photos (
+    id SERIAL PRIMARY KEY,
+    gallery_id INTEGER NOT NULL,
+    file_name TEXT NOT NULL,
+    favourite BOOL NOT NULL DEFAULT FALSE,
+    CONSTRAINT proofing_photos_gallery_file_key UNIQUE (gallery_id, file_name),
+    CONSTRAINT fk_gallery FOREIGN KEY(gallery_id) REFERENCES proofing_galleries(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS proofing_comments (
+    id SERIAL PRIMARY KEY,
+    photo_id INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_photo FOREIGN KEY(photo_id) REFERENCES proofing_photos(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS proofing_sessions (
+    token TEXT UNIQUE NOT NULL PRIMARY KEY,
+    gallery_id INTEGER NOT NULL,
+    created TIMESTAMPTZ NOT NULL,
+    max_age BIGINT NOT NULL,
+    CONSTRAINT fk_gallery FOREIGN KEY(gallery_id) REFERENCES proofing_galleries(id) ON DELETE CASCADE
+);
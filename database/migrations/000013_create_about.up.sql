@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS about (
+    id INTEGER PRIMARY KEY DEFAULT 1,
+    portrait TEXT NOT NULL DEFAULT '',
+    statement TEXT NOT NULL DEFAULT '',
+    resume TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT about_single_row CHECK (id = 1)
+);
+INSERT INTO about (id) VALUES (1) ON CONFLICT DO NOTHING;
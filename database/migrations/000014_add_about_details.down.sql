@@ -0,0 +1,6 @@
+DROP TABLE awards,
+about_clients,
+social_links,
+about_sections;
+ALTER TABLE about DROP COLUMN IF EXISTS location;
+ALTER TABLE about DROP COLUMN IF EXISTS contact_email;
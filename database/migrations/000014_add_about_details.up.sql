@@ -0,0 +1,30 @@
+ALTER TABLE about ADD COLUMN IF NOT EXISTS contact_email TEXT NOT NULL DEFAULT '';
+ALTER TABLE about ADD COLUMN IF NOT EXISTS location TEXT NOT NULL DEFAULT '';
+CREATE TABLE IF NOT EXISTS about_sections (
+    id SERIAL PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS social_links (
+    id SERIAL PRIMARY KEY,
+    platform TEXT NOT NULL,
+    url TEXT NOT NULL,
+    label TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS about_clients (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    url TEXT,
+    position INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS awards (
+    id SERIAL PRIMARY KEY,
+    title TEXT NOT NULL,
+    issuer TEXT NOT NULL DEFAULT '',
+    year INTEGER,
+    url TEXT,
+    description TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS cv (
+    id INTEGER PRIMARY KEY DEFAULT 1,
+    data JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT cv_single_row CHECK (id = 1)
+);
+INSERT INTO cv (id) VALUES (1) ON CONFLICT DO NOTHING;
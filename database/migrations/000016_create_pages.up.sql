@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS pages (
+    id SERIAL PRIMARY KEY,
+    slug TEXT UNIQUE NOT NULL,
+    title TEXT NOT NULL,
+    format TEXT NOT NULL DEFAULT 'markdown',
+    body TEXT NOT NULL DEFAULT '',
+    blocks JSONB NOT NULL DEFAULT '[]',
+    meta_title TEXT NOT NULL DEFAULT '',
+    meta_description TEXT NOT NULL DEFAULT '',
+    share_image TEXT,
+    no_index BOOL NOT NULL DEFAULT FALSE,
+    published BOOL NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
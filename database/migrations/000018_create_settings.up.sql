@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS settings (
+    id INTEGER PRIMARY KEY DEFAULT 1,
+    site_title TEXT NOT NULL DEFAULT '',
+    meta_description TEXT NOT NULL DEFAULT '',
+    share_image TEXT,
+    primary_color TEXT NOT NULL DEFAULT '',
+    secondary_color TEXT NOT NULL DEFAULT '',
+    analytics_enabled BOOL NOT NULL DEFAULT FALSE,
+    footer_text TEXT NOT NULL DEFAULT '',
+    notification_email TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT settings_single_row CHECK (id = 1)
+);
+INSERT INTO settings (id) VALUES (1) ON CONFLICT DO NOTHING;
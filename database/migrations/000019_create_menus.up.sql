@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS menu_items (
+    id SERIAL PRIMARY KEY,
+    menu TEXT NOT NULL,
+    parent_id INTEGER,
+    position INTEGER NOT NULL DEFAULT 0,
+    label TEXT NOT NULL,
+    link_type TEXT NOT NULL,
+    project_id TEXT,
+    page_id INTEGER,
+    album_id INTEGER,
+    url TEXT,
+    new_tab BOOL NOT NULL DEFAULT FALSE,
+    CONSTRAINT fk_parent FOREIGN KEY(parent_id) REFERENCES menu_items(id) ON DELETE CASCADE,
+    CONSTRAINT fk_project FOREIGN KEY(project_id) REFERENCES gallery_items(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_page FOREIGN KEY(page_id) REFERENCES pages(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_album FOREIGN KEY(album_id) REFERENCES albums(id) ON DELETE RESTRICT,
+    CONSTRAINT menu_items_link CHECK (
+        (link_type = 'project') = (project_id IS NOT NULL) AND
+        (link_type = 'page') = (page_id IS NOT NULL) AND
+        (link_type = 'album') = (album_id IS NOT NULL) AND
+        (link_type = 'url') = (url IS NOT NULL)
+    )
+);
+CREATE INDEX IF NOT EXISTS menu_items_menu ON menu_items (menu, position);
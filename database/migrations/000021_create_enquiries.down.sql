@@ -0,0 +1,2 @@
+DROP TABLE enquiry_attachments;
+DROP TABLE enquiries;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS enquiries (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    project_type TEXT NOT NULL,
+    budget_min INTEGER,
+    budget_max INTEGER,
+    deadline DATE,
+    description TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS enquiry_attachments (
+    id SERIAL PRIMARY KEY,
+    enquiry_id INTEGER NOT NULL,
+    file_name TEXT NOT NULL,
+    stored_name TEXT UNIQUE NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT fk_enquiry FOREIGN KEY(enquiry_id) REFERENCES enquiries(id) ON DELETE CASCADE
+);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS testimonials (
+    id SERIAL PRIMARY KEY,
+    quote TEXT NOT NULL,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT '',
+    company TEXT NOT NULL DEFAULT '',
+    avatar TEXT,
+    project_id TEXT,
+    featured BOOL NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_project FOREIGN KEY(project_id) REFERENCES gallery_items(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS testimonials_project ON testimonials (project_id);
@@ -0,0 +1,4 @@
+DROP TABLE order_items;
+DROP TABLE orders;
+DROP TABLE print_variants;
+DROP TABLE print_products;
@@ -0,0 +1,53 @@
+CREATE TABLE IF NOT EXISTS print_products (
+    id SERIAL PRIMARY KEY,
+    photo_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    edition_size INTEGER,
+    sold INTEGER NOT NULL DEFAULT 0,
+    published BOOL NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_photo FOREIGN KEY(photo_id) REFERENCES photos(id) ON DELETE CASCADE,
+    CONSTRAINT print_products_edition_check CHECK (edition_size IS NULL OR (edition_size > 0 AND sold <= edition_size))
+);
+CREATE TABLE IF NOT EXISTS print_variants (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    size TEXT NOT NULL,
+    paper TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    stock INTEGER,
+    position INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT fk_product FOREIGN KEY(product_id) REFERENCES print_products(id) ON DELETE CASCADE,
+    CONSTRAINT print_variants_price_check CHECK (price > 0),
+    CONSTRAINT print_variants_stock_check CHECK (stock IS NULL OR stock >= 0)
+);
+CREATE TABLE IF NOT EXISTS orders (
+    id SERIAL PRIMARY KEY,
+    reference TEXT UNIQUE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    address JSONB NOT NULL,
+    total INTEGER NOT NULL,
+    currency TEXT NOT NULL,
+    payment_provider TEXT NOT NULL,
+    payment_id TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS order_items (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL,
+    product_id INTEGER,
+    variant_id INTEGER,
+    title TEXT NOT NULL,
+    size TEXT NOT NULL,
+    paper TEXT NOT NULL,
+    unit_price INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    CONSTRAINT fk_order FOREIGN KEY(order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_product FOREIGN KEY(product_id) REFERENCES print_products(id) ON DELETE SET NULL,
+    CONSTRAINT fk_variant FOREIGN KEY(variant_id) REFERENCES print_variants(id) ON DELETE SET NULL
+);
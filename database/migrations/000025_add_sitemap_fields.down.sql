@@ -0,0 +1,3 @@
+ALTER TABLE gallery_items DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE albums DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE settings DROP COLUMN IF EXISTS robots_txt;
@@ -0,0 +1,3 @@
+ALTER TABLE gallery_items ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE settings ADD COLUMN IF NOT EXISTS robots_txt TEXT NOT NULL DEFAULT '';
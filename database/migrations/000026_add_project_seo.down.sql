@@ -0,0 +1,6 @@
+ALTER TABLE gallery_items
+    DROP COLUMN IF EXISTS meta_title,
+    DROP COLUMN IF EXISTS meta_description,
+    DROP COLUMN IF EXISTS share_image,
+    DROP COLUMN IF EXISTS canonical_url,
+    DROP COLUMN IF EXISTS no_index;
@@ -0,0 +1,6 @@
+ALTER TABLE gallery_items
+    ADD COLUMN IF NOT EXISTS meta_title TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS meta_description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS share_image TEXT,
+    ADD COLUMN IF NOT EXISTS canonical_url TEXT,
+    ADD COLUMN IF NOT EXISTS no_index BOOL NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+UPDATE contributors SET url = '' WHERE url IS NULL;
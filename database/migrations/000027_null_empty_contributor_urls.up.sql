@@ -0,0 +1 @@
+UPDATE contributors SET url = NULL WHERE url = '';
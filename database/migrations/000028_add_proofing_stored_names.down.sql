@@ -0,0 +1 @@
+ALTER TABLE proofing_photos DROP COLUMN IF EXISTS stored_name;
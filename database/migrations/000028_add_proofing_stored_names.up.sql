@@ -0,0 +1 @@
+ALTER TABLE proofing_photos ADD COLUMN IF NOT EXISTS stored_name TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE download_links DROP COLUMN IF EXISTS requests;
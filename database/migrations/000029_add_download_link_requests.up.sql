@@ -0,0 +1,2 @@
+ALTER TABLE download_links ADD COLUMN IF NOT EXISTS requests INTEGER NOT NULL DEFAULT 0;
+UPDATE download_links SET requests = downloads;
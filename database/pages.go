@@ -0,0 +1,134 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// pageColumns are the columns selected for a page.
+const pageColumns = `
+	id, slug, title, format, body, blocks, meta_title, meta_description, share_image, no_index, published,
+	created_at, updated_at
+`
+
+// AddPage inserts a new page into the database, returning the new page's ID.
+func (db DB) AddPage(page *entities.Page) (uint, error) {
+	tx := db.db.MustBegin()
+
+	query := `INSERT INTO pages (
+		slug,
+		title,
+		format,
+		body,
+		blocks,
+		meta_title,
+		meta_description,
+		share_image,
+		no_index,
+		published
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id;`
+
+	var id uint
+	err := tx.QueryRowx(query, page.Slug, page.Title, page.Format, page.Body, page.Blocks, page.MetaTitle,
+		page.MetaDescription, page.ShareImage, page.NoIndex, page.Published).Scan(&id)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetPages fetches pages from the database, sorted by title. Unpublished pages
+// are only included if drafts is set.
+func (db DB) GetPages(drafts bool) ([]*entities.Page, error) {
+	ret := make([]*entities.Page, 0)
+	if err := db.db.Select(&ret, "SELECT "+pageColumns+" FROM pages WHERE published OR $1 ORDER BY title, id;", drafts); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetPage fetches the page with the given ID from the database.
+func (db DB) GetPage(id uint) (*entities.Page, error) {
+	var ret entities.Page
+	if err := db.db.Get(&ret, "SELECT "+pageColumns+" FROM pages WHERE id = $1;", id); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// GetPageBySlug fetches the page with the given slug from the database.
+func (db DB) GetPageBySlug(slug string) (*entities.Page, error) {
+	var ret entities.Page
+	if err := db.db.Get(&ret, "SELECT "+pageColumns+" FROM pages WHERE slug = $1;", slug); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// UpdatePage changes every field of an existing page. If there is no page
+// with the ID, sql.ErrNoRows is returned.
+func (db DB) UpdatePage(page *entities.Page) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		pages
+	SET
+		slug = $1,
+		title = $2,
+		format = $3,
+		body = $4,
+		blocks = $5,
+		meta_title = $6,
+		meta_description = $7,
+		share_image = $8,
+		no_index = $9,
+		published = $10,
+		updated_at = NOW()
+	WHERE
+		id = $11;
+	`
+
+	res, err := tx.Exec(query, page.Slug, page.Title, page.Format, page.Body, page.Blocks, page.MetaTitle,
+		page.MetaDescription, page.ShareImage, page.NoIndex, page.Published, page.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// RemovePage deletes a page from the database.
+func (db DB) RemovePage(id uint) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM pages WHERE id=$1;", id)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
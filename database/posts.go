@@ -0,0 +1,158 @@
+package database
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// postColumns are the columns selected for a post.
+const postColumns = "id, slug, title, excerpt, body, cover_image, tags, published, published_at, created_at, updated_at"
+
+// PostFilter narrows down which posts are fetched. Zero values don't filter
+// anything.
+type PostFilter struct {
+	// Drafts includes unpublished and scheduled posts.
+	Drafts bool
+
+	// Tag only includes posts with this tag.
+	Tag string
+
+	// Limit is the most posts to fetch.
+	Limit int
+}
+
+// AddPost inserts a new post into the database, returning the new post's ID.
+func (db DB) AddPost(post *entities.Post) (uint, error) {
+	tx := db.db.MustBegin()
+
+	query := `INSERT INTO posts (
+		slug,
+		title,
+		excerpt,
+		body,
+		cover_image,
+		tags,
+		published,
+		published_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;`
+
+	var id uint
+	err := tx.QueryRowx(query, post.Slug, post.Title, post.Excerpt, post.Body, post.CoverImage, post.Tags,
+		post.Published, post.PublishedAt).Scan(&id)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetPosts fetches posts from the database, newest first. Posts without a
+// publish date come before everything else.
+func (db DB) GetPosts(filter PostFilter) ([]*entities.Post, error) {
+	where := []string{"($1 OR (published AND published_at <= NOW()))"}
+	args := []interface{}{filter.Drafts}
+
+	if filter.Tag != "" {
+		args = append(args, filter.Tag)
+		where = append(where, "tags @> jsonb_build_array($2::text)")
+	}
+
+	query := "SELECT " + postColumns + " FROM posts WHERE " + strings.Join(where, " AND ") +
+		" ORDER BY published_at DESC NULLS FIRST, id DESC"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += " LIMIT $" + strconv.Itoa(len(args))
+	}
+
+	ret := make([]*entities.Post, 0)
+	if err := db.db.Select(&ret, query+";", args...); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetPost fetches the post with the given ID from the database.
+func (db DB) GetPost(id uint) (*entities.Post, error) {
+	var ret entities.Post
+	if err := db.db.Get(&ret, "SELECT "+postColumns+" FROM posts WHERE id = $1;", id); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// GetPostBySlug fetches the post with the given slug from the database.
+func (db DB) GetPostBySlug(slug string) (*entities.Post, error) {
+	var ret entities.Post
+	if err := db.db.Get(&ret, "SELECT "+postColumns+" FROM posts WHERE slug = $1;", slug); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// UpdatePost changes every field of an existing post. If there is no post
+// with the ID, sql.ErrNoRows is returned.
+func (db DB) UpdatePost(post *entities.Post) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		posts
+	SET
+		slug = $1,
+		title = $2,
+		excerpt = $3,
+		body = $4,
+		cover_image = $5,
+		tags = $6,
+		published = $7,
+		published_at = $8,
+		updated_at = NOW()
+	WHERE
+		id = $9;
+	`
+
+	res, err := tx.Exec(query, post.Slug, post.Title, post.Excerpt, post.Body, post.CoverImage, post.Tags,
+		post.Published, post.PublishedAt, post.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// RemovePost deletes a post from the database.
+func (db DB) RemovePost(id uint) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM posts WHERE id=$1;", id)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
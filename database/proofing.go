@@ -0,0 +1,361 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// proofingGalleryQuery selects proofing galleries without their password
+// hashes.
+const proofingGalleryQuery = `
+	SELECT
+		id, title, client_name, share_token, pwdhash IS NOT NULL AS has_password, expires_at, created_at
+	FROM proofing_galleries
+`
+
+// AddProofingGallery inserts a new proofing gallery into the database,
+// returning the new gallery's ID. If the password is empty, the gallery
+// can be viewed by anyone with the share link.
+func (db DB) AddProofingGallery(gallery *entities.ProofingGallery, password string) (uint, error) {
+	tx := db.db.MustBegin()
+
+	query := `INSERT INTO proofing_galleries (
+		title,
+		client_name,
+		share_token,
+		pwdhash,
+		expires_at
+	) VALUES ($1, $2, $3, CASE WHEN $4 = '' THEN NULL ELSE crypt($4, gen_salt('bf')) END, $5) RETURNING id;`
+
+	var id uint
+	if err := tx.QueryRowx(query, gallery.Title, gallery.ClientName, gallery.ShareToken, password, gallery.ExpiresAt).Scan(&id); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetProofingGalleries fetches all proofing galleries from the database,
+// newest first. The photos are not included.
+func (db DB) GetProofingGalleries() ([]*entities.ProofingGallery, error) {
+	ret := make([]*entities.ProofingGallery, 0)
+	if err := db.db.Select(&ret, proofingGalleryQuery+"ORDER BY created_at DESC;"); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetProofingGallery fetches a proofing gallery with the given ID, along with
+// its photos and their comments.
+func (db DB) GetProofingGallery(id uint) (*entities.ProofingGallery, error) {
+	var gallery entities.ProofingGallery
+	if err := db.db.Get(&gallery, proofingGalleryQuery+"WHERE id = $1;", id); err != nil {
+		return nil, err
+	}
+
+	if err := db.getProofingPhotos(&gallery); err != nil {
+		return nil, err
+	}
+
+	return &gallery, nil
+}
+
+// GetProofingGalleryByToken fetches a proofing gallery with the given share
+// token, along with its photos and their comments.
+func (db DB) GetProofingGalleryByToken(token string) (*entities.ProofingGallery, error) {
+	var gallery entities.ProofingGallery
+	if err := db.db.Get(&gallery, proofingGalleryQuery+"WHERE share_token = $1;", token); err != nil {
+		return nil, err
+	}
+
+	if err := db.getProofingPhotos(&gallery); err != nil {
+		return nil, err
+	}
+
+	return &gallery, nil
+}
+
+// getProofingPhotos fetches the photos and comments for a proofing gallery.
+func (db DB) getProofingPhotos(gallery *entities.ProofingGallery) error {
+	photos := make([]*entities.ProofingPhoto, 0)
+	query := "SELECT id, file_name, stored_name, favourite FROM proofing_photos WHERE gallery_id = $1 ORDER BY id;"
+	if err := db.db.Select(&photos, query, gallery.ID); err != nil {
+		return err
+	}
+
+	comments := make([]*entities.ProofingComment, 0)
+
+	query = `
+		SELECT proofing_comments.id, proofing_comments.photo_id, proofing_comments.body, proofing_comments.created_at
+		FROM proofing_comments
+		JOIN proofing_photos ON proofing_photos.id = proofing_comments.photo_id
+		WHERE proofing_photos.gallery_id = $1
+		ORDER BY proofing_comments.created_at, proofing_comments.id;
+	`
+
+	if err := db.db.Select(&comments, query, gallery.ID); err != nil {
+		return err
+	}
+
+	byPhoto := make(map[uint]*entities.ProofingPhoto, len(photos))
+	for _, photo := range photos {
+		photo.Comments = make([]*entities.ProofingComment, 0)
+		byPhoto[photo.ID] = photo
+	}
+
+	for _, comment := range comments {
+		if photo, ok := byPhoto[comment.PhotoID]; ok {
+			photo.Comments = append(photo.Comments, comment)
+		}
+	}
+
+	gallery.Photos = photos
+	return nil
+}
+
+// UpdateProofingGallery sets the title, client name, and expiry date of an
+// existing proofing gallery. If there is no gallery with the ID,
+// sql.ErrNoRows is returned.
+func (db DB) UpdateProofingGallery(gallery *entities.ProofingGallery) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		proofing_galleries
+	SET
+		title = $1,
+		client_name = $2,
+		expires_at = $3
+	WHERE
+		id = $4;
+	`
+
+	res := tx.MustExec(query, gallery.Title, gallery.ClientName, gallery.ExpiresAt, gallery.ID)
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// SetProofingPassword sets a new password for a proofing gallery. An empty
+// password removes the password, and any sessions for the gallery are
+// removed either way.
+func (db DB) SetProofingPassword(id uint, password string) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("UPDATE proofing_galleries SET pwdhash = CASE WHEN $1 = '' THEN NULL ELSE crypt($1, gen_salt('bf')) END WHERE id = $2;", password, id)
+	tx.MustExec("DELETE FROM proofing_sessions WHERE gallery_id = $1;", id)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// CheckProofingPassword checks if a password matches the one set for a
+// proofing gallery.
+func (db DB) CheckProofingPassword(id uint, password string) (bool, error) {
+	var ret bool
+	err := db.db.Get(&ret, "SELECT pwdhash = crypt($2, pwdhash) FROM proofing_galleries WHERE id = $1 AND pwdhash IS NOT NULL;", id, password)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	return ret, nil
+}
+
+// RemoveProofingGallery deletes a proofing gallery from the database, along
+// with its photos, comments, and sessions. The image files are not removed.
+func (db DB) RemoveProofingGallery(id uint) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM proofing_galleries WHERE id=$1;", id)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// AddProofingPhotos adds uploaded photos to a proofing gallery. Either every
+// photo is added, or none are.
+func (db DB) AddProofingPhotos(galleryID uint, photos []*entities.ProofingPhoto) error {
+	tx := db.db.MustBegin()
+
+	query := "INSERT INTO proofing_photos (gallery_id, file_name, stored_name) VALUES ($1, $2, $3);"
+	for _, photo := range photos {
+		if _, err := tx.Exec(query, galleryID, photo.FileName, photo.StoredName); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// GetUnstoredProofingPhotos fetches the proofing photos that were added
+// before photos were stored privately, so their files are still in the
+// images directory.
+func (db DB) GetUnstoredProofingPhotos() ([]*entities.ProofingPhoto, error) {
+	ret := make([]*entities.ProofingPhoto, 0)
+	if err := db.db.Select(&ret, "SELECT id, file_name, stored_name, favourite FROM proofing_photos WHERE stored_name = '' ORDER BY id;"); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// SetProofingStoredName sets the name a proofing photo's file is privately
+// stored under.
+func (db DB) SetProofingStoredName(photoID uint, storedName string) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("UPDATE proofing_photos SET stored_name=$1 WHERE id=$2;", storedName, photoID)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// RemoveProofingPhotos removes image files from a proofing gallery, along
+// with their comments.
+func (db DB) RemoveProofingPhotos(galleryID uint, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("DELETE FROM proofing_photos WHERE gallery_id=$1 AND file_name IN (")
+	for i := range files {
+		if i == len(files)-1 {
+			sb.WriteString(fmt.Sprintf("$%d", i+2))
+		} else {
+			sb.WriteString(fmt.Sprintf("$%d,", i+2))
+		}
+	}
+	sb.WriteString(");")
+
+	var args []interface{}
+	args = append(args, galleryID)
+	for _, file := range files {
+		args = append(args, file)
+	}
+
+	tx := db.db.MustBegin()
+	tx.MustExec(sb.String(), args...)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// SetProofingFavourite marks or unmarks a photo in a proofing gallery as a
+// favourite. If the photo isn't in the gallery, sql.ErrNoRows is returned.
+func (db DB) SetProofingFavourite(galleryID, photoID uint, favourite bool) error {
+	tx := db.db.MustBegin()
+
+	res := tx.MustExec("UPDATE proofing_photos SET favourite=$1 WHERE id=$2 AND gallery_id=$3;", favourite, photoID, galleryID)
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// AddProofingComment adds a comment to a photo in a proofing gallery,
+// returning the new comment. If the photo isn't in the gallery, sql.ErrNoRows
+// is returned.
+func (db DB) AddProofingComment(galleryID, photoID uint, body string) (*entities.ProofingComment, error) {
+	tx := db.db.MustBegin()
+
+	query := `
+		INSERT INTO proofing_comments (photo_id, body)
+		SELECT id, $3 FROM proofing_photos WHERE id = $1 AND gallery_id = $2
+		RETURNING id, photo_id, body, created_at;
+	`
+
+	var comment entities.ProofingComment
+	if err := tx.Get(&comment, query, photoID, galleryID, body); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// AddProofingSession saves a proofing gallery session in the database.
+func (db DB) AddProofingSession(session *entities.ProofingSession) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("INSERT INTO proofing_sessions (token, gallery_id, created, max_age) VALUES ($1, $2, $3, $4);", session.Token, session.GalleryID, session.Created, session.MaxAge)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// GetProofingSession fetches a proofing gallery session from the database. If
+// there is no session with the token, sql.ErrNoRows is returned.
+func (db DB) GetProofingSession(token string) (*entities.ProofingSession, error) {
+	var session entities.ProofingSession
+	if err := db.db.Get(&session, "SELECT token, gallery_id, created, max_age FROM proofing_sessions WHERE token = $1;", token); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// RemoveProofingSession deletes a proofing gallery session from the database.
+func (db DB) RemoveProofingSession(token string) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM proofing_sessions WHERE token=$1;", token)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package database
+
+import "github.com/nicolekellydesign/webby-api/entities"
+
+// GetSettings fetches the site-wide settings from the database.
+func (db DB) GetSettings() (*entities.Settings, error) {
+	var ret entities.Settings
+
+	query := `
+	SELECT
+		site_title, meta_description, share_image, primary_color, secondary_color,
+		analytics_enabled, footer_text, notification_email, robots_txt, updated_at
+	FROM
+		settings
+	WHERE
+		id = 1;
+	`
+
+	if err := db.db.Get(&ret, query); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// UpdateSettings saves new site-wide settings to the database.
+func (db DB) UpdateSettings(settings *entities.Settings) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		settings
+	SET
+		site_title = $1,
+		meta_description = $2,
+		share_image = $3,
+		primary_color = $4,
+		secondary_color = $5,
+		analytics_enabled = $6,
+		footer_text = $7,
+		notification_email = $8,
+		robots_txt = $9,
+		updated_at = NOW()
+	WHERE
+		id = 1;
+	`
+
+	tx.MustExec(query, settings.SiteTitle, settings.MetaDescription, settings.ShareImage, settings.PrimaryColor,
+		settings.SecondaryColor, settings.AnalyticsEnabled, settings.FooterText, settings.NotificationEmail,
+		settings.RobotsTxt)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
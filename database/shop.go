@@ -0,0 +1,529 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+var (
+	// ErrNotForSale is returned when an order has a print that isn't in the
+	// shop.
+	ErrNotForSale = errors.New("print is not for sale")
+
+	// ErrOutOfStock is returned when there aren't enough of a print left for
+	// an order.
+	ErrOutOfStock = errors.New("print is out of stock")
+)
+
+// printProductQuery selects print products along with the file name of their
+// photo, and whether it's watermarked.
+const printProductQuery = `
+	SELECT
+		print_products.id, print_products.photo_id, photos.file_name AS photo,
+		photos.watermark AS photo_watermark, print_products.title,
+		print_products.description, print_products.edition_size, print_products.sold,
+		print_products.published, print_products.position, print_products.created_at
+	FROM print_products
+	JOIN photos ON photos.id = print_products.photo_id
+`
+
+// printVariantColumns are the columns selected for print variants.
+const printVariantColumns = "id, product_id, size, paper, price, stock, position"
+
+// orderColumns are the columns selected for orders.
+const orderColumns = `id, reference, status, name, email, address, total, currency, payment_provider,
+	payment_id, created_at, updated_at`
+
+// AddPrintProduct inserts a new print product and its variants into the
+// database, returning the new product's ID.
+func (db DB) AddPrintProduct(product *entities.PrintProduct) (uint, error) {
+	tx := db.db.MustBegin()
+
+	query := `INSERT INTO print_products (
+		photo_id,
+		title,
+		description,
+		edition_size,
+		published,
+		position
+	) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;`
+
+	var id uint
+	if err := tx.QueryRowx(query, product.PhotoID, product.Title, product.Description, product.EditionSize,
+		product.Published, product.Position).Scan(&id); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	for _, variant := range product.Variants {
+		variant.ProductID = id
+		if _, err := addPrintVariant(tx, variant); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetPrintProducts fetches print products from the database along with their
+// variants, in display order. Unpublished products are only fetched if asked
+// for.
+func (db DB) GetPrintProducts(unpublished bool) ([]*entities.PrintProduct, error) {
+	ret := make([]*entities.PrintProduct, 0)
+
+	query := printProductQuery + "WHERE $1 OR print_products.published ORDER BY print_products.position, print_products.id;"
+	if err := db.db.Select(&ret, query, unpublished); err != nil {
+		return nil, err
+	}
+
+	variants := make([]*entities.PrintVariant, 0)
+	if err := db.db.Select(&variants, "SELECT "+printVariantColumns+" FROM print_variants ORDER BY position, id;"); err != nil {
+		return nil, err
+	}
+
+	products := make(map[uint]*entities.PrintProduct, len(ret))
+	for _, product := range ret {
+		product.Variants = make([]*entities.PrintVariant, 0)
+		products[product.ID] = product
+	}
+
+	for _, variant := range variants {
+		if product, ok := products[variant.ProductID]; ok {
+			product.Variants = append(product.Variants, variant)
+		}
+	}
+
+	return ret, nil
+}
+
+// GetPrintProduct fetches the print product with the given ID from the
+// database, along with its variants.
+func (db DB) GetPrintProduct(id uint) (*entities.PrintProduct, error) {
+	var ret entities.PrintProduct
+	if err := db.db.Get(&ret, printProductQuery+"WHERE print_products.id = $1;", id); err != nil {
+		return nil, err
+	}
+
+	ret.Variants = make([]*entities.PrintVariant, 0)
+
+	query := "SELECT " + printVariantColumns + " FROM print_variants WHERE product_id = $1 ORDER BY position, id;"
+	if err := db.db.Select(&ret.Variants, query, id); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// UpdatePrintProduct changes the details of a print product. Its variants and
+// how many prints have been sold aren't changed.
+func (db DB) UpdatePrintProduct(product *entities.PrintProduct) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		print_products
+	SET
+		photo_id = $1,
+		title = $2,
+		description = $3,
+		edition_size = $4,
+		published = $5,
+		position = $6
+	WHERE
+		id = $7;
+	`
+
+	res, err := tx.Exec(query, product.PhotoID, product.Title, product.Description, product.EditionSize,
+		product.Published, product.Position, product.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// RemovePrintProduct deletes a print product and its variants from the
+// database. Orders for it are kept.
+func (db DB) RemovePrintProduct(id uint) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM print_products WHERE id = $1;", id)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// AddPrintVariant inserts a new variant of a print product into the database,
+// returning the new variant's ID.
+func (db DB) AddPrintVariant(variant *entities.PrintVariant) (uint, error) {
+	tx := db.db.MustBegin()
+
+	id, err := addPrintVariant(tx, variant)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// addPrintVariant inserts a variant of a print product as part of a
+// transaction.
+func addPrintVariant(tx *sqlx.Tx, variant *entities.PrintVariant) (uint, error) {
+	query := `INSERT INTO print_variants (
+		product_id,
+		size,
+		paper,
+		price,
+		stock,
+		position
+	) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;`
+
+	var id uint
+	err := tx.QueryRowx(query, variant.ProductID, variant.Size, variant.Paper, variant.Price, variant.Stock, variant.Position).Scan(&id)
+
+	return id, err
+}
+
+// UpdatePrintVariant changes a variant of a print product. If the product has
+// no variant with the ID, sql.ErrNoRows is returned.
+func (db DB) UpdatePrintVariant(variant *entities.PrintVariant) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		print_variants
+	SET
+		size = $1,
+		paper = $2,
+		price = $3,
+		stock = $4,
+		position = $5
+	WHERE
+		id = $6 AND product_id = $7;
+	`
+
+	res, err := tx.Exec(query, variant.Size, variant.Paper, variant.Price, variant.Stock, variant.Position, variant.ID, variant.ProductID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// RemovePrintVariant deletes a variant of a print product from the database.
+// Orders for it are kept.
+func (db DB) RemovePrintVariant(productID, id uint) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM print_variants WHERE id = $1 AND product_id = $2;", id, productID)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// AddOrder inserts a new pending order into the database, returning the new
+// order's ID. Each item only needs its variant ID and quantity; the rest of
+// the item and the order total are filled in from the variants. Stock and
+// edition prints are held for the order straight away, so two orders can't
+// buy the last print. If a variant isn't in a published product,
+// ErrNotForSale is returned, and if there aren't enough prints left,
+// ErrOutOfStock is.
+func (db DB) AddOrder(order *entities.Order) (uint, error) {
+	tx := db.db.MustBegin()
+
+	variantIDs := make([]int32, 0, len(order.Items))
+	for _, item := range order.Items {
+		variantIDs = append(variantIDs, item.VariantID.Int32)
+	}
+
+	// Lock the products in a fixed order so that orders for the same prints
+	// wait for each other instead of deadlocking
+	query, args, err := sqlx.In(`
+	SELECT
+		id
+	FROM
+		print_products
+	WHERE
+		id IN (SELECT product_id FROM print_variants WHERE id IN (?))
+	ORDER BY
+		id
+	FOR UPDATE;
+	`, variantIDs)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	var locked []uint
+	if err := tx.Select(&locked, tx.Rebind(query), args...); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	query = `
+	SELECT
+		print_variants.product_id, print_variants.size, print_variants.paper, print_variants.price,
+		print_variants.stock, print_products.title, print_products.edition_size, print_products.sold,
+		print_products.published
+	FROM print_variants
+	JOIN print_products ON print_products.id = print_variants.product_id
+	WHERE print_variants.id = $1
+	FOR UPDATE OF print_variants;
+	`
+
+	order.Total = 0
+	for _, item := range order.Items {
+		var row struct {
+			entities.PrintVariant
+			Title       string
+			EditionSize sql.NullInt32 `db:"edition_size"`
+			Sold        int
+			Published   bool
+		}
+
+		if err := tx.Get(&row, query, item.VariantID); err != nil {
+			tx.Rollback()
+			if err == sql.ErrNoRows {
+				return 0, ErrNotForSale
+			}
+
+			return 0, err
+		}
+
+		if !row.Published {
+			tx.Rollback()
+			return 0, ErrNotForSale
+		}
+
+		if (row.Stock != nil && *row.Stock < item.Quantity) ||
+			(row.EditionSize.Valid && row.Sold+item.Quantity > int(row.EditionSize.Int32)) {
+			tx.Rollback()
+			return 0, ErrOutOfStock
+		}
+
+		tx.MustExec("UPDATE print_variants SET stock = stock - $1 WHERE id = $2 AND stock IS NOT NULL;", item.Quantity, item.VariantID)
+		tx.MustExec("UPDATE print_products SET sold = sold + $1 WHERE id = $2;", item.Quantity, row.ProductID)
+
+		item.ProductID.Int32 = int32(row.ProductID)
+		item.ProductID.Valid = true
+		item.Title = row.Title
+		item.Size = row.Size
+		item.Paper = row.Paper
+		item.UnitPrice = row.Price
+		order.Total += row.Price * item.Quantity
+	}
+
+	query = `INSERT INTO orders (
+		reference,
+		status,
+		name,
+		email,
+		address,
+		total,
+		currency,
+		payment_provider
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;`
+
+	var id uint
+	if err := tx.QueryRowx(query, order.Reference, entities.OrderPending, order.Name, order.Email, order.Address,
+		order.Total, order.Currency, order.PaymentProvider).Scan(&id); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	query = `INSERT INTO order_items (
+		order_id,
+		product_id,
+		variant_id,
+		title,
+		size,
+		paper,
+		unit_price,
+		quantity
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
+
+	for _, item := range order.Items {
+		if _, err := tx.Exec(query, id, item.ProductID, item.VariantID, item.Title, item.Size, item.Paper,
+			item.UnitPrice, item.Quantity); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// SetOrderPayment records the ID that the payment provider gave the payment
+// for an order.
+func (db DB) SetOrderPayment(id uint, paymentID string) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("UPDATE orders SET payment_id = $1, updated_at = NOW() WHERE id = $2;", paymentID, id)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// GetStaleOrders fetches the IDs of pending orders that were made before the
+// given time, oldest first.
+func (db DB) GetStaleOrders(before time.Time) ([]uint, error) {
+	ret := make([]uint, 0)
+
+	query := "SELECT id FROM orders WHERE status = $1 AND created_at < $2 ORDER BY created_at, id;"
+	if err := db.db.Select(&ret, query, entities.OrderPending, before); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetOrders fetches orders from the database, newest first. If a status is
+// given, only orders in that state are fetched. Their items aren't included.
+func (db DB) GetOrders(status string) ([]*entities.Order, error) {
+	ret := make([]*entities.Order, 0)
+
+	query := "SELECT " + orderColumns + " FROM orders WHERE ($1 = '' OR status = $1) ORDER BY created_at DESC, id DESC;"
+	if err := db.db.Select(&ret, query, status); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetOrder fetches the order with the given ID from the database, along with
+// its items.
+func (db DB) GetOrder(id uint) (*entities.Order, error) {
+	return db.getOrder("id = $1", id)
+}
+
+// GetOrderByReference fetches the order with the given reference from the
+// database, along with its items.
+func (db DB) GetOrderByReference(reference string) (*entities.Order, error) {
+	return db.getOrder("reference = $1", reference)
+}
+
+// getOrder fetches the order that matches a condition, along with its items.
+func (db DB) getOrder(where string, arg interface{}) (*entities.Order, error) {
+	var ret entities.Order
+	if err := db.db.Get(&ret, "SELECT "+orderColumns+" FROM orders WHERE "+where+";", arg); err != nil {
+		return nil, err
+	}
+
+	ret.Items = make([]*entities.OrderItem, 0)
+
+	query := "SELECT id, product_id, variant_id, title, size, paper, unit_price, quantity FROM order_items WHERE order_id = $1 ORDER BY id;"
+	if err := db.db.Select(&ret.Items, query, ret.ID); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// SetOrderStatus moves an order to a new state, as long as it's in one of the
+// states it can be moved from. If it isn't, sql.ErrNoRows is returned.
+// Cancelling an order puts the prints held for it back in stock.
+func (db DB) SetOrderStatus(id uint, status string, from ...string) error {
+	tx := db.db.MustBegin()
+
+	query, args, err := sqlx.In("UPDATE orders SET status = ?, updated_at = NOW() WHERE id = ? AND status IN (?);", status, id, from)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	res, err := tx.Exec(tx.Rebind(query), args...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if status == entities.OrderCancelled {
+		query = `
+		UPDATE
+			print_variants
+		SET
+			stock = stock + order_items.quantity
+		FROM order_items
+		WHERE
+			order_items.variant_id = print_variants.id AND order_items.order_id = $1 AND print_variants.stock IS NOT NULL;
+		`
+		tx.MustExec(query, id)
+
+		query = `
+		UPDATE
+			print_products
+		SET
+			sold = sold - items.quantity
+		FROM (
+			SELECT product_id, SUM(quantity) AS quantity FROM order_items WHERE order_id = $1 GROUP BY product_id
+		) AS items
+		WHERE
+			items.product_id = print_products.id;
+		`
+		tx.MustExec(query, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
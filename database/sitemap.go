@@ -0,0 +1,46 @@
+package database
+
+import "github.com/nicolekellydesign/webby-api/entities"
+
+// sitemapQuery selects every public page that's listed in the sitemap. Projects
+// and pages that search engines are told not to index are left out, and posts
+// only count as changed once they're published.
+const sitemapQuery = `
+	SELECT 'project' AS kind, id AS slug, updated_at FROM gallery_items WHERE published AND NOT no_index
+	UNION ALL
+	SELECT 'page', slug, updated_at FROM pages WHERE published AND NOT no_index
+	UNION ALL
+	SELECT 'album', slug, updated_at FROM albums
+	UNION ALL
+	SELECT 'post', slug, GREATEST(updated_at, published_at) FROM posts WHERE published AND published_at <= NOW()
+`
+
+// GetSitemapEntries fetches every public page that should be listed in the
+// sitemap, grouped by kind.
+func (db DB) GetSitemapEntries() ([]*entities.SitemapEntry, error) {
+	ret := make([]*entities.SitemapEntry, 0)
+	if err := db.db.Select(&ret, "SELECT kind, slug, updated_at FROM ("+sitemapQuery+") AS entries ORDER BY kind, slug;"); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetSitemapVersion fetches a hash of every entry in the sitemap. It changes
+// whenever a page is added, changed, removed, hidden from search engines, or
+// goes live on a schedule, so it's used to tell when the sitemap has to be
+// made again without sending every page back.
+func (db DB) GetSitemapVersion() (string, error) {
+	var ret string
+
+	query := `
+		SELECT MD5(COALESCE(STRING_AGG(kind || ' ' || slug || ' ' || updated_at::TEXT, E'\n' ORDER BY kind, slug), ''))
+		FROM (` + sitemapQuery + `) AS entries;
+	`
+
+	if err := db.db.Get(&ret, query); err != nil {
+		return "", err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// subscriberColumns are the columns selected for newsletter subscribers.
+const subscriberColumns = "id, email, status, created_at, confirmed_at, unsubscribed_at"
+
+// AddSubscriber adds a pending newsletter subscriber to the database. Someone
+// who unsubscribed before is made pending again. It returns the subscriber's
+// status before the change, which is confirmed if they were already
+// subscribed; confirmed subscribers are left as they are.
+func (db DB) AddSubscriber(email string) (string, error) {
+	tx := db.db.MustBegin()
+
+	var status string
+	err := tx.Get(&status, "SELECT status FROM subscribers WHERE email = $1 FOR UPDATE;", email)
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		return "", err
+	}
+
+	switch {
+	case err == sql.ErrNoRows:
+		status = ""
+		if _, err := tx.Exec("INSERT INTO subscribers (email) VALUES ($1) ON CONFLICT (email) DO NOTHING;", email); err != nil {
+			tx.Rollback()
+			return "", err
+		}
+	case status == entities.SubscriberUnsubscribed:
+		tx.MustExec("UPDATE subscribers SET status = $1, unsubscribed_at = NULL WHERE email = $2;", entities.SubscriberPending, email)
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	return status, nil
+}
+
+// GetSubscribers fetches newsletter subscribers from the database, oldest
+// first. If a status is given, only subscribers in that state are fetched.
+func (db DB) GetSubscribers(status string) ([]*entities.Subscriber, error) {
+	ret := make([]*entities.Subscriber, 0)
+
+	query := "SELECT " + subscriberColumns + " FROM subscribers WHERE ($1 = '' OR status = $1) ORDER BY created_at, id;"
+	if err := db.db.Select(&ret, query, status); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// ConfirmSubscriber confirms the email address of a pending newsletter
+// subscriber. Confirming an address twice does nothing. If there's no pending
+// or confirmed subscriber with the address, sql.ErrNoRows is returned.
+func (db DB) ConfirmSubscriber(email string) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		subscribers
+	SET
+		status = $1,
+		confirmed_at = COALESCE(confirmed_at, NOW())
+	WHERE
+		email = $2 AND status IN ($3, $1);
+	`
+
+	res := tx.MustExec(query, entities.SubscriberConfirmed, email, entities.SubscriberPending)
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// UnsubscribeSubscriber marks a newsletter subscriber as unsubscribed. The
+// subscriber is kept, so that it's clear they asked not to get email.
+func (db DB) UnsubscribeSubscriber(email string) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		subscribers
+	SET
+		status = $1,
+		unsubscribed_at = NOW()
+	WHERE
+		email = $2 AND status != $1;
+	`
+
+	tx.MustExec(query, entities.SubscriberUnsubscribed, email)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// RemoveSubscriber deletes a newsletter subscriber from the database.
+func (db DB) RemoveSubscriber(id uint) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM subscribers WHERE id = $1;", id)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
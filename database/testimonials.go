@@ -0,0 +1,138 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// TestimonialFilter narrows down the testimonials fetched from the database.
+// Unless Drafts is set, links to unpublished projects are left out, and
+// filtering by an unpublished project finds nothing.
+type TestimonialFilter struct {
+	Drafts   bool
+	Project  string
+	Featured bool
+}
+
+// AddTestimonial inserts a new testimonial into the database, returning the
+// new testimonial's ID.
+func (db DB) AddTestimonial(testimonial *entities.Testimonial) (uint, error) {
+	tx := db.db.MustBegin()
+
+	query := `INSERT INTO testimonials (
+		quote,
+		name,
+		role,
+		company,
+		avatar,
+		project_id,
+		featured,
+		position
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;`
+
+	var id uint
+	if err := tx.QueryRowx(query, testimonial.Quote, testimonial.Name, testimonial.Role, testimonial.Company,
+		testimonial.Avatar, testimonial.Project, testimonial.Featured, testimonial.Position).Scan(&id); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetTestimonials fetches testimonials from the database in display order.
+func (db DB) GetTestimonials(filter TestimonialFilter) ([]*entities.Testimonial, error) {
+	ret := make([]*entities.Testimonial, 0)
+
+	query := `
+	SELECT
+		testimonials.id, testimonials.quote, testimonials.name, testimonials.role, testimonials.company,
+		testimonials.avatar, testimonials.featured, testimonials.position, testimonials.created_at,
+		CASE WHEN $1 OR gallery_items.published THEN testimonials.project_id END AS project_id
+	FROM testimonials
+	LEFT JOIN gallery_items ON gallery_items.id = testimonials.project_id
+	WHERE
+		($2 = '' OR (testimonials.project_id = $2 AND ($1 OR gallery_items.published)))
+		AND (NOT $3 OR testimonials.featured)
+	ORDER BY testimonials.position, testimonials.id;
+	`
+
+	if err := db.db.Select(&ret, query, filter.Drafts, filter.Project, filter.Featured); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetTestimonial fetches the testimonial with the given ID from the database.
+func (db DB) GetTestimonial(id uint) (*entities.Testimonial, error) {
+	var ret entities.Testimonial
+
+	query := `
+	SELECT
+		id, quote, name, role, company, avatar, project_id, featured, position, created_at
+	FROM testimonials
+	WHERE id = $1;
+	`
+
+	if err := db.db.Get(&ret, query, id); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// UpdateTestimonial changes an existing testimonial. If there is no
+// testimonial with the ID, sql.ErrNoRows is returned.
+func (db DB) UpdateTestimonial(testimonial *entities.Testimonial) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		testimonials
+	SET
+		quote = $1,
+		name = $2,
+		role = $3,
+		company = $4,
+		avatar = $5,
+		project_id = $6,
+		featured = $7,
+		position = $8
+	WHERE
+		id = $9;
+	`
+
+	res := tx.MustExec(query, testimonial.Quote, testimonial.Name, testimonial.Role, testimonial.Company,
+		testimonial.Avatar, testimonial.Project, testimonial.Featured, testimonial.Position, testimonial.ID)
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// RemoveTestimonial deletes a testimonial from the database.
+func (db DB) RemoveTestimonial(id uint) error {
+	tx := db.db.MustBegin()
+	tx.MustExec("DELETE FROM testimonials WHERE id = $1;", id)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
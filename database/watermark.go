@@ -0,0 +1,44 @@
+package database
+
+import "github.com/nicolekellydesign/webby-api/entities"
+
+// GetWatermark fetches the watermark settings from the database.
+func (db DB) GetWatermark() (*entities.Watermark, error) {
+	var ret entities.Watermark
+
+	query := "SELECT enabled, image, text, position, opacity, scale, updated_at FROM watermark WHERE id = 1;"
+	if err := db.db.Get(&ret, query); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// UpdateWatermark saves new watermark settings to the database.
+func (db DB) UpdateWatermark(watermark *entities.Watermark) error {
+	tx := db.db.MustBegin()
+
+	query := `
+	UPDATE
+		watermark
+	SET
+		enabled = $1,
+		image = $2,
+		text = $3,
+		position = $4,
+		opacity = $5,
+		scale = $6,
+		updated_at = NOW()
+	WHERE
+		id = 1;
+	`
+
+	tx.MustExec(query, watermark.Enabled, watermark.Image, watermark.Text, watermark.Position, watermark.Opacity, watermark.Scale)
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
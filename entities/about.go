@@ -1,28 +1,62 @@
 package entities
 
+import "github.com/nicolekellydesign/webby-api/internal/db"
+
+// Platforms that a social link can point to. Links to anywhere else use
+// SocialWebsite or SocialOther.
+const (
+	SocialBehance   = "behance"
+	SocialDribbble  = "dribbble"
+	SocialFacebook  = "facebook"
+	SocialGitHub    = "github"
+	SocialInstagram = "instagram"
+	SocialLinkedIn  = "linkedin"
+	SocialPinterest = "pinterest"
+	SocialTwitter   = "twitter"
+	SocialVimeo     = "vimeo"
+	SocialYouTube   = "youtube"
+	SocialWebsite   = "website"
+	SocialOther     = "other"
+)
+
 // About holds information for the about page.
 type About struct {
-	Portrait  string `json:"portrait,omitempty"`
-	Statement string `json:"statement,omitempty"`
-	Resume    string `json:"resume,omitempty"`
+	Portrait     string          `json:"portrait,omitempty" db:"portrait"`
+	Statement    string          `json:"statement,omitempty" db:"statement"`
+	Resume       string          `json:"resume,omitempty" db:"resume"`
+	ContactEmail string          `json:"contactEmail,omitempty" db:"contact_email"`
+	Location     string          `json:"location,omitempty" db:"location"`
+	Sections     []*AboutSection `json:"sections"`
+	SocialLinks  []*SocialLink   `json:"socialLinks"`
+	Clients      []*AboutClient  `json:"clients"`
+	Awards       []*Award        `json:"awards"`
 }
 
-// MergeAbout combines two About structs, returning a new struct with the merged
-// values.
-func MergeAbout(left, right About) *About {
-	ret := left
-
-	if right.Portrait != "" {
-		ret.Portrait = right.Portrait
-	}
+// AboutSection is a free-form section of the about page, with a Markdown
+// body.
+type AboutSection struct {
+	Title string `json:"title" db:"title"`
+	Body  string `json:"body" db:"body"`
+}
 
-	if right.Statement != "" {
-		ret.Statement = right.Statement
-	}
+// SocialLink is a link to a social media or portfolio profile.
+type SocialLink struct {
+	Platform string `json:"platform" db:"platform"`
+	URL      string `json:"url" db:"url"`
+	Label    string `json:"label,omitempty" db:"label"`
+}
 
-	if right.Resume != "" {
-		ret.Resume = right.Resume
-	}
+// AboutClient is a client in the about page's list of selected clients.
+type AboutClient struct {
+	Name string        `json:"name" db:"name"`
+	URL  db.NullString `json:"url,omitempty" db:"url"`
+}
 
-	return &ret
+// Award is an award or other recognition listed on the about page.
+type Award struct {
+	Title       string        `json:"title" db:"title"`
+	Issuer      string        `json:"issuer,omitempty" db:"issuer"`
+	Year        db.NullInt    `json:"year,omitempty" db:"year"`
+	URL         db.NullString `json:"url,omitempty" db:"url"`
+	Description string        `json:"description,omitempty" db:"description"`
 }
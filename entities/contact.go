@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// ContactMessage is a message sent through the contact form.
+type ContactMessage struct {
+	ID        uint      `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Email     string    `json:"email" db:"email"`
+	Subject   string    `json:"subject" db:"subject"`
+	Message   string    `json:"message" db:"message"`
+	Read      bool      `json:"read" db:"read"`
+	Archived  bool      `json:"archived" db:"archived"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
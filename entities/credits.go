@@ -0,0 +1,26 @@
+package entities
+
+import "github.com/nicolekellydesign/webby-api/internal/db"
+
+// Kinds of contributors that can be credited on a project.
+const (
+	ContributorPerson       = "person"
+	ContributorOrganisation = "organisation"
+)
+
+// Contributor is a person or organisation that can be credited on projects.
+type Contributor struct {
+	ID   uint          `json:"id" db:"id"`
+	Name string        `json:"name" db:"name"`
+	Kind string        `json:"kind" db:"kind"`
+	URL  db.NullString `json:"url,omitempty" db:"url"`
+}
+
+// Credit links a contributor to a project with the role they had on it.
+type Credit struct {
+	ContributorID uint          `json:"contributorId" db:"contributor_id"`
+	Name          string        `json:"name" db:"name"`
+	Kind          string        `json:"kind" db:"kind"`
+	URL           db.NullString `json:"url,omitempty" db:"url"`
+	Role          string        `json:"role" db:"role"`
+}
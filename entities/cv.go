@@ -0,0 +1,71 @@
+package entities
+
+import "time"
+
+// CV is the designer's structured résumé. Dates are ISO 8601 dates that can
+// leave out the day or the month, like "2021", "2021-06", or "2021-06-15".
+type CV struct {
+	Name         string           `json:"name"`
+	Headline     string           `json:"headline,omitempty"`
+	Email        string           `json:"email,omitempty"`
+	Website      string           `json:"website,omitempty"`
+	Location     string           `json:"location,omitempty"`
+	Summary      string           `json:"summary,omitempty"`
+	Experience   []*CVExperience  `json:"experience"`
+	Education    []*CVEducation   `json:"education"`
+	Skills       []*CVSkill       `json:"skills"`
+	Exhibitions  []*CVExhibition  `json:"exhibitions"`
+	Publications []*CVPublication `json:"publications"`
+	UpdatedAt    time.Time        `json:"updatedAt"`
+}
+
+// CVExperience is a job or other role on a CV. An empty end date means the
+// role is current.
+type CVExperience struct {
+	Organisation string   `json:"organisation"`
+	Position     string   `json:"position"`
+	Location     string   `json:"location,omitempty"`
+	URL          string   `json:"url,omitempty"`
+	StartDate    string   `json:"startDate"`
+	EndDate      string   `json:"endDate,omitempty"`
+	Summary      string   `json:"summary,omitempty"`
+	Highlights   []string `json:"highlights,omitempty"`
+}
+
+// CVEducation is a course of study on a CV.
+type CVEducation struct {
+	Institution string `json:"institution"`
+	Area        string `json:"area,omitempty"`
+	StudyType   string `json:"studyType,omitempty"`
+	URL         string `json:"url,omitempty"`
+	StartDate   string `json:"startDate,omitempty"`
+	EndDate     string `json:"endDate,omitempty"`
+}
+
+// CVSkill is a skill on a CV, along with related keywords.
+type CVSkill struct {
+	Name     string   `json:"name"`
+	Level    string   `json:"level,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// CVExhibition is an exhibition on a CV that the designer's work was shown
+// in.
+type CVExhibition struct {
+	Title       string `json:"title"`
+	Venue       string `json:"venue,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Date        string `json:"date"`
+	URL         string `json:"url,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// CVPublication is a publication on a CV that the designer wrote or was
+// featured in.
+type CVPublication struct {
+	Name        string `json:"name"`
+	Publisher   string `json:"publisher,omitempty"`
+	ReleaseDate string `json:"releaseDate,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+}
@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// Download directories that signed download links can point into.
+const (
+	DownloadImages    = "images"
+	DownloadResources = "resources"
+)
+
+// DownloadLink is a signed, expiring link to download an original file. A
+// link can be limited to a number of downloads, and can be revoked before it
+// expires.
+type DownloadLink struct {
+	ID           uint       `json:"id" db:"id"`
+	Directory    string     `json:"directory" db:"directory"`
+	FileName     string     `json:"fileName" db:"file_name"`
+	MaxDownloads db.NullInt `json:"maxDownloads" db:"max_downloads"`
+	Downloads    int        `json:"downloads" db:"downloads"`
+	Requests     int        `json:"requests" db:"requests"`
+	ExpiresAt    time.Time  `json:"expiresAt" db:"expires_at"`
+	Revoked      bool       `json:"revoked" db:"revoked"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	URL          string     `json:"url,omitempty" db:"-"`
+}
@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// Kinds of project that a commission enquiry can be for.
+const (
+	EnquiryBranding     = "branding"
+	EnquiryIllustration = "illustration"
+	EnquiryPhotography  = "photography"
+	EnquiryPrint        = "print"
+	EnquiryWeb          = "web"
+	EnquiryOther        = "other"
+)
+
+// Enquiry is a commission enquiry sent by a client. The budget is a range in
+// whole units of currency, and either end of it can be left open.
+type Enquiry struct {
+	ID              uint                 `json:"id" db:"id"`
+	Name            string               `json:"name" db:"name"`
+	Email           string               `json:"email" db:"email"`
+	ProjectType     string               `json:"projectType" db:"project_type"`
+	BudgetMin       db.NullInt           `json:"budgetMin" db:"budget_min"`
+	BudgetMax       db.NullInt           `json:"budgetMax" db:"budget_max"`
+	Deadline        db.NullTime          `json:"deadline" db:"deadline"`
+	Description     string               `json:"description" db:"description"`
+	AttachmentCount int                  `json:"attachmentCount" db:"attachment_count"`
+	Attachments     []*EnquiryAttachment `json:"attachments,omitempty"`
+	CreatedAt       time.Time            `json:"createdAt" db:"created_at"`
+}
+
+// EnquiryAttachment is a file sent along with a commission enquiry, like a
+// reference image. Attachments are stored under a random name, away from the
+// public files.
+type EnquiryAttachment struct {
+	ID          uint   `json:"id" db:"id"`
+	FileName    string `json:"fileName" db:"file_name"`
+	StoredName  string `json:"-" db:"stored_name"`
+	ContentType string `json:"contentType" db:"content_type"`
+	Size        int64  `json:"size" db:"size"`
+}
@@ -1,14 +1,71 @@
 package entities
 
-import "github.com/nicolekellydesign/webby-api/internal/db"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
 
 // GalleryItem represents an item in the main project gallery.
 type GalleryItem struct {
-	Name        string        `json:"name" db:"id"`
-	Title       string        `json:"title" db:"title"`
-	Caption     string        `json:"caption" db:"caption"`
-	ProjectInfo string        `json:"projectInfo" db:"project_info"`
-	Thumbnail   string        `json:"thumbnail" db:"thumbnail"`
-	VideoKey    db.NullString `json:"videoKey,omitempty" db:"video_key"`
-	Images      []string      `json:"images"`
+	Name         string         `json:"name" db:"id"`
+	Title        string         `json:"title" db:"title"`
+	Caption      string         `json:"caption" db:"caption"`
+	ProjectInfo  string         `json:"projectInfo" db:"project_info"`
+	Thumbnail    string         `json:"thumbnail" db:"thumbnail"`
+	VideoKey     db.NullString  `json:"videoKey,omitempty" db:"video_key"`
+	Published    bool           `json:"published" db:"published"`
+	YearStart    db.NullInt     `json:"yearStart" db:"year_start"`
+	YearEnd      db.NullInt     `json:"yearEnd" db:"year_end"`
+	Client       db.NullString  `json:"client,omitempty" db:"client"`
+	Services     db.StringList  `json:"services" db:"services"`
+	Links        Links          `json:"links" db:"links"`
+	LiveURL      db.NullString  `json:"liveUrl,omitempty" db:"live_url"`
+	Images       []string       `json:"images"`
+	Credits      []*Credit      `json:"credits,omitempty"`
+	Testimonials []*Testimonial `json:"testimonials,omitempty"`
+	UpdatedAt    time.Time      `json:"updatedAt" db:"updated_at"`
+
+	// SEO overrides. Anything left empty falls back to the project's own
+	// fields, then to the site settings.
+	MetaTitle       string        `json:"metaTitle,omitempty" db:"meta_title"`
+	MetaDescription string        `json:"metaDescription,omitempty" db:"meta_description"`
+	ShareImage      db.NullString `json:"shareImage,omitempty" db:"share_image"`
+	CanonicalURL    db.NullString `json:"canonicalUrl,omitempty" db:"canonical_url"`
+	NoIndex         bool          `json:"noIndex" db:"no_index"`
+}
+
+// Link is an external link with a label to show for it.
+type Link struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// Links is a list of links that is stored as a JSON array.
+type Links []Link
+
+// MarshalJSON implements the JSON marshal interface for Links. A nil list is
+// marshalled as an empty array.
+func (l Links) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal([]Link(l))
+}
+
+// Scan implements the Scanner interface for Links.
+func (l *Links) Scan(value interface{}) error {
+	return db.ScanJSON(value, l)
+}
+
+// Value implements the driver Valuer interface for Links.
+func (l Links) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+
+	return db.JSONValue([]Link(l))
 }
@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// Places an image can be used on the site.
+const (
+	ImageUsePhoto     = "photo"
+	ImageUseProject   = "project"
+	ImageUseThumbnail = "thumbnail"
+)
+
+// ImageHash is the perceptual hash of an image file, along with the size and
+// modification time of the file when it was hashed.
+type ImageHash struct {
+	FileName string    `db:"file_name"`
+	Hash     int64     `db:"hash"`
+	Size     int64     `db:"size"`
+	ModTime  time.Time `db:"mod_time"`
+}
+
+// ImageUse is a place where an image file is used on the site. Project is set
+// for project images and thumbnails.
+type ImageUse struct {
+	FileName string        `json:"fileName" db:"file_name"`
+	Kind     string        `json:"kind" db:"kind"`
+	Project  db.NullString `json:"project,omitempty" db:"project"`
+}
+
+// DuplicateImage is an image that looks like one or more images that are
+// already stored.
+type DuplicateImage struct {
+	FileName string   `json:"fileName"`
+	Matches  []string `json:"matches"`
+}
@@ -0,0 +1,26 @@
+package entities
+
+// Menu locations on the site.
+const (
+	MenuHeader = "header"
+	MenuFooter = "footer"
+)
+
+// Kinds of link that a menu item can have.
+const (
+	MenuLinkProject = "project"
+	MenuLinkPage    = "page"
+	MenuLinkAlbum   = "album"
+	MenuLinkURL     = "url"
+)
+
+// MenuItem is an item in one of the site's menus. The target is the name of a
+// project, the slug of a page or album, or an external URL, depending on the
+// link type. Items can have their own items nested under them.
+type MenuItem struct {
+	Label    string      `json:"label" db:"label"`
+	LinkType string      `json:"type" db:"link_type"`
+	Target   string      `json:"target" db:"target"`
+	NewTab   bool        `json:"newTab" db:"new_tab"`
+	Children []*MenuItem `json:"children"`
+}
@@ -0,0 +1,80 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// Formats that a page body can be written in.
+const (
+	PageMarkdown = "markdown"
+	PageBlocks   = "blocks"
+)
+
+// Types of content block that a page written in blocks can have.
+const (
+	BlockHeading   = "heading"
+	BlockParagraph = "paragraph"
+	BlockImage     = "image"
+	BlockGallery   = "gallery"
+	BlockQuote     = "quote"
+	BlockList      = "list"
+	BlockEmbed     = "embed"
+	BlockDivider   = "divider"
+)
+
+// Page is a generic content page on the site, like a services or FAQ page.
+// The body is either Markdown or a list of content blocks, depending on the
+// format.
+type Page struct {
+	ID              uint          `json:"id" db:"id"`
+	Slug            string        `json:"slug" db:"slug"`
+	Title           string        `json:"title" db:"title"`
+	Format          string        `json:"format" db:"format"`
+	Body            string        `json:"body" db:"body"`
+	Blocks          Blocks        `json:"blocks" db:"blocks"`
+	MetaTitle       string        `json:"metaTitle,omitempty" db:"meta_title"`
+	MetaDescription string        `json:"metaDescription,omitempty" db:"meta_description"`
+	ShareImage      db.NullString `json:"shareImage,omitempty" db:"share_image"`
+	NoIndex         bool          `json:"noIndex" db:"no_index"`
+	Published       bool          `json:"published" db:"published"`
+	CreatedAt       time.Time     `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time     `json:"updatedAt" db:"updated_at"`
+}
+
+// Block is a content block on a page. The data depends on the type of
+// block, and is passed through to the site as it is.
+type Block struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Blocks is a list of content blocks that is stored as a JSON array.
+type Blocks []Block
+
+// MarshalJSON implements the JSON marshal interface for Blocks. A nil list is
+// marshalled as an empty array.
+func (b Blocks) MarshalJSON() ([]byte, error) {
+	if b == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal([]Block(b))
+}
+
+// Scan implements the Scanner interface for Blocks.
+func (b *Blocks) Scan(value interface{}) error {
+	return db.ScanJSON(value, b)
+}
+
+// Value implements the driver Valuer interface for Blocks.
+func (b Blocks) Value() (driver.Value, error) {
+	if b == nil {
+		return "[]", nil
+	}
+
+	return db.JSONValue([]Block(b))
+}
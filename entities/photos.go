@@ -1,6 +1,34 @@
 package entities
 
+import "github.com/nicolekellydesign/webby-api/internal/db"
+
 // Photo represents a photography photo.
 type Photo struct {
-	Filename string `json:"filename" db:"file_name"`
+	ID       uint        `json:"id" db:"id"`
+	Filename string      `json:"filename" db:"file_name"`
+	Title    string      `json:"title" db:"title"`
+	Caption  string      `json:"caption" db:"caption"`
+	AltText  string      `json:"altText" db:"alt_text"`
+	Location string      `json:"location" db:"location"`
+	TakenAt  db.NullTime `json:"takenAt" db:"taken_at"`
+	// Watermark is whether the public version of the photo is watermarked.
+	Watermark bool `json:"watermark" db:"watermark"`
+}
+
+// Album is a named collection of photos.
+type Album struct {
+	ID           uint          `json:"id" db:"id"`
+	Slug         string        `json:"slug" db:"slug"`
+	Title        string        `json:"title" db:"title"`
+	Description  string        `json:"description" db:"description"`
+	CoverPhotoID db.NullInt    `json:"coverPhotoId" db:"cover_photo_id"`
+	CoverPhoto   db.NullString `json:"coverPhoto,omitempty" db:"cover_photo"`
+	Position     int           `json:"position" db:"position"`
+	PhotoCount   int           `json:"photoCount" db:"photo_count"`
+	Photos       []*Photo      `json:"photos,omitempty"`
+	// CoverImageID is the ID of the photo shown as the cover, whether it was
+	// picked or is the first photo in the album. CoverWatermark is whether
+	// that photo is watermarked.
+	CoverImageID   db.NullInt `json:"coverImageId" db:"cover_image_id"`
+	CoverWatermark bool       `json:"-" db:"cover_watermark"`
 }
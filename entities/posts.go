@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// Post is a blog post. A post is shown publicly once it's published and its
+// publish date has passed, so posts can be scheduled ahead of time.
+type Post struct {
+	ID          uint          `json:"id" db:"id"`
+	Slug        string        `json:"slug" db:"slug"`
+	Title       string        `json:"title" db:"title"`
+	Excerpt     string        `json:"excerpt" db:"excerpt"`
+	Body        string        `json:"body" db:"body"`
+	CoverImage  db.NullString `json:"coverImage,omitempty" db:"cover_image"`
+	Tags        db.StringList `json:"tags" db:"tags"`
+	Published   bool          `json:"published" db:"published"`
+	PublishedAt db.NullTime   `json:"publishedAt" db:"published_at"`
+	CreatedAt   time.Time     `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time     `json:"updatedAt" db:"updated_at"`
+}
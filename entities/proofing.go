@@ -0,0 +1,90 @@
+package entities
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// ProofingGallery is a private gallery of photos for a client to review. It
+// isn't listed publicly, and can only be reached through its share link.
+type ProofingGallery struct {
+	ID          uint             `json:"id" db:"id"`
+	Title       string           `json:"title" db:"title"`
+	ClientName  string           `json:"clientName" db:"client_name"`
+	ShareToken  string           `json:"shareToken,omitempty" db:"share_token"`
+	HasPassword bool             `json:"hasPassword" db:"has_password"`
+	ExpiresAt   db.NullTime      `json:"expiresAt" db:"expires_at"`
+	CreatedAt   time.Time        `json:"createdAt" db:"created_at"`
+	Photos      []*ProofingPhoto `json:"photos,omitempty"`
+}
+
+// Expired checks if the gallery's share link has expired.
+func (g *ProofingGallery) Expired() bool {
+	return g.ExpiresAt.Valid && time.Now().After(g.ExpiresAt.Time)
+}
+
+// ProofingPhoto is a photo in a proofing gallery, along with whether the
+// client has marked it as a favourite and any comments they've left on it.
+// The file is stored privately under StoredName, and FileName is the name it
+// was uploaded with.
+type ProofingPhoto struct {
+	ID         uint               `json:"id" db:"id"`
+	FileName   string             `json:"fileName,omitempty" db:"file_name"`
+	StoredName string             `json:"-" db:"stored_name"`
+	Favourite  bool               `json:"favourite" db:"favourite"`
+	Comments   []*ProofingComment `json:"comments"`
+}
+
+// ProofingComment is a comment a client left on a photo in a proofing
+// gallery.
+type ProofingComment struct {
+	ID        uint      `json:"id" db:"id"`
+	PhotoID   uint      `json:"photoId" db:"photo_id"`
+	Body      string    `json:"body" db:"body"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// ProofingSession holds a client's access to a password protected proofing
+// gallery.
+type ProofingSession struct {
+	Token     string
+	GalleryID uint `db:"gallery_id"`
+	Created   time.Time
+	MaxAge    int `db:"max_age"`
+}
+
+// NewProofingSession creates a new session for a proofing gallery with a
+// unique token generated as a UUID, and an expiry time of seven days.
+func NewProofingSession(galleryID uint) (*ProofingSession, error) {
+	token, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProofingSession{
+		Token:     token.String(),
+		GalleryID: galleryID,
+		Created:   time.Now().UTC(),
+		MaxAge:    int((7 * 24 * time.Hour).Seconds()),
+	}, nil
+}
+
+// Expired checks if the session has expired.
+func (s *ProofingSession) Expired() bool {
+	expires := s.Created.Add(time.Duration(s.MaxAge) * time.Second)
+	return time.Now().After(expires)
+}
+
+// NewShareToken generates a random, unguessable token for a share link.
+func NewShareToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
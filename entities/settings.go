@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// PublicSettings are the site-wide settings that the public site needs.
+type PublicSettings struct {
+	SiteTitle        string        `json:"siteTitle" db:"site_title"`
+	MetaDescription  string        `json:"metaDescription" db:"meta_description"`
+	ShareImage       db.NullString `json:"shareImage,omitempty" db:"share_image"`
+	PrimaryColor     string        `json:"primaryColor" db:"primary_color"`
+	SecondaryColor   string        `json:"secondaryColor" db:"secondary_color"`
+	AnalyticsEnabled bool          `json:"analyticsEnabled" db:"analytics_enabled"`
+	FooterText       string        `json:"footerText" db:"footer_text"`
+}
+
+// Settings are all of the site-wide settings, including the ones that are
+// only shown to admins.
+type Settings struct {
+	PublicSettings
+	NotificationEmail string    `json:"notificationEmail" db:"notification_email"`
+	RobotsTxt         string    `json:"robotsTxt" db:"robots_txt"`
+	UpdatedAt         time.Time `json:"updatedAt" db:"updated_at"`
+}
@@ -0,0 +1,104 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// States that a print order can be in. Orders are pending until they've been
+// paid for, and stock is held for them until they're paid or cancelled.
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderFulfilled = "fulfilled"
+	OrderCancelled = "cancelled"
+)
+
+// PrintProduct is a print of a photo that's sold in the shop. Limited editions
+// have an edition size, and can't sell more prints than that across all of
+// their variants; open editions have an edition size of 0.
+type PrintProduct struct {
+	ID          uint            `json:"id" db:"id"`
+	PhotoID     uint            `json:"photoId" db:"photo_id"`
+	Photo       string          `json:"photo,omitempty" db:"photo"`
+	Image       string          `json:"image" db:"-"`
+	Title       string          `json:"title" db:"title"`
+	Description string          `json:"description" db:"description"`
+	EditionSize db.NullInt      `json:"editionSize" db:"edition_size"`
+	Sold        int             `json:"sold" db:"sold"`
+	Published   bool            `json:"published" db:"published"`
+	Position    int             `json:"position" db:"position"`
+	Variants    []*PrintVariant `json:"variants"`
+	CreatedAt   time.Time       `json:"createdAt" db:"created_at"`
+
+	// PhotoWatermark is whether the print's photo is watermarked.
+	PhotoWatermark bool `json:"-" db:"photo_watermark"`
+}
+
+// PrintVariant is a size and paper that a print is sold in. The price is in
+// the minor unit of the shop's currency, like cents. Variants that are made
+// to order don't track stock, and have a nil stock.
+type PrintVariant struct {
+	ID        uint   `json:"id" db:"id"`
+	ProductID uint   `json:"productId" db:"product_id"`
+	Size      string `json:"size" db:"size"`
+	Paper     string `json:"paper" db:"paper"`
+	Price     int    `json:"price" db:"price"`
+	Stock     *int   `json:"stock" db:"stock"`
+	Position  int    `json:"position" db:"position"`
+}
+
+// Order is an order for prints from the shop. The total is in the minor unit
+// of the order's currency.
+type Order struct {
+	ID              uint          `json:"id" db:"id"`
+	Reference       string        `json:"reference" db:"reference"`
+	Status          string        `json:"status" db:"status"`
+	Name            string        `json:"name" db:"name"`
+	Email           string        `json:"email" db:"email"`
+	Address         Address       `json:"address" db:"address"`
+	Total           int           `json:"total" db:"total"`
+	Currency        string        `json:"currency" db:"currency"`
+	PaymentProvider string        `json:"paymentProvider" db:"payment_provider"`
+	PaymentID       db.NullString `json:"paymentId" db:"payment_id"`
+	Items           []*OrderItem  `json:"items,omitempty"`
+	CreatedAt       time.Time     `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time     `json:"updatedAt" db:"updated_at"`
+}
+
+// OrderItem is a line on an order. The product details and price are copied
+// from the variant when the order is made, so that the order doesn't change
+// if the product does.
+type OrderItem struct {
+	ID        uint       `json:"id" db:"id"`
+	ProductID db.NullInt `json:"productId" db:"product_id"`
+	VariantID db.NullInt `json:"variantId" db:"variant_id"`
+	Title     string     `json:"title" db:"title"`
+	Size      string     `json:"size" db:"size"`
+	Paper     string     `json:"paper" db:"paper"`
+	UnitPrice int        `json:"unitPrice" db:"unit_price"`
+	Quantity  int        `json:"quantity" db:"quantity"`
+}
+
+// Address is where an order is shipped to. The country is an ISO 3166-1
+// alpha-2 code.
+type Address struct {
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postalCode"`
+	Country    string `json:"country"`
+}
+
+// Scan implements the Scanner interface for Address.
+func (a *Address) Scan(value interface{}) error {
+	return db.ScanJSON(value, a)
+}
+
+// Value implements the driver Valuer interface for Address.
+func (a Address) Value() (driver.Value, error) {
+	return db.JSONValue(a)
+}
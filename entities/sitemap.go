@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// Kinds of page that are listed in the sitemap.
+const (
+	SitemapProject = "project"
+	SitemapPage    = "page"
+	SitemapAlbum   = "album"
+	SitemapPost    = "post"
+)
+
+// SitemapEntry is a public page of the site that search engines should know
+// about, along with when it last changed.
+type SitemapEntry struct {
+	Kind      string    `db:"kind"`
+	Slug      string    `db:"slug"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// States that a newsletter subscriber can be in. Subscribers are pending
+// until they confirm their email address.
+const (
+	SubscriberPending      = "pending"
+	SubscriberConfirmed    = "confirmed"
+	SubscriberUnsubscribed = "unsubscribed"
+)
+
+// Subscriber is someone who signed up for the newsletter.
+type Subscriber struct {
+	ID             uint        `json:"id" db:"id"`
+	Email          string      `json:"email" db:"email"`
+	Status         string      `json:"status" db:"status"`
+	CreatedAt      time.Time   `json:"createdAt" db:"created_at"`
+	ConfirmedAt    db.NullTime `json:"confirmedAt" db:"confirmed_at"`
+	UnsubscribedAt db.NullTime `json:"unsubscribedAt" db:"unsubscribed_at"`
+}
@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// Testimonial is a quote from a client, optionally linked to the project it's
+// about. Testimonials are shown in order of their position.
+type Testimonial struct {
+	ID        uint          `json:"id" db:"id"`
+	Quote     string        `json:"quote" db:"quote"`
+	Name      string        `json:"name" db:"name"`
+	Role      string        `json:"role" db:"role"`
+	Company   string        `json:"company" db:"company"`
+	Avatar    db.NullString `json:"avatar,omitempty" db:"avatar"`
+	Project   db.NullString `json:"project,omitempty" db:"project_id"`
+	Featured  bool          `json:"featured" db:"featured"`
+	Position  int           `json:"position" db:"position"`
+	CreatedAt time.Time     `json:"createdAt" db:"created_at"`
+}
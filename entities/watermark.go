@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// Watermark holds the settings for the watermark drawn on public photos. If
+// an image is set, it's used instead of the text.
+type Watermark struct {
+	Enabled   bool          `json:"enabled" db:"enabled"`
+	Image     db.NullString `json:"image,omitempty" db:"image"`
+	Text      string        `json:"text" db:"text"`
+	Position  string        `json:"position" db:"position"`
+	Opacity   float64       `json:"opacity" db:"opacity"`
+	Scale     float64       `json:"scale" db:"scale"`
+	UpdatedAt time.Time     `json:"updatedAt" db:"updated_at"`
+}
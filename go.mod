@@ -10,6 +10,8 @@ require (
 	github.com/golang-migrate/migrate/v4 v4.15.1
 	github.com/jackc/pgx/v4 v4.13.0
 	github.com/jmoiron/sqlx v1.3.4
+	github.com/jung-kurt/gofpdf v1.16.2
+	golang.org/x/image v0.0.0-20211028202545-6944b10bf410
 )
 
 require (
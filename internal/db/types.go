@@ -2,7 +2,9 @@ package db
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"time"
 )
@@ -54,6 +56,15 @@ func (i *NullInt) Scan(value interface{}) error {
 	return nil
 }
 
+// Value implements the driver Valuer interface for NullInt.
+func (i NullInt) Value() (driver.Value, error) {
+	if !i.Valid {
+		return nil, nil
+	}
+
+	return int64(i.Int32), nil
+}
+
 // NullString wraps sql.NullString and implements some interfaces to make life easier.
 type NullString sql.NullString
 
@@ -101,6 +112,15 @@ func (s *NullString) Scan(value interface{}) error {
 	return nil
 }
 
+// Value implements the driver Valuer interface for NullString.
+func (s NullString) Value() (driver.Value, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+
+	return s.String, nil
+}
+
 // NullTime wraps sql.NullTime and implements some interfaces to make life easier.
 type NullTime sql.NullTime
 
@@ -146,3 +166,64 @@ func (t *NullTime) Scan(value interface{}) error {
 
 	return nil
 }
+
+// Value implements the driver Valuer interface for NullTime.
+func (t NullTime) Value() (driver.Value, error) {
+	if !t.Valid {
+		return nil, nil
+	}
+
+	return t.Time, nil
+}
+
+// StringList is a list of strings that is stored as a JSON array.
+type StringList []string
+
+// MarshalJSON implements the JSON marshal interface for StringList. A nil
+// list is marshalled as an empty array.
+func (l StringList) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal([]string(l))
+}
+
+// Scan implements the Scanner interface for StringList.
+func (l *StringList) Scan(value interface{}) error {
+	return ScanJSON(value, l)
+}
+
+// Value implements the driver Valuer interface for StringList.
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+
+	return JSONValue([]string(l))
+}
+
+// ScanJSON unmarshals a JSON column value from the database into dest. A null
+// value leaves dest untouched.
+func ScanJSON(value interface{}, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return fmt.Errorf("cannot scan %T as JSON", value)
+	}
+}
+
+// JSONValue marshals v so that it can be stored in a JSON column.
+func JSONValue(v interface{}) (driver.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
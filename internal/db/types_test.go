@@ -416,3 +416,159 @@ func TestScanNullTime_Null(t *testing.T) {
 		t.Fatal("scanned NullTime is not zero value")
 	}
 }
+
+// TestValueNullInt ensures that a valid NullInt is stored as its value.
+func TestValueNullInt(t *testing.T) {
+	// Given
+	test := NullInt{
+		Int32: 5,
+		Valid: true,
+	}
+
+	// When
+	result, err := test.Value()
+
+	// Then
+	if err != nil {
+		t.Errorf("error calling Value on NullInt: %s\n", err.Error())
+	}
+
+	if result != int64(5) {
+		t.Fatalf("result does not match expected: got %v, expected: 5\n", result)
+	}
+}
+
+// TestValueNullInt_Null ensures that an invalid NullInt is stored as null.
+func TestValueNullInt_Null(t *testing.T) {
+	// Given
+	test := NullInt{
+		Int32: 5,
+		Valid: false,
+	}
+
+	// When
+	result, err := test.Value()
+
+	// Then
+	if err != nil {
+		t.Errorf("error calling Value on NullInt: %s\n", err.Error())
+	}
+
+	if result != nil {
+		t.Fatalf("result does not match expected: got %v, expected: nil\n", result)
+	}
+}
+
+// TestValueNullString ensures that a valid NullString is stored as its value.
+func TestValueNullString(t *testing.T) {
+	// Given
+	test := NullString{
+		String: "test",
+		Valid:  true,
+	}
+
+	// When
+	result, err := test.Value()
+
+	// Then
+	if err != nil {
+		t.Errorf("error calling Value on NullString: %s\n", err.Error())
+	}
+
+	if result != "test" {
+		t.Fatalf("result does not match expected: got %v, expected: test\n", result)
+	}
+}
+
+// TestValueNullString_Null ensures that an invalid NullString is stored as
+// null.
+func TestValueNullString_Null(t *testing.T) {
+	// Given
+	test := NullString{
+		Valid: false,
+	}
+
+	// When
+	result, err := test.Value()
+
+	// Then
+	if err != nil {
+		t.Errorf("error calling Value on NullString: %s\n", err.Error())
+	}
+
+	if result != nil {
+		t.Fatalf("result does not match expected: got %v, expected: nil\n", result)
+	}
+}
+
+// TestMarshalStringList_Nil ensures that a nil StringList is marshalled as an
+// empty array.
+func TestMarshalStringList_Nil(t *testing.T) {
+	// Given
+	var test StringList
+
+	// When
+	result, err := test.MarshalJSON()
+
+	// Then
+	if err != nil {
+		t.Errorf("error marshalling nil StringList: %s\n", err.Error())
+	}
+
+	if string(result) != "[]" {
+		t.Fatalf("result does not match expected: got: %s, expected: []\n", result)
+	}
+}
+
+// TestScanStringList ensures that scanning a JSON array from a database works
+// as expected.
+func TestScanStringList(t *testing.T) {
+	// Given
+	var result StringList
+
+	// When
+	err := result.Scan([]byte(`["a","b"]`))
+
+	// Then
+	if err != nil {
+		t.Errorf("error calling Scan on StringList: %s\n", err.Error())
+	}
+
+	if len(result) != 2 || result[0] != "a" || result[1] != "b" {
+		t.Fatalf("result does not match expected: got %v, expected: [a b]\n", result)
+	}
+}
+
+// TestScanStringList_Invalid ensures that scanning a value that isn't JSON
+// returns an error.
+func TestScanStringList_Invalid(t *testing.T) {
+	// Given
+	var result StringList
+
+	// When
+	err := result.Scan(5)
+
+	// Then
+	if err == nil {
+		t.Fatal("expected an error scanning a number into a StringList")
+	}
+}
+
+// TestValueStringList_Nil ensures that a nil StringList is stored as an empty
+// JSON array.
+func TestValueStringList_Nil(t *testing.T) {
+	// Given
+	var test StringList
+
+	// When
+	result, err := test.Value()
+
+	// Then
+	if err != nil {
+		t.Errorf("error calling Value on StringList: %s\n", err.Error())
+	}
+
+	if result != "[]" {
+		t.Fatalf("result does not match expected: got %v, expected: []\n", result)
+	}
+}
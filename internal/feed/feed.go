@@ -0,0 +1,269 @@
+// Package feed writes lists of posts as RSS 2.0, Atom, and JSON Feed
+// documents.
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// Media types for each of the feed formats.
+const (
+	RSSContentType  = "application/rss+xml; charset=utf-8"
+	AtomContentType = "application/atom+xml; charset=utf-8"
+	JSONContentType = "application/feed+json; charset=utf-8"
+)
+
+// Feed is a list of items, along with information about the site they're
+// from. Every URL is absolute.
+type Feed struct {
+	Title       string
+	Description string
+	Author      string
+	Link        string
+	FeedURL     string
+	Updated     time.Time
+	Items       []*Item
+}
+
+// Item is an entry in a feed. The link is used as the item's unique ID.
+type Item struct {
+	Title     string
+	Link      string
+	Summary   string
+	Content   string
+	Image     string
+	Tags      []string
+	Published time.Time
+	Updated   time.Time
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	Self          atomLink   `xml:"atom:link"`
+	LastBuildDate string     `xml:"lastBuildDate,omitempty"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Description string   `xml:"description,omitempty"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// WriteRSS writes a feed as an RSS 2.0 document. Items are described by
+// their summaries.
+func WriteRSS(w io.Writer, f *Feed) error {
+	doc := rss{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			Self:        atomLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+			Items:       make([]*rssItem, 0, len(f.Items)),
+		},
+	}
+
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+
+	for _, item := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, &rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: true, Value: item.Link},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+			Description: item.Summary,
+			Categories:  item.Tags,
+		})
+	}
+
+	return writeXML(w, &doc)
+}
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string       `xml:"title"`
+	ID      string       `xml:"id"`
+	Updated string       `xml:"updated"`
+	Links   []atomLink   `xml:"link"`
+	Author  atomAuthor   `xml:"author"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary,omitempty"`
+	Content    *atomContent   `xml:"content,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// WriteAtom writes a feed as an Atom document. Item content is sent as plain
+// text.
+func WriteAtom(w io.Writer, f *Feed) error {
+	author := f.Author
+	if author == "" {
+		author = f.Title
+	}
+
+	doc := atomFeed{
+		Title:   f.Title,
+		ID:      f.Link,
+		Updated: f.Updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.Link},
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+		},
+		Author:  atomAuthor{Name: author},
+		Entries: make([]*atomEntry, 0, len(f.Items)),
+	}
+
+	for _, item := range f.Items {
+		entry := &atomEntry{
+			Title:      item.Title,
+			ID:         item.Link,
+			Link:       atomLink{Href: item.Link},
+			Published:  item.Published.Format(time.RFC3339),
+			Updated:    updated(item).Format(time.RFC3339),
+			Summary:    item.Summary,
+			Categories: make([]atomCategory, 0, len(item.Tags)),
+		}
+
+		if item.Content != "" {
+			entry.Content = &atomContent{Type: "text", Value: item.Content}
+		}
+
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return writeXML(w, &doc)
+}
+
+type jsonFeed struct {
+	Version     string        `json:"version"`
+	Title       string        `json:"title"`
+	HomePageURL string        `json:"home_page_url"`
+	FeedURL     string        `json:"feed_url"`
+	Description string        `json:"description,omitempty"`
+	Authors     []*jsonAuthor `json:"authors,omitempty"`
+	Items       []*jsonItem   `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	Summary       string   `json:"summary,omitempty"`
+	ContentText   string   `json:"content_text"`
+	Image         string   `json:"image,omitempty"`
+	DatePublished string   `json:"date_published"`
+	DateModified  string   `json:"date_modified"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// WriteJSON writes a feed as a JSON Feed 1.1 document. Item content is sent
+// as plain text.
+func WriteJSON(w io.Writer, f *Feed) error {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Items:       make([]*jsonItem, 0, len(f.Items)),
+	}
+
+	if f.Author != "" {
+		doc.Authors = []*jsonAuthor{{Name: f.Author}}
+	}
+
+	for _, item := range f.Items {
+		doc.Items = append(doc.Items, &jsonItem{
+			ID:            item.Link,
+			URL:           item.Link,
+			Title:         item.Title,
+			Summary:       item.Summary,
+			ContentText:   item.Content,
+			Image:         item.Image,
+			DatePublished: item.Published.Format(time.RFC3339),
+			DateModified:  updated(item).Format(time.RFC3339),
+			Tags:          item.Tags,
+		})
+	}
+
+	return json.NewEncoder(w).Encode(&doc)
+}
+
+// updated gets when an item was last changed, which is never before it was
+// published.
+func updated(item *Item) time.Time {
+	if item.Updated.After(item.Published) {
+		return item.Updated
+	}
+
+	return item.Published
+}
+
+// writeXML writes an XML document with its header.
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,182 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testFeed creates a feed with a single item.
+func testFeed() *Feed {
+	published := time.Date(2021, 11, 2, 10, 30, 0, 0, time.UTC)
+
+	return &Feed{
+		Title:       "Studio News",
+		Description: "Process write-ups & news",
+		Author:      "Nicole Kelly",
+		Link:        "https://example.com/",
+		FeedURL:     "https://example.com/api/v1/feeds/rss",
+		Updated:     published,
+		Items: []*Item{
+			{
+				Title:     "A <new> project",
+				Link:      "https://example.com/blog/a-new-project",
+				Summary:   "How it started",
+				Content:   "The whole story.",
+				Tags:      []string{"branding", "process"},
+				Published: published,
+			},
+		},
+	}
+}
+
+// TestWriteRSS ensures that an RSS feed is well-formed and has every item.
+func TestWriteRSS(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+
+	// When
+	err := WriteRSS(&buf, testFeed())
+
+	// Then
+	if err != nil {
+		t.Fatalf("error writing feed: %s\n", err.Error())
+	}
+
+	var doc struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title      string   `xml:"title"`
+				GUID       string   `xml:"guid"`
+				PubDate    string   `xml:"pubDate"`
+				Categories []string `xml:"category"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("feed is not valid XML: %s\n", err.Error())
+	}
+
+	if len(doc.Channel.Items) != 1 {
+		t.Fatalf("wrong number of items: got %d, expected: 1\n", len(doc.Channel.Items))
+	}
+
+	item := doc.Channel.Items[0]
+	if item.Title != "A <new> project" || item.GUID != "https://example.com/blog/a-new-project" {
+		t.Errorf("item does not match expected: got %v\n", item)
+	}
+
+	if item.PubDate != "Tue, 02 Nov 2021 10:30:00 +0000" {
+		t.Errorf("result does not match expected: got %s, expected: %s\n", item.PubDate, "Tue, 02 Nov 2021 10:30:00 +0000")
+	}
+
+	if len(item.Categories) != 2 {
+		t.Errorf("wrong number of categories: got %d, expected: 2\n", len(item.Categories))
+	}
+
+	if !strings.Contains(buf.String(), `<atom:link href="https://example.com/api/v1/feeds/rss" rel="self"`) {
+		t.Errorf("feed is missing its self link: %s\n", buf.String())
+	}
+}
+
+// TestWriteAtom ensures that an Atom feed is well-formed and has every item.
+func TestWriteAtom(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+
+	// When
+	err := WriteAtom(&buf, testFeed())
+
+	// Then
+	if err != nil {
+		t.Fatalf("error writing feed: %s\n", err.Error())
+	}
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Author  string   `xml:"author>name"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Updated string `xml:"updated"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+	}
+
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("feed is not valid Atom: %s\n", err.Error())
+	}
+
+	if doc.Author != "Nicole Kelly" {
+		t.Errorf("result does not match expected: got %s, expected: %s\n", doc.Author, "Nicole Kelly")
+	}
+
+	if len(doc.Entries) != 1 {
+		t.Fatalf("wrong number of entries: got %d, expected: 1\n", len(doc.Entries))
+	}
+
+	entry := doc.Entries[0]
+	if entry.Updated != "2021-11-02T10:30:00Z" || entry.Content != "The whole story." {
+		t.Errorf("entry does not match expected: got %v\n", entry)
+	}
+}
+
+// TestWriteJSON ensures that a JSON feed has the fields that JSON Feed 1.1
+// requires.
+func TestWriteJSON(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+
+	// When
+	err := WriteJSON(&buf, testFeed())
+
+	// Then
+	if err != nil {
+		t.Fatalf("error writing feed: %s\n", err.Error())
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("feed is not valid JSON: %s\n", err.Error())
+	}
+
+	if doc["version"] != "https://jsonfeed.org/version/1.1" {
+		t.Errorf("result does not match expected: got %v, expected: %s\n", doc["version"], "https://jsonfeed.org/version/1.1")
+	}
+
+	items, ok := doc["items"].([]interface{})
+	if !ok || len(items) != 1 {
+		t.Fatalf("wrong items: got %v\n", doc["items"])
+	}
+
+	item := items[0].(map[string]interface{})
+	if item["id"] != "https://example.com/blog/a-new-project" || item["content_text"] != "The whole story." {
+		t.Errorf("item does not match expected: got %v\n", item)
+	}
+}
+
+// TestWriteJSON_Empty ensures that a feed without items has an empty items
+// array.
+func TestWriteJSON_Empty(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	f := testFeed()
+	f.Items = nil
+
+	// When
+	err := WriteJSON(&buf, f)
+
+	// Then
+	if err != nil {
+		t.Fatalf("error writing feed: %s\n", err.Error())
+	}
+
+	if !strings.Contains(buf.String(), `"items":[]`) {
+		t.Fatalf("feed does not have an empty items array: %s\n", buf.String())
+	}
+}
@@ -0,0 +1,131 @@
+// Package mailer sends plain text email through an SMTP server.
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the SMTP server to send email through, and the address to
+// send it from. The username and password are only used if a username is set.
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Message is a plain text email to send.
+type Message struct {
+	To      []string
+	ReplyTo string
+	Subject string
+	Body    string
+}
+
+// Mailer sends email through an SMTP server.
+type Mailer struct {
+	config Config
+	from   *mail.Address
+}
+
+// New creates a Mailer that sends email with the given config.
+func New(config Config) (*Mailer, error) {
+	if config.Host == "" {
+		return nil, errors.New("no SMTP host set")
+	}
+
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %s", err.Error())
+	}
+
+	return &Mailer{config, from}, nil
+}
+
+// Send sends an email. STARTTLS is used if the server supports it.
+func (m *Mailer) Send(msg *Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("no recipients")
+	}
+
+	to := make([]string, 0, len(msg.To))
+	for _, addr := range msg.To {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return fmt.Errorf("invalid recipient: %s", err.Error())
+		}
+
+		to = append(to, parsed.Address)
+	}
+
+	data, err := m.format(msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+	return smtp.SendMail(addr, auth, m.from.Address, to, data)
+}
+
+// format builds the raw email for a message, with its headers. The body is
+// quoted-printable so that long lines and non-ASCII text survive the trip.
+func (m *Mailer) format(msg *Message, now time.Time) ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	domain := m.from.Address[strings.LastIndex(m.from.Address, "@")+1:]
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		// Line breaks in a header value would let it add headers of its own
+		value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+
+	header("From", m.from.String())
+	header("To", strings.Join(msg.To, ", "))
+	if msg.ReplyTo != "" {
+		header("Reply-To", msg.ReplyTo)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", "<"+hex.EncodeToString(id)+"@"+domain+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	body = strings.ReplaceAll(body, "\n", "\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,176 @@
+package mailer
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sink is an SMTP server that accepts one email and keeps it.
+type sink struct {
+	listener net.Listener
+	from     string
+	to       []string
+	data     string
+	done     chan struct{}
+}
+
+// newSink starts an SMTP sink on a random local port.
+func newSink(t *testing.T) *sink {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to start SMTP sink: %s\n", err.Error())
+	}
+
+	s := &sink{listener: listener, done: make(chan struct{})}
+	go s.serve()
+
+	return s
+}
+
+// serve handles a single SMTP session.
+func (s *sink) serve() {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP sink")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.to = append(s.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 go ahead")
+
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				if line == ".\r\n" {
+					break
+				}
+
+				data.WriteString(line)
+			}
+
+			s.data = data.String()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// TestSend ensures that an email is delivered to an SMTP server with the
+// right envelope and headers.
+func TestSend(t *testing.T) {
+	// Given
+	s := newSink(t)
+	defer s.listener.Close()
+
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	m, err := New(Config{Host: host, Port: portNumber, From: "Webby <webby@example.com>"})
+	if err != nil {
+		t.Fatalf("error creating mailer: %s\n", err.Error())
+	}
+
+	msg := &Message{
+		To:      []string{"owner@example.com"},
+		ReplyTo: "client@example.com",
+		Subject: "New enquiry",
+		Body:    "Hello\nthere",
+	}
+
+	// When
+	err = m.Send(msg)
+	<-s.done
+
+	// Then
+	if err != nil {
+		t.Fatalf("error sending email: %s\n", err.Error())
+	}
+
+	if s.from != "webby@example.com" {
+		t.Errorf("result does not match expected: got %v, expected: %v\n", s.from, "webby@example.com")
+	}
+
+	if len(s.to) != 1 || s.to[0] != "owner@example.com" {
+		t.Errorf("result does not match expected: got %v, expected: %v\n", s.to, []string{"owner@example.com"})
+	}
+
+	for _, expected := range []string{"Reply-To: client@example.com\r\n", "Subject: New enquiry\r\n", "\r\n\r\nHello\r\nthere"} {
+		if !strings.Contains(s.data, expected) {
+			t.Errorf("result does not match expected: got %q, expected it to contain: %q\n", s.data, expected)
+		}
+	}
+}
+
+// TestFormat_HeaderInjection ensures that line breaks can't be used to add
+// headers to an email.
+func TestFormat_HeaderInjection(t *testing.T) {
+	// Given
+	m, _ := New(Config{Host: "localhost", From: "webby@example.com"})
+	msg := &Message{
+		To:      []string{"owner@example.com"},
+		Subject: "Hello\r\nBcc: victim@example.com",
+	}
+
+	// When
+	data, err := m.format(msg, time.Now())
+
+	// Then
+	if err != nil {
+		t.Fatalf("error formatting email: %s\n", err.Error())
+	}
+
+	if strings.Contains(string(data), "\r\nBcc:") {
+		t.Fatalf("result does not match expected: got %q, expected no Bcc header\n", data)
+	}
+}
+
+// TestNew_InvalidFrom ensures that a mailer can't be created with an invalid
+// from address.
+func TestNew_InvalidFrom(t *testing.T) {
+	// Given
+	config := Config{Host: "localhost", From: "not an address"}
+
+	// When
+	_, err := New(config)
+
+	// Then
+	if err == nil {
+		t.Fatalf("result does not match expected: got %v, expected an error\n", err)
+	}
+}
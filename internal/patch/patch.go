@@ -0,0 +1,68 @@
+// Package patch implements JSON Merge Patch as described in RFC 7386.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ContentType is the media type for JSON Merge Patch documents.
+const ContentType = "application/merge-patch+json"
+
+// Merge applies a merge patch to a JSON document and returns the patched
+// document.
+//
+// Members in the patch replace the matching members in the document, members
+// that are absent are left unchanged, and members set to null are removed. An
+// empty document is treated as an empty object.
+func Merge(doc, patch []byte) ([]byte, error) {
+	p, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	var target interface{}
+	if len(bytes.TrimSpace(doc)) > 0 {
+		if target, err = decode(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(merge(target, p))
+}
+
+// merge recursively merges a decoded patch value into a decoded target value.
+func merge(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+		} else {
+			targetObj[key] = merge(targetObj[key], value)
+		}
+	}
+
+	return targetObj
+}
+
+// decode unmarshals a JSON value, keeping numbers as they were written.
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var ret interface{}
+	if err := decoder.Decode(&ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
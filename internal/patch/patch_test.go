@@ -0,0 +1,78 @@
+package patch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestMerge runs through the examples from appendix A of RFC 7386.
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		doc      string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, test := range tests {
+		// When
+		result, err := Merge([]byte(test.doc), []byte(test.patch))
+
+		// Then
+		if err != nil {
+			t.Errorf("error merging %s into %s: %s\n", test.patch, test.doc, err.Error())
+			continue
+		}
+
+		var got, expected interface{}
+		json.Unmarshal(result, &got)
+		json.Unmarshal([]byte(test.expected), &expected)
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("result does not match expected: got: %s, expected: %s\n", result, test.expected)
+		}
+	}
+}
+
+// TestMerge_EmptyDocument ensures that an empty document is treated as an
+// empty object.
+func TestMerge_EmptyDocument(t *testing.T) {
+	// When
+	result, err := Merge(nil, []byte(`{"a":"b","c":null}`))
+
+	// Then
+	if err != nil {
+		t.Fatalf("error merging into empty document: %s\n", err.Error())
+	}
+
+	if string(result) != `{"a":"b"}` {
+		t.Fatalf("result does not match expected: got: %s, expected: {\"a\":\"b\"}\n", result)
+	}
+}
+
+// TestMerge_InvalidPatch ensures that a malformed patch returns an error.
+func TestMerge_InvalidPatch(t *testing.T) {
+	// When
+	_, err := Merge([]byte(`{}`), []byte(`{"a":`))
+
+	// Then
+	if err == nil {
+		t.Fatal("expected an error for a malformed patch")
+	}
+}
@@ -0,0 +1,69 @@
+package payment
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// Fake is a payment provider for local development that never takes any
+// money. Payments are paid as soon as they're created, unless their status
+// is changed with SetStatus.
+type Fake struct {
+	mu       sync.Mutex
+	payments map[string]string
+}
+
+// NewFake creates a fake payment provider.
+func NewFake() *Fake {
+	return &Fake{payments: make(map[string]string)}
+}
+
+// Name is the name of the provider.
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+// CreatePayment starts a payment that's already paid. The checkout URL is the
+// return URL, since there's nothing to pay.
+func (f *Fake) CreatePayment(req *Request) (*Session, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	id := "fake_" + hex.EncodeToString(b)
+
+	f.mu.Lock()
+	f.payments[id] = StatusPaid
+	f.mu.Unlock()
+
+	return &Session{ID: id, CheckoutURL: req.ReturnURL}, nil
+}
+
+// GetStatus checks the state of a payment.
+func (f *Fake) GetStatus(id string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status, ok := f.payments[id]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return status, nil
+}
+
+// SetStatus changes the state of a payment, to try out what happens when a
+// payment is still pending or fails.
+func (f *Fake) SetStatus(id, status string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.payments[id]; !ok {
+		return ErrNotFound
+	}
+
+	f.payments[id] = status
+	return nil
+}
@@ -0,0 +1,70 @@
+package payment
+
+import "testing"
+
+// TestFake_Paid ensures that fake payments are paid as soon as they're
+// created.
+func TestFake_Paid(t *testing.T) {
+	// Given
+	f := NewFake()
+	session, err := f.CreatePayment(&Request{Reference: "abc", Amount: 5000, Currency: "USD", ReturnURL: "https://example.com/done"})
+	if err != nil {
+		t.Fatalf("error creating payment: %s\n", err.Error())
+	}
+
+	// When
+	status, err := f.GetStatus(session.ID)
+
+	// Then
+	if err != nil {
+		t.Fatalf("error getting payment status: %s\n", err.Error())
+	}
+
+	if status != StatusPaid {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", status, StatusPaid)
+	}
+
+	if session.CheckoutURL != "https://example.com/done" {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", session.CheckoutURL, "https://example.com/done")
+	}
+}
+
+// TestFake_SetStatus ensures that the status of a fake payment can be
+// changed.
+func TestFake_SetStatus(t *testing.T) {
+	// Given
+	f := NewFake()
+	session, _ := f.CreatePayment(&Request{Reference: "abc", Amount: 5000, Currency: "USD"})
+
+	// When
+	err := f.SetStatus(session.ID, StatusFailed)
+	status, _ := f.GetStatus(session.ID)
+
+	// Then
+	if err != nil {
+		t.Fatalf("error setting payment status: %s\n", err.Error())
+	}
+
+	if status != StatusFailed {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", status, StatusFailed)
+	}
+}
+
+// TestFake_NotFound ensures that unknown payments can't be found.
+func TestFake_NotFound(t *testing.T) {
+	// Given
+	f := NewFake()
+
+	// When
+	_, err := f.GetStatus("fake_missing")
+
+	// Then
+	if err != ErrNotFound {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", err, ErrNotFound)
+	}
+}
+
+// TestFake_Provider ensures that the fake can be used as a provider.
+func TestFake_Provider(t *testing.T) {
+	var _ Provider = NewFake()
+}
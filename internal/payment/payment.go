@@ -0,0 +1,55 @@
+// Package payment hands orders off to a payment provider, and checks whether
+// they've been paid for.
+package payment
+
+import (
+	"errors"
+	"time"
+)
+
+// States that a payment can be in.
+const (
+	StatusPending = "pending"
+	StatusPaid    = "paid"
+	StatusFailed  = "failed"
+)
+
+// ErrNotFound is returned when a provider has no payment with an ID.
+var ErrNotFound = errors.New("payment not found")
+
+// Request is a payment to take for an order. The amount is in the minor unit
+// of the currency, like cents.
+type Request struct {
+	Reference   string
+	Amount      int64
+	Currency    string
+	Email       string
+	Description string
+
+	// ReturnURL is where the customer is sent once they've paid.
+	ReturnURL string
+
+	// ExpiresAt is when the order stops holding its prints. Providers that
+	// can should stop taking payment for it then.
+	ExpiresAt time.Time
+}
+
+// Session is a payment that's been started with a provider. The customer
+// pays at the checkout URL.
+type Session struct {
+	ID          string
+	CheckoutURL string
+}
+
+// Provider takes payments for orders.
+type Provider interface {
+	// Name is the name of the provider, which is stored with each order.
+	Name() string
+
+	// CreatePayment starts a payment, returning where the customer should go
+	// to pay.
+	CreatePayment(req *Request) (*Session, error)
+
+	// GetStatus checks the state of a payment.
+	GetStatus(id string) (string, error)
+}
@@ -0,0 +1,160 @@
+// Package phash computes perceptual hashes of images, so that images that
+// look the same can be found even if they were saved at a different size or
+// quality.
+package phash
+
+import (
+	"image"
+	// Registered so that GIF images can be decoded
+	_ "image/gif"
+	// Registered so that JPEG images can be decoded
+	_ "image/jpeg"
+	// Registered so that PNG images can be decoded
+	_ "image/png"
+	"math/bits"
+	"os"
+	"sort"
+)
+
+// samplesPerCell is how many pixels are sampled along each side of a grid
+// cell when hashing.
+const samplesPerCell = 32
+
+// Threshold is the largest distance between two hashes for their images to
+// be considered near-duplicates.
+const Threshold = 10
+
+// Hash computes the difference hash of an image. The image is shrunk to a
+// 9x8 grid of grayscale values, and each bit of the hash is set if a cell is
+// brighter than the cell to its right.
+func Hash(img image.Image) uint64 {
+	const width, height = 9, 8
+
+	bounds := img.Bounds()
+	var cells [height][width]float64
+
+	// Average the brightness of the pixels that fall into each cell. Large
+	// images are sampled rather than read in full, which is plenty for a grid
+	// this small.
+	stepX := maxInt(bounds.Dx()/(width*samplesPerCell), 1)
+	stepY := maxInt(bounds.Dy()/(height*samplesPerCell), 1)
+
+	var counts [height][width]float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		cy := (y - bounds.Min.Y) * height / bounds.Dy()
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			cx := (x - bounds.Min.X) * width / bounds.Dx()
+			r, g, b, _ := img.At(x, y).RGBA()
+			cells[cy][cx] += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			counts[cy][cx]++
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width-1; x++ {
+			hash <<= 1
+			if cells[y][x]/maxFloat(counts[y][x], 1) > cells[y][x+1]/maxFloat(counts[y][x+1], 1) {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+// HashFile decodes the image at the given path and computes its hash.
+func HashFile(path string) (uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return 0, err
+	}
+
+	return Hash(img), nil
+}
+
+// Distance returns the number of bits that differ between two hashes. The
+// lower the distance, the more alike the images are.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Similar checks if two hashes are close enough for their images to be
+// considered near-duplicates.
+func Similar(a, b uint64) bool {
+	return Distance(a, b) <= Threshold
+}
+
+// Cluster groups names whose hashes are similar to each other. Names that
+// aren't similar to any others are left out. Each cluster is sorted, and the
+// clusters are sorted by their first name.
+func Cluster(hashes map[string]uint64) [][]string {
+	names := make([]string, 0, len(hashes))
+	for name := range hashes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Union-find over the indexes of the sorted names
+	parents := make([]int, len(names))
+	for i := range parents {
+		parents[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+
+	for i := range names {
+		for j := i + 1; j < len(names); j++ {
+			if Similar(hashes[names[i]], hashes[names[j]]) {
+				parents[find(j)] = find(i)
+			}
+		}
+	}
+
+	groups := make(map[int][]string)
+	for i, name := range names {
+		root := find(i)
+		groups[root] = append(groups[root], name)
+	}
+
+	ret := make([][]string, 0)
+	for _, group := range groups {
+		if len(group) > 1 {
+			ret = append(ret, group)
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i][0] < ret[j][0]
+	})
+
+	return ret
+}
+
+// maxFloat returns the larger of two floats.
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// maxInt returns the larger of two ints.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
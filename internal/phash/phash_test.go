@@ -0,0 +1,117 @@
+package phash
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// gradient creates a test image that gets brighter from left to right, with a
+// dark square whose position depends on offset.
+func gradient(width, height, offset int, brightness uint8) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8(x*200/width) + brightness
+			if x >= offset && x < offset+width/4 && y >= height/4 && y < height/2 {
+				v = 0
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+
+	return img
+}
+
+// TestHash_Resized ensures that the same image at a different size has a
+// similar hash.
+func TestHash_Resized(t *testing.T) {
+	// Given
+	small := gradient(90, 80, 10, 0)
+	large := gradient(900, 800, 100, 0)
+
+	// When
+	distance := Distance(Hash(small), Hash(large))
+
+	// Then
+	if distance > Threshold {
+		t.Fatalf("resized image is not similar: got distance %d, expected at most %d\n", distance, Threshold)
+	}
+}
+
+// TestHash_Brightened ensures that a brightened copy of an image has a
+// similar hash.
+func TestHash_Brightened(t *testing.T) {
+	// Given
+	original := gradient(180, 160, 20, 0)
+	brightened := gradient(180, 160, 20, 40)
+
+	// When
+	distance := Distance(Hash(original), Hash(brightened))
+
+	// Then
+	if distance > Threshold {
+		t.Fatalf("brightened image is not similar: got distance %d, expected at most %d\n", distance, Threshold)
+	}
+}
+
+// TestHash_Different ensures that images that look different don't have
+// similar hashes.
+func TestHash_Different(t *testing.T) {
+	// Given
+	left := gradient(180, 160, 0, 0)
+	mirrored := image.NewGray(left.Bounds())
+	for y := 0; y < 160; y++ {
+		for x := 0; x < 180; x++ {
+			mirrored.Set(179-x, y, left.At(x, y))
+		}
+	}
+
+	// When
+	distance := Distance(Hash(left), Hash(mirrored))
+
+	// Then
+	if distance <= Threshold {
+		t.Fatalf("different images are similar: got distance %d, expected more than %d\n", distance, Threshold)
+	}
+}
+
+// TestDistance ensures that the distance counts the differing bits.
+func TestDistance(t *testing.T) {
+	// When
+	result := Distance(0b1011, 0b0110)
+
+	// Then
+	if result != 3 {
+		t.Fatalf("result does not match expected: got %d, expected: 3\n", result)
+	}
+}
+
+// TestCluster ensures that similar hashes are grouped together, and that
+// hashes with nothing similar are left out.
+func TestCluster(t *testing.T) {
+	// Given
+	hashes := map[string]uint64{
+		"a.jpg": 0x0000000000000000,
+		"b.jpg": 0x0000000000000003,
+		"c.jpg": 0xffffffffffffffff,
+		"d.jpg": 0x00000000ffffffff,
+		"e.jpg": 0xfffffffffffffff0,
+	}
+
+	// When
+	result := Cluster(hashes)
+
+	// Then
+	if len(result) != 2 {
+		t.Fatalf("wrong number of clusters: got %d, expected: 2\n", len(result))
+	}
+
+	if len(result[0]) != 2 || result[0][0] != "a.jpg" || result[0][1] != "b.jpg" {
+		t.Fatalf("first cluster does not match expected: got %v, expected: [a.jpg b.jpg]\n", result[0])
+	}
+
+	if len(result[1]) != 2 || result[1][0] != "c.jpg" || result[1][1] != "e.jpg" {
+		t.Fatalf("second cluster does not match expected: got %v, expected: [c.jpg e.jpg]\n", result[1])
+	}
+}
@@ -0,0 +1,67 @@
+// Package ratelimit limits how often something can be done per key, like per
+// IP address, within a sliding window of time.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows a set number of events per key within a window of time. It's
+// safe to use from multiple goroutines.
+type Limiter struct {
+	limit  int
+	window time.Duration
+
+	mu        sync.Mutex
+	events    map[string][]time.Time
+	lastPrune time.Time
+}
+
+// New creates a Limiter that allows limit events per key within the window.
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:  limit,
+		window: window,
+		events: make(map[string][]time.Time),
+	}
+}
+
+// Allow checks if another event is allowed for the key at the given time. If
+// it is, the event is counted against the key.
+func (l *Limiter) Allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Keys that haven't been seen for a whole window are forgotten, so the
+	// map doesn't keep growing
+	if now.Sub(l.lastPrune) >= l.window {
+		for k, events := range l.events {
+			if len(recent(events, now.Add(-l.window))) == 0 {
+				delete(l.events, k)
+			}
+		}
+
+		l.lastPrune = now
+	}
+
+	events := recent(l.events[key], now.Add(-l.window))
+	if len(events) >= l.limit {
+		l.events[key] = events
+		return false
+	}
+
+	l.events[key] = append(events, now)
+	return true
+}
+
+// recent gets the events that happened after a cutoff time. Events are kept
+// in the order they happened.
+func recent(events []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(events) && !events[i].After(cutoff) {
+		i++
+	}
+
+	return events[i:]
+}
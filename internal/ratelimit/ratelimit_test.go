@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// TestAllow_Limit ensures that events over the limit are refused.
+func TestAllow_Limit(t *testing.T) {
+	// Given
+	l := New(2, time.Minute)
+	now := time.Now()
+
+	// When
+	first := l.Allow("1.2.3.4", now)
+	second := l.Allow("1.2.3.4", now.Add(time.Second))
+	third := l.Allow("1.2.3.4", now.Add(2*time.Second))
+
+	// Then
+	if !first || !second {
+		t.Fatalf("result does not match expected: got %v and %v, expected: %v\n", first, second, true)
+	}
+
+	if third {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", third, false)
+	}
+}
+
+// TestAllow_Keys ensures that each key has its own limit.
+func TestAllow_Keys(t *testing.T) {
+	// Given
+	l := New(1, time.Minute)
+	now := time.Now()
+	l.Allow("1.2.3.4", now)
+
+	// When
+	result := l.Allow("5.6.7.8", now)
+
+	// Then
+	if !result {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", result, true)
+	}
+}
+
+// TestAllow_Window ensures that events are allowed again once older events
+// have left the window.
+func TestAllow_Window(t *testing.T) {
+	// Given
+	l := New(1, time.Minute)
+	now := time.Now()
+	l.Allow("1.2.3.4", now)
+
+	// When
+	early := l.Allow("1.2.3.4", now.Add(59*time.Second))
+	late := l.Allow("1.2.3.4", now.Add(61*time.Second))
+
+	// Then
+	if early {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", early, false)
+	}
+
+	if !late {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", late, true)
+	}
+}
+
+// TestAllow_Prune ensures that keys are forgotten once they've been quiet for
+// a whole window.
+func TestAllow_Prune(t *testing.T) {
+	// Given
+	l := New(1, time.Minute)
+	now := time.Now()
+	l.Allow("1.2.3.4", now)
+
+	// When
+	l.Allow("5.6.7.8", now.Add(2*time.Minute))
+
+	// Then
+	if _, found := l.events["1.2.3.4"]; found {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", found, false)
+	}
+}
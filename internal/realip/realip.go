@@ -0,0 +1,126 @@
+// Package realip works out the address of the client that sent a request
+// when the server is behind a reverse proxy. Forwarding headers are only
+// believed when the request came from a trusted proxy, since anyone else can
+// set them to whatever they like.
+package realip
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// forwardingHeaders are the headers a proxy sets to pass on details of the
+// client's request.
+var forwardingHeaders = []string{
+	"Forwarded",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Proto",
+	"X-Real-IP",
+}
+
+// ParseTrusted parses a comma separated list of IP addresses and CIDR ranges
+// of trusted proxies, like "127.0.0.1, 10.0.0.0/8".
+func ParseTrusted(s string) ([]*net.IPNet, error) {
+	ret := make([]*net.IPNet, 0)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if !strings.Contains(part, "/") {
+			ip := net.ParseIP(part)
+			if ip == nil {
+				return nil, errors.New("invalid IP address: " + part)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+
+			ret = append(ret, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(part)
+		if err != nil {
+			return nil, errors.New("invalid CIDR range: " + part)
+		}
+
+		ret = append(ret, ipNet)
+	}
+
+	return ret, nil
+}
+
+// Middleware returns a middleware handler that sets the remote address of
+// each request to the client's address, if the request came through one of
+// the trusted proxies. The X-Real-IP header is used if the proxy sets it.
+// Otherwise, the last address in X-Forwarded-For is used, since that's the
+// one the proxy added; anything before it came from the client.
+//
+// Requests that didn't come from a trusted proxy keep their address, and
+// have their forwarding headers removed, so that handlers can believe the
+// ones that are left, like X-Forwarded-Proto.
+func Middleware(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !fromTrusted(r, trusted) {
+				for _, header := range forwardingHeaders {
+					r.Header.Del(header)
+				}
+			} else if ip := clientIP(r); ip != "" {
+				r.RemoteAddr = ip
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// fromTrusted checks if a request was sent by one of the trusted proxies.
+func fromTrusted(r *http.Request, trusted []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return contains(trusted, net.ParseIP(host))
+}
+
+// clientIP gets the client's address from the forwarding headers of a
+// request that came from a trusted proxy. If the headers don't have a valid
+// address, an empty string is returned.
+func clientIP(r *http.Request) string {
+	ip := strings.TrimSpace(r.Header.Get("X-Real-IP"))
+	if ip == "" {
+		forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+		ip = strings.TrimSpace(forwarded[len(forwarded)-1])
+	}
+
+	if net.ParseIP(ip) == nil {
+		return ""
+	}
+
+	return ip
+}
+
+// contains checks if an IP address is in any of the given ranges.
+func contains(ranges []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range ranges {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
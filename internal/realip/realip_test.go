@@ -0,0 +1,125 @@
+package realip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestParseTrusted ensures that single addresses and CIDR ranges can be
+// mixed in the list of trusted proxies.
+func TestParseTrusted(t *testing.T) {
+	// Given
+	list := "127.0.0.1, 10.0.0.0/8,,::1"
+
+	// When
+	trusted, err := ParseTrusted(list)
+
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err)
+	}
+
+	if len(trusted) != 3 {
+		t.Fatalf("result does not match expected: got %d ranges, expected: %d\n", len(trusted), 3)
+	}
+
+	if trusted[0].String() != "127.0.0.1/32" || trusted[1].String() != "10.0.0.0/8" || trusted[2].String() != "::1/128" {
+		t.Fatalf("result does not match expected: got %v\n", trusted)
+	}
+}
+
+// TestParseTrusted_Invalid ensures that anything that isn't an address or a
+// CIDR range is rejected.
+func TestParseTrusted_Invalid(t *testing.T) {
+	for _, list := range []string{"localhost", "10.0.0.0/33", "1.2.3"} {
+		// When
+		_, err := ParseTrusted(list)
+
+		// Then
+		if err == nil {
+			t.Fatalf("expected an error for %q\n", list)
+		}
+	}
+}
+
+// TestMiddleware ensures that forwarding headers are only believed from
+// trusted proxies.
+func TestMiddleware(t *testing.T) {
+	trusted, _ := ParseTrusted("10.0.0.1")
+
+	tests := []struct {
+		name      string
+		remote    string
+		realIP    string
+		forwarded string
+		expected  string
+	}{
+		{"untrusted with X-Real-IP", "1.2.3.4:5000", "9.9.9.9", "", "1.2.3.4:5000"},
+		{"untrusted with X-Forwarded-For", "1.2.3.4:5000", "", "9.9.9.9", "1.2.3.4:5000"},
+		{"trusted with X-Real-IP", "10.0.0.1:5000", "5.6.7.8", "9.9.9.9", "5.6.7.8"},
+		{"trusted with X-Forwarded-For", "10.0.0.1:5000", "", "9.9.9.9, 5.6.7.8", "5.6.7.8"},
+		{"trusted without headers", "10.0.0.1:5000", "", "", "10.0.0.1:5000"},
+		{"trusted with a bad header", "10.0.0.1:5000", "not an IP", "", "10.0.0.1:5000"},
+	}
+
+	for _, test := range tests {
+		// Given
+		var result string
+		handler := Middleware(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result = r.RemoteAddr
+		}))
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = test.remote
+		if test.realIP != "" {
+			r.Header.Set("X-Real-IP", test.realIP)
+		}
+		if test.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", test.forwarded)
+		}
+
+		// When
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+
+		// Then
+		if result != test.expected {
+			t.Fatalf("%s: result does not match expected: got %s, expected: %s\n", test.name, result, test.expected)
+		}
+	}
+}
+
+// TestMiddleware_Proto ensures that X-Forwarded-Proto is removed from
+// requests that didn't come from a trusted proxy.
+func TestMiddleware_Proto(t *testing.T) {
+	trusted, _ := ParseTrusted("10.0.0.1")
+
+	tests := []struct {
+		name     string
+		remote   string
+		expected string
+	}{
+		{"untrusted", "1.2.3.4:5000", ""},
+		{"trusted", "10.0.0.1:5000", "https"},
+	}
+
+	for _, test := range tests {
+		// Given
+		var result string
+		handler := Middleware(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result = r.Header.Get("X-Forwarded-Proto")
+		}))
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = test.remote
+		r.Header.Set("X-Forwarded-Proto", "https")
+
+		// When
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+
+		// Then
+		if result != test.expected {
+			t.Fatalf("%s: result does not match expected: got %q, expected: %q\n", test.name, result, test.expected)
+		}
+	}
+}
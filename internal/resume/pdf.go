@@ -0,0 +1,211 @@
+package resume
+
+import (
+	"io"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	pageMargin  = 20.0
+	lineHeight  = 5.0
+	fontFamily  = "Helvetica"
+	headingSize = 13.0
+	bodySize    = 10.0
+	smallSize   = 9.0
+)
+
+// WritePDF renders a JSON Resume document as an A4 PDF.
+func WritePDF(w io.Writer, r *Resume) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.SetTitle(r.Basics.Name, true)
+	pdf.SetAuthor(r.Basics.Name, true)
+	pdf.SetCreator("Webby", true)
+	pdf.AddPage()
+
+	// The core fonts only cover Windows-1252, so text is translated first
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	p := &printer{pdf, tr}
+
+	p.basics(&r.Basics)
+
+	if len(r.Work) > 0 {
+		p.heading("Experience")
+		for _, e := range r.Work {
+			p.entry(e.Position, dateRange(e.StartDate, e.EndDate, true), join(", ", e.Name, e.Location), e.Summary, e.Highlights)
+		}
+	}
+
+	if len(r.Projects) > 0 {
+		p.heading("Exhibitions")
+		for _, e := range r.Projects {
+			p.entry(e.Name, e.StartDate, e.Entity, e.Description, nil)
+		}
+	}
+
+	if len(r.Education) > 0 {
+		p.heading("Education")
+		for _, e := range r.Education {
+			p.entry(e.Institution, dateRange(e.StartDate, e.EndDate, false), join(", ", e.StudyType, e.Area), "", nil)
+		}
+	}
+
+	if len(r.Awards) > 0 {
+		p.heading("Awards")
+		for _, e := range r.Awards {
+			p.entry(e.Title, e.Date, e.Awarder, e.Summary, nil)
+		}
+	}
+
+	if len(r.Publications) > 0 {
+		p.heading("Publications")
+		for _, e := range r.Publications {
+			p.entry(e.Name, e.ReleaseDate, e.Publisher, e.Summary, nil)
+		}
+	}
+
+	if len(r.Skills) > 0 {
+		p.heading("Skills")
+		for _, s := range r.Skills {
+			p.skill(s)
+		}
+	}
+
+	return pdf.Output(w)
+}
+
+// printer writes the parts of a résumé to a PDF.
+type printer struct {
+	pdf *gofpdf.Fpdf
+	tr  func(string) string
+}
+
+// basics writes the name, label, contact details, and summary at the top of
+// the first page.
+func (p *printer) basics(b *Basics) {
+	p.pdf.SetFont(fontFamily, "B", 20)
+	p.pdf.MultiCell(0, 9, p.tr(b.Name), "", "L", false)
+
+	if b.Label != "" {
+		p.pdf.SetFont(fontFamily, "", 12)
+		p.pdf.MultiCell(0, 6, p.tr(b.Label), "", "L", false)
+	}
+
+	location := ""
+	if b.Location != nil {
+		location = b.Location.Address
+	}
+
+	contact := join("  |  ", b.Email, b.URL, location)
+	if contact != "" {
+		p.pdf.SetFont(fontFamily, "", smallSize)
+		p.pdf.SetTextColor(90, 90, 90)
+		p.pdf.MultiCell(0, lineHeight, p.tr(contact), "", "L", false)
+		p.pdf.SetTextColor(0, 0, 0)
+	}
+
+	if len(b.Profiles) > 0 {
+		profiles := make([]string, 0, len(b.Profiles))
+		for _, profile := range b.Profiles {
+			profiles = append(profiles, profile.URL)
+		}
+
+		p.pdf.SetFont(fontFamily, "", smallSize)
+		p.pdf.SetTextColor(90, 90, 90)
+		p.pdf.MultiCell(0, lineHeight, p.tr(strings.Join(profiles, "  |  ")), "", "L", false)
+		p.pdf.SetTextColor(0, 0, 0)
+	}
+
+	if b.Summary != "" {
+		p.pdf.Ln(3)
+		p.pdf.SetFont(fontFamily, "", bodySize)
+		p.pdf.MultiCell(0, lineHeight, p.tr(b.Summary), "", "L", false)
+	}
+}
+
+// heading writes a section heading with a rule under it.
+func (p *printer) heading(text string) {
+	p.pdf.Ln(4)
+	p.pdf.SetFont(fontFamily, "B", headingSize)
+	p.pdf.CellFormat(0, 7, p.tr(text), "B", 1, "L", false, 0, "")
+	p.pdf.Ln(2)
+}
+
+// entry writes a titled entry, with its date on the right, a subtitle, a
+// summary, and a list of highlights. Empty parts are left out.
+func (p *printer) entry(title, date, subtitle, summary string, highlights []string) {
+	width, _ := p.pdf.GetPageSize()
+	contentWidth := width - 2*pageMargin
+
+	p.pdf.SetFont(fontFamily, "", smallSize)
+	dateWidth := p.pdf.GetStringWidth(p.tr(date)) + 2
+
+	p.pdf.SetFont(fontFamily, "B", bodySize)
+	y := p.pdf.GetY()
+	p.pdf.MultiCell(contentWidth-dateWidth, lineHeight, p.tr(title), "", "L", false)
+	after := p.pdf.GetY()
+
+	if date != "" {
+		p.pdf.SetXY(pageMargin+contentWidth-dateWidth, y)
+		p.pdf.SetFont(fontFamily, "", smallSize)
+		p.pdf.CellFormat(dateWidth, lineHeight, p.tr(date), "", 0, "R", false, 0, "")
+		p.pdf.SetXY(pageMargin, after)
+	}
+
+	if subtitle != "" {
+		p.pdf.SetFont(fontFamily, "I", bodySize)
+		p.pdf.MultiCell(0, lineHeight, p.tr(subtitle), "", "L", false)
+	}
+
+	p.pdf.SetFont(fontFamily, "", bodySize)
+	if summary != "" {
+		p.pdf.MultiCell(0, lineHeight, p.tr(summary), "", "L", false)
+	}
+
+	for _, highlight := range highlights {
+		p.pdf.SetX(pageMargin + 3)
+		p.pdf.MultiCell(0, lineHeight, p.tr("- "+highlight), "", "L", false)
+	}
+
+	p.pdf.Ln(2)
+}
+
+// skill writes a skill on a single line, with its level and keywords.
+func (p *printer) skill(s *Skill) {
+	text := s.Name
+	if s.Level != "" {
+		text += " (" + s.Level + ")"
+	}
+
+	if len(s.Keywords) > 0 {
+		text += ": " + strings.Join(s.Keywords, ", ")
+	}
+
+	p.pdf.SetFont(fontFamily, "", bodySize)
+	p.pdf.MultiCell(0, lineHeight, p.tr(text), "", "L", false)
+}
+
+// dateRange formats a start and end date. If current is set, a missing end
+// date is shown as the present.
+func dateRange(start, end string, current bool) string {
+	if end == "" && current && start != "" {
+		end = "Present"
+	}
+
+	return join(" – ", start, end)
+}
+
+// join joins the non-empty strings with a separator.
+func join(sep string, parts ...string) string {
+	ret := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			ret = append(ret, part)
+		}
+	}
+
+	return strings.Join(ret, sep)
+}
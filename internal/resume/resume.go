@@ -0,0 +1,232 @@
+// Package resume exports a CV as a JSON Resume document, and renders it as a
+// PDF.
+//
+// See https://jsonresume.org/schema/ for the JSON Resume format.
+package resume
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+)
+
+// SchemaURL is the JSON Schema that exported documents follow.
+const SchemaURL = "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json"
+
+// Resume is a JSON Resume document.
+type Resume struct {
+	Schema       string         `json:"$schema"`
+	Basics       Basics         `json:"basics"`
+	Work         []*Work        `json:"work"`
+	Education    []*Education   `json:"education"`
+	Awards       []*Award       `json:"awards"`
+	Publications []*Publication `json:"publications"`
+	Skills       []*Skill       `json:"skills"`
+	Projects     []*Project     `json:"projects"`
+	Meta         Meta           `json:"meta"`
+}
+
+// Basics is the personal information in a JSON Resume document.
+type Basics struct {
+	Name     string     `json:"name"`
+	Label    string     `json:"label,omitempty"`
+	Email    string     `json:"email,omitempty"`
+	URL      string     `json:"url,omitempty"`
+	Summary  string     `json:"summary,omitempty"`
+	Location *Location  `json:"location,omitempty"`
+	Profiles []*Profile `json:"profiles"`
+}
+
+// Location is where the person is based. The CV only has a free-form
+// location, so it's kept as the address.
+type Location struct {
+	Address string `json:"address"`
+}
+
+// Profile is a social media or portfolio profile.
+type Profile struct {
+	Network string `json:"network"`
+	URL     string `json:"url"`
+}
+
+// Work is a job or other role.
+type Work struct {
+	Name       string   `json:"name"`
+	Position   string   `json:"position"`
+	Location   string   `json:"location,omitempty"`
+	URL        string   `json:"url,omitempty"`
+	StartDate  string   `json:"startDate,omitempty"`
+	EndDate    string   `json:"endDate,omitempty"`
+	Summary    string   `json:"summary,omitempty"`
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+// Education is a course of study.
+type Education struct {
+	Institution string `json:"institution"`
+	Area        string `json:"area,omitempty"`
+	StudyType   string `json:"studyType,omitempty"`
+	URL         string `json:"url,omitempty"`
+	StartDate   string `json:"startDate,omitempty"`
+	EndDate     string `json:"endDate,omitempty"`
+}
+
+// Award is an award or other recognition.
+type Award struct {
+	Title   string `json:"title"`
+	Date    string `json:"date,omitempty"`
+	Awarder string `json:"awarder,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// Publication is something the person wrote or was featured in.
+type Publication struct {
+	Name        string `json:"name"`
+	Publisher   string `json:"publisher,omitempty"`
+	ReleaseDate string `json:"releaseDate,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+}
+
+// Skill is a skill, along with related keywords.
+type Skill struct {
+	Name     string   `json:"name"`
+	Level    string   `json:"level,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// Project is a project the person worked on. JSON Resume has no section for
+// exhibitions, so they're listed as projects with the "exhibition" type.
+type Project struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Entity      string `json:"entity,omitempty"`
+	Type        string `json:"type,omitempty"`
+	StartDate   string `json:"startDate,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// Meta holds information about the document itself.
+type Meta struct {
+	Version      string `json:"version"`
+	LastModified string `json:"lastModified"`
+}
+
+// FromCV converts a CV to a JSON Resume document. The social links and awards
+// come from the about page.
+func FromCV(cv *entities.CV, about *entities.About) *Resume {
+	ret := &Resume{
+		Schema: SchemaURL,
+		Basics: Basics{
+			Name:     cv.Name,
+			Label:    cv.Headline,
+			Email:    cv.Email,
+			URL:      cv.Website,
+			Summary:  cv.Summary,
+			Profiles: make([]*Profile, 0),
+		},
+		Work:         make([]*Work, 0, len(cv.Experience)),
+		Education:    make([]*Education, 0, len(cv.Education)),
+		Awards:       make([]*Award, 0),
+		Publications: make([]*Publication, 0, len(cv.Publications)),
+		Skills:       make([]*Skill, 0, len(cv.Skills)),
+		Projects:     make([]*Project, 0, len(cv.Exhibitions)),
+		Meta: Meta{
+			Version:      "v1.0.0",
+			LastModified: cv.UpdatedAt.UTC().Format("2006-01-02T15:04:05"),
+		},
+	}
+
+	if cv.Location != "" {
+		ret.Basics.Location = &Location{Address: cv.Location}
+	}
+
+	for _, e := range cv.Experience {
+		ret.Work = append(ret.Work, &Work{
+			Name:       e.Organisation,
+			Position:   e.Position,
+			Location:   e.Location,
+			URL:        e.URL,
+			StartDate:  e.StartDate,
+			EndDate:    e.EndDate,
+			Summary:    e.Summary,
+			Highlights: e.Highlights,
+		})
+	}
+
+	for _, e := range cv.Education {
+		ret.Education = append(ret.Education, &Education{
+			Institution: e.Institution,
+			Area:        e.Area,
+			StudyType:   e.StudyType,
+			URL:         e.URL,
+			StartDate:   e.StartDate,
+			EndDate:     e.EndDate,
+		})
+	}
+
+	for _, p := range cv.Publications {
+		ret.Publications = append(ret.Publications, &Publication{
+			Name:        p.Name,
+			Publisher:   p.Publisher,
+			ReleaseDate: p.ReleaseDate,
+			URL:         p.URL,
+			Summary:     p.Summary,
+		})
+	}
+
+	for _, s := range cv.Skills {
+		ret.Skills = append(ret.Skills, &Skill{
+			Name:     s.Name,
+			Level:    s.Level,
+			Keywords: s.Keywords,
+		})
+	}
+
+	for _, e := range cv.Exhibitions {
+		entity := e.Venue
+		if e.Location != "" {
+			entity = strings.TrimPrefix(entity+", "+e.Location, ", ")
+		}
+
+		ret.Projects = append(ret.Projects, &Project{
+			Name:        e.Title,
+			Description: e.Description,
+			Entity:      entity,
+			Type:        "exhibition",
+			StartDate:   e.Date,
+			URL:         e.URL,
+		})
+	}
+
+	if about != nil {
+		for _, link := range about.SocialLinks {
+			network := link.Label
+			if network == "" {
+				network = link.Platform
+			}
+
+			ret.Basics.Profiles = append(ret.Basics.Profiles, &Profile{
+				Network: network,
+				URL:     link.URL,
+			})
+		}
+
+		for _, award := range about.Awards {
+			a := &Award{
+				Title:   award.Title,
+				Awarder: award.Issuer,
+				Summary: award.Description,
+			}
+
+			if award.Year.Valid {
+				a.Date = strconv.Itoa(int(award.Year.Int32))
+			}
+
+			ret.Awards = append(ret.Awards, a)
+		}
+	}
+
+	return ret
+}
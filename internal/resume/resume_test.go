@@ -0,0 +1,111 @@
+package resume
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nicolekellydesign/webby-api/entities"
+	"github.com/nicolekellydesign/webby-api/internal/db"
+)
+
+// testCV creates a CV with one of everything.
+func testCV() *entities.CV {
+	return &entities.CV{
+		Name:     "Nicole Kelly",
+		Headline: "Graphic Designer",
+		Email:    "hello@example.com",
+		Location: "Cork, Ireland",
+		Summary:  "Designer of things.",
+		Experience: []*entities.CVExperience{
+			{Organisation: "Studio", Position: "Designer", StartDate: "2019-06", Highlights: []string{"Rebranded the café"}},
+		},
+		Education: []*entities.CVEducation{
+			{Institution: "College", Area: "Design", StudyType: "BA", StartDate: "2015", EndDate: "2019"},
+		},
+		Skills: []*entities.CVSkill{
+			{Name: "Typography", Keywords: []string{"Layout", "Lettering"}},
+		},
+		Exhibitions: []*entities.CVExhibition{
+			{Title: "Graduate Show", Venue: "The Gallery", Location: "Cork", Date: "2019-05"},
+		},
+		Publications: []*entities.CVPublication{
+			{Name: "On Type", Publisher: "Magazine", ReleaseDate: "2020"},
+		},
+		UpdatedAt: time.Date(2021, 11, 2, 10, 30, 0, 0, time.UTC),
+	}
+}
+
+// TestFromCV ensures that every part of a CV ends up in the right place in
+// the JSON Resume document.
+func TestFromCV(t *testing.T) {
+	// Given
+	cv := testCV()
+	about := &entities.About{
+		SocialLinks: []*entities.SocialLink{{Platform: entities.SocialBehance, URL: "https://behance.net/nicole"}},
+		Awards:      []*entities.Award{{Title: "Best in Show", Issuer: "Awards Co", Year: db.NullInt{Int32: 2020, Valid: true}}},
+	}
+
+	// When
+	result := FromCV(cv, about)
+
+	// Then
+	if result.Basics.Location == nil || result.Basics.Location.Address != cv.Location {
+		t.Errorf("result does not match expected: got %v, expected: %v\n", result.Basics.Location, cv.Location)
+	}
+
+	if len(result.Work) != 1 || result.Work[0].Name != "Studio" || result.Work[0].Highlights[0] != "Rebranded the café" {
+		t.Errorf("work does not match expected: got %v\n", result.Work)
+	}
+
+	if len(result.Projects) != 1 || result.Projects[0].Type != "exhibition" || result.Projects[0].Entity != "The Gallery, Cork" {
+		t.Errorf("exhibitions do not match expected: got %v\n", result.Projects)
+	}
+
+	if len(result.Awards) != 1 || result.Awards[0].Date != "2020" || result.Awards[0].Awarder != "Awards Co" {
+		t.Errorf("awards do not match expected: got %v\n", result.Awards)
+	}
+
+	if len(result.Basics.Profiles) != 1 || result.Basics.Profiles[0].Network != entities.SocialBehance {
+		t.Errorf("profiles do not match expected: got %v\n", result.Basics.Profiles)
+	}
+
+	if result.Meta.LastModified != "2021-11-02T10:30:00" {
+		t.Errorf("result does not match expected: got %s, expected: %s\n", result.Meta.LastModified, "2021-11-02T10:30:00")
+	}
+}
+
+// TestFromCV_Empty ensures that an empty CV exports empty lists instead of
+// nulls.
+func TestFromCV_Empty(t *testing.T) {
+	// When
+	b, err := json.Marshal(FromCV(&entities.CV{}, nil))
+
+	// Then
+	if err != nil {
+		t.Fatalf("error encoding resume: %s\n", err.Error())
+	}
+
+	if bytes.Contains(b, []byte("null")) {
+		t.Fatalf("resume contains nulls: %s\n", b)
+	}
+}
+
+// TestWritePDF ensures that a CV is rendered as a PDF document.
+func TestWritePDF(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+
+	// When
+	err := WritePDF(&buf, FromCV(testCV(), nil))
+
+	// Then
+	if err != nil {
+		t.Fatalf("error writing PDF: %s\n", err.Error())
+	}
+
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("result is not a PDF document: %q\n", buf.Bytes()[:16])
+	}
+}
@@ -0,0 +1,119 @@
+// Package seo creates the metadata that search engines and social networks
+// read from a page: Open Graph and Twitter meta tags, and schema.org JSON-LD.
+package seo
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Context is the JSON-LD context for schema.org types.
+const Context = "https://schema.org"
+
+// Page is what's known about a page for its meta tags. Every URL is absolute,
+// and anything left empty is left out of the tags.
+type Page struct {
+	Title       string
+	Description string
+	URL         string
+	Image       string
+	SiteName    string
+
+	// Type is the Open Graph type of the page, like website or article.
+	Type string
+
+	// NoIndex asks search engines not to list the page.
+	NoIndex bool
+}
+
+// Tag is a meta tag. Open Graph tags are set with the property attribute, and
+// every other tag with the name attribute.
+type Tag struct {
+	Name     string `json:"name,omitempty"`
+	Property string `json:"property,omitempty"`
+	Content  string `json:"content"`
+}
+
+// Tags creates the meta tags for a page. Pages with an image get a large
+// image card on Twitter.
+func Tags(p *Page) []Tag {
+	tags := make([]Tag, 0, 14)
+
+	add := func(tag Tag) {
+		if tag.Content != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	add(Tag{Name: "description", Content: p.Description})
+	if p.NoIndex {
+		add(Tag{Name: "robots", Content: "noindex"})
+	}
+
+	add(Tag{Property: "og:type", Content: p.Type})
+	add(Tag{Property: "og:site_name", Content: p.SiteName})
+	add(Tag{Property: "og:title", Content: p.Title})
+	add(Tag{Property: "og:description", Content: p.Description})
+	add(Tag{Property: "og:url", Content: p.URL})
+	add(Tag{Property: "og:image", Content: p.Image})
+
+	card := "summary"
+	if p.Image != "" {
+		card = "summary_large_image"
+	}
+
+	add(Tag{Name: "twitter:card", Content: card})
+	add(Tag{Name: "twitter:title", Content: p.Title})
+	add(Tag{Name: "twitter:description", Content: p.Description})
+	add(Tag{Name: "twitter:image", Content: p.Image})
+
+	return tags
+}
+
+// CreativeWork is a schema.org CreativeWork, marshalled as JSON-LD.
+type CreativeWork struct {
+	Context      string   `json:"@context"`
+	Type         string   `json:"@type"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description,omitempty"`
+	URL          string   `json:"url"`
+	Image        []string `json:"image,omitempty"`
+	Creator      *Agent   `json:"creator,omitempty"`
+	Contributors []*Agent `json:"contributor,omitempty"`
+	DateCreated  string   `json:"dateCreated,omitempty"`
+	DateModified string   `json:"dateModified,omitempty"`
+	Keywords     string   `json:"keywords,omitempty"`
+	SameAs       []string `json:"sameAs,omitempty"`
+}
+
+// Agent is a schema.org Person or Organization that made a creative work.
+type Agent struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Truncate shortens text to at most n characters for use as a description.
+// Whitespace is collapsed, and text that's cut is cut between words where
+// possible, with an ellipsis on the end.
+func Truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	// Leave room for the ellipsis
+	cut := runes[:n-1]
+	for i := len(cut) - 1; i > 0; i-- {
+		if unicode.IsSpace(cut[i]) {
+			cut = cut[:i]
+			break
+		}
+	}
+
+	return strings.TrimRightFunc(string(cut), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
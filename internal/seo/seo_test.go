@@ -0,0 +1,93 @@
+package seo
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTags ensures that a page gets Open Graph and Twitter tags, with a large
+// image card when it has an image.
+func TestTags(t *testing.T) {
+	// Given
+	p := &Page{
+		Title:       "Project",
+		Description: "A project",
+		URL:         "https://example.com/gallery/project",
+		Image:       "https://example.com/images/project.png",
+		SiteName:    "Example",
+		Type:        "article",
+	}
+
+	// When
+	got := Tags(p)
+
+	// Then
+	expected := []Tag{
+		{Name: "description", Content: "A project"},
+		{Property: "og:type", Content: "article"},
+		{Property: "og:site_name", Content: "Example"},
+		{Property: "og:title", Content: "Project"},
+		{Property: "og:description", Content: "A project"},
+		{Property: "og:url", Content: "https://example.com/gallery/project"},
+		{Property: "og:image", Content: "https://example.com/images/project.png"},
+		{Name: "twitter:card", Content: "summary_large_image"},
+		{Name: "twitter:title", Content: "Project"},
+		{Name: "twitter:description", Content: "A project"},
+		{Name: "twitter:image", Content: "https://example.com/images/project.png"},
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", got, expected)
+	}
+}
+
+// TestTags_Minimal ensures that empty fields are left out, and that pages
+// without an image get a small card.
+func TestTags_Minimal(t *testing.T) {
+	// Given
+	p := &Page{Title: "Project", URL: "https://example.com/gallery/project", NoIndex: true}
+
+	// When
+	got := Tags(p)
+
+	// Then
+	expected := []Tag{
+		{Name: "robots", Content: "noindex"},
+		{Property: "og:title", Content: "Project"},
+		{Property: "og:url", Content: "https://example.com/gallery/project"},
+		{Name: "twitter:card", Content: "summary"},
+		{Name: "twitter:title", Content: "Project"},
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", got, expected)
+	}
+}
+
+// TestTruncate ensures that long text is cut between words.
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in       string
+		n        int
+		expected string
+	}{
+		{"Short text", 20, "Short text"},
+		{"  Spread\n out   text ", 20, "Spread out text"},
+		{"A long sentence, with words", 18, "A long sentence…"},
+		{"Unbrokenwordthatgoeson", 10, "Unbrokenw…"},
+	}
+
+	for _, test := range tests {
+		// When
+		got := Truncate(test.in, test.n)
+
+		// Then
+		if got != test.expected {
+			t.Fatalf("result does not match expected: got %q, expected: %q\n", got, test.expected)
+		}
+
+		if n := len([]rune(got)); n > test.n {
+			t.Fatalf("result is too long: got %d characters, expected at most %d\n", n, test.n)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package signing signs URL query parameters with an HMAC, so that a URL can
+// be handed out without the server having to remember it, and checked when it
+// comes back.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureKey is the query parameter that holds the signature.
+	SignatureKey = "sig"
+
+	// ExpiresKey is the query parameter that holds the Unix time a signed URL
+	// expires at.
+	ExpiresKey = "expires"
+)
+
+var (
+	// ErrInvalid is returned when a signature is missing or doesn't match.
+	ErrInvalid = errors.New("invalid signature")
+
+	// ErrExpired is returned when a signature is valid, but has expired.
+	ErrExpired = errors.New("signature has expired")
+)
+
+// Signer signs and verifies query parameters with a secret key.
+type Signer struct {
+	key []byte
+}
+
+// New creates a Signer that uses the given secret key.
+func New(key []byte) *Signer {
+	return &Signer{key}
+}
+
+// NewKey generates a random secret key.
+func NewKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Sign sets the expiry time of the query parameters, and adds a signature
+// covering every parameter. The parameters are changed in place.
+func (s *Signer) Sign(values url.Values, expires time.Time) {
+	values.Del(SignatureKey)
+	values.Set(ExpiresKey, strconv.FormatInt(expires.Unix(), 10))
+	values.Set(SignatureKey, s.signature(values))
+}
+
+// Verify checks that the query parameters have a valid signature, and that
+// they haven't expired at the given time.
+func (s *Signer) Verify(values url.Values, now time.Time) error {
+	sig, err := hex.DecodeString(values.Get(SignatureKey))
+	if err != nil || len(sig) == 0 {
+		return ErrInvalid
+	}
+
+	expected, _ := hex.DecodeString(s.signature(values))
+	if !hmac.Equal(sig, expected) {
+		return ErrInvalid
+	}
+
+	expires, err := strconv.ParseInt(values.Get(ExpiresKey), 10, 64)
+	if err != nil {
+		return ErrInvalid
+	}
+
+	if now.Unix() >= expires {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+// signature computes the signature of every query parameter other than the
+// signature itself. Parameters are encoded sorted by key, so their order in
+// the URL doesn't matter.
+func (s *Signer) signature(values url.Values) string {
+	signed := make(url.Values, len(values))
+	for k, v := range values {
+		if k != SignatureKey {
+			signed[k] = v
+		}
+	}
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(signed.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,97 @@
+package signing
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestVerify_Valid ensures that signed parameters can be verified.
+func TestVerify_Valid(t *testing.T) {
+	// Given
+	s := New([]byte("secret"))
+	now := time.Now()
+	values := url.Values{"file": {"photo.jpg"}, "id": {"4"}}
+	s.Sign(values, now.Add(time.Hour))
+
+	// When
+	err := s.Verify(values, now)
+
+	// Then
+	if err != nil {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", err, nil)
+	}
+}
+
+// TestVerify_Order ensures that the order of the parameters in the URL
+// doesn't change the signature.
+func TestVerify_Order(t *testing.T) {
+	// Given
+	s := New([]byte("secret"))
+	now := time.Now()
+	values := url.Values{"file": {"photo.jpg"}, "id": {"4"}}
+	s.Sign(values, now.Add(time.Hour))
+
+	query := "sig=" + values.Get(SignatureKey) + "&id=4&expires=" + values.Get(ExpiresKey) + "&file=photo.jpg"
+	parsed, _ := url.ParseQuery(query)
+
+	// When
+	err := s.Verify(parsed, now)
+
+	// Then
+	if err != nil {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", err, nil)
+	}
+}
+
+// TestVerify_Tampered ensures that changing any parameter, or using a
+// different key, makes the signature invalid.
+func TestVerify_Tampered(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		key    string
+		change func(url.Values)
+	}{
+		{"file", "secret", func(v url.Values) { v.Set("file", "other.jpg") }},
+		{"expires", "secret", func(v url.Values) { v.Set(ExpiresKey, "99999999999") }},
+		{"added", "secret", func(v url.Values) { v.Set("extra", "1") }},
+		{"removed", "secret", func(v url.Values) { v.Del("id") }},
+		{"signature", "secret", func(v url.Values) { v.Set(SignatureKey, "00") }},
+		{"missing", "secret", func(v url.Values) { v.Del(SignatureKey) }},
+		{"key", "other", func(v url.Values) {}},
+	}
+
+	for _, test := range tests {
+		// Given
+		values := url.Values{"file": {"photo.jpg"}, "id": {"4"}}
+		New([]byte("secret")).Sign(values, now.Add(time.Hour))
+		test.change(values)
+
+		// When
+		err := New([]byte(test.key)).Verify(values, now)
+
+		// Then
+		if err != ErrInvalid {
+			t.Errorf("result does not match expected for %s: got %v, expected: %v\n", test.name, err, ErrInvalid)
+		}
+	}
+}
+
+// TestVerify_Expired ensures that a valid signature is rejected once it has
+// expired.
+func TestVerify_Expired(t *testing.T) {
+	// Given
+	s := New([]byte("secret"))
+	now := time.Now()
+	values := url.Values{"file": {"photo.jpg"}}
+	s.Sign(values, now.Add(-time.Second))
+
+	// When
+	err := s.Verify(values, now)
+
+	// Then
+	if err != ErrExpired {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", err, ErrExpired)
+	}
+}
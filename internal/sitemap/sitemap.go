@@ -0,0 +1,98 @@
+// Package sitemap writes sitemaps in the sitemaps.org XML format, along with
+// the robots.txt files that point search engines to them.
+package sitemap
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// ContentType is the media type of a sitemap.
+	ContentType = "application/xml; charset=utf-8"
+
+	// RobotsContentType is the media type of a robots.txt file.
+	RobotsContentType = "text/plain; charset=utf-8"
+
+	// MaxURLs is the most URLs a single sitemap can have.
+	MaxURLs = 50000
+
+	// DefaultRobots lets every crawler see the whole site.
+	DefaultRobots = "User-agent: *\nAllow: /\n"
+)
+
+// ErrTooManyURLs is returned when there are more URLs than fit in a sitemap.
+var ErrTooManyURLs = errors.New("too many URLs for one sitemap")
+
+// URL is a page in a sitemap. The location is absolute, and the last modified
+// time is left out if it's zero.
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlSet struct {
+	XMLName xml.Name  `xml:"urlset"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	URLs    []*urlTag `xml:"url"`
+}
+
+type urlTag struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Write writes a list of URLs as a sitemap.
+func Write(w io.Writer, urls []*URL) error {
+	if len(urls) > MaxURLs {
+		return ErrTooManyURLs
+	}
+
+	set := urlSet{
+		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  make([]*urlTag, 0, len(urls)),
+	}
+
+	for _, u := range urls {
+		tag := &urlTag{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			tag.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+
+		set.URLs = append(set.URLs, tag)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(&set); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Robots creates a robots.txt file from a set of rules, pointing crawlers to
+// the sitemap at the given URL. If there are no rules, DefaultRobots is used.
+// Rules that already list a sitemap are left as they are.
+func Robots(rules, sitemapURL string) string {
+	rules = strings.TrimSpace(strings.ReplaceAll(rules, "\r\n", "\n"))
+	if rules == "" {
+		rules = strings.TrimSpace(DefaultRobots)
+	}
+
+	for _, line := range strings.Split(rules, "\n") {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), "sitemap:") {
+			return rules + "\n"
+		}
+	}
+
+	return rules + "\n\nSitemap: " + sitemapURL + "\n"
+}
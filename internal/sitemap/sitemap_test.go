@@ -0,0 +1,99 @@
+package sitemap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWrite ensures that URLs are written as a sitemap, with their last
+// modified times in UTC.
+func TestWrite(t *testing.T) {
+	// Given
+	zone := time.FixedZone("test", 2*60*60)
+	urls := []*URL{
+		{Loc: "https://example.com/", LastMod: time.Date(2024, 3, 1, 12, 0, 0, 0, zone)},
+		{Loc: "https://example.com/gallery/a&b"},
+	}
+
+	var buf bytes.Buffer
+
+	// When
+	err := Write(&buf, urls)
+
+	// Then
+	if err != nil {
+		t.Fatalf("error writing sitemap: %s\n", err.Error())
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/</loc>
+    <lastmod>2024-03-01T10:00:00Z</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/gallery/a&amp;b</loc>
+  </url>
+</urlset>
+`
+
+	if buf.String() != expected {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", buf.String(), expected)
+	}
+}
+
+// TestWrite_TooManyURLs ensures that sitemaps can't go over the URL limit.
+func TestWrite_TooManyURLs(t *testing.T) {
+	// Given
+	urls := make([]*URL, MaxURLs+1)
+	for i := range urls {
+		urls[i] = &URL{Loc: "https://example.com/"}
+	}
+
+	// When
+	err := Write(&bytes.Buffer{}, urls)
+
+	// Then
+	if err != ErrTooManyURLs {
+		t.Fatalf("result does not match expected: got %v, expected: %v\n", err, ErrTooManyURLs)
+	}
+}
+
+// TestRobots_Default ensures that everything is allowed when there are no
+// rules.
+func TestRobots_Default(t *testing.T) {
+	// When
+	got := Robots("  ", "https://example.com/sitemap.xml")
+
+	// Then
+	expected := "User-agent: *\nAllow: /\n\nSitemap: https://example.com/sitemap.xml\n"
+	if got != expected {
+		t.Fatalf("result does not match expected: got %q, expected: %q\n", got, expected)
+	}
+}
+
+// TestRobots_Rules ensures that custom rules are kept, with the sitemap added
+// to the end.
+func TestRobots_Rules(t *testing.T) {
+	// When
+	got := Robots("User-agent: *\r\nDisallow: /drafts\r\n", "https://example.com/sitemap.xml")
+
+	// Then
+	expected := "User-agent: *\nDisallow: /drafts\n\nSitemap: https://example.com/sitemap.xml\n"
+	if got != expected {
+		t.Fatalf("result does not match expected: got %q, expected: %q\n", got, expected)
+	}
+}
+
+// TestRobots_ExistingSitemap ensures that the sitemap isn't added twice.
+func TestRobots_ExistingSitemap(t *testing.T) {
+	// When
+	got := Robots("User-agent: *\nsitemap: https://cdn.example.com/sitemap.xml", "https://example.com/sitemap.xml")
+
+	// Then
+	if strings.Count(got, "https://example.com/sitemap.xml") != 0 {
+		t.Fatalf("result does not match expected: got %q, expected the existing sitemap only\n", got)
+	}
+}
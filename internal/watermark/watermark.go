@@ -0,0 +1,140 @@
+// Package watermark draws watermarks onto images.
+package watermark
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Positions a watermark can be placed at.
+const (
+	TopLeft     = "top-left"
+	TopRight    = "top-right"
+	BottomLeft  = "bottom-left"
+	BottomRight = "bottom-right"
+	Center      = "center"
+)
+
+// margin is the gap between a watermark and the edges of the image, as a
+// fraction of the image's shorter side.
+const margin = 0.02
+
+// Options controls how a watermark is drawn.
+type Options struct {
+	// Position is where on the image the watermark is placed.
+	Position string
+	// Opacity is how opaque the watermark is, from 0 to 1.
+	Opacity float64
+	// Scale is the width of the watermark as a fraction of the image's
+	// width, from 0 to 1.
+	Scale float64
+}
+
+// ValidPosition checks if a position is one that a watermark can be placed at.
+func ValidPosition(position string) bool {
+	switch position {
+	case TopLeft, TopRight, BottomLeft, BottomRight, Center:
+		return true
+	}
+
+	return false
+}
+
+// Apply returns a copy of img with the watermark mark drawn on it. The
+// original image is left untouched.
+func Apply(img image.Image, mark image.Image, opts Options) *image.RGBA {
+	bounds := img.Bounds()
+	ret := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(ret, ret.Bounds(), img, bounds.Min, draw.Src)
+
+	markBounds := mark.Bounds()
+	if markBounds.Empty() || opts.Scale <= 0 || opts.Opacity <= 0 {
+		return ret
+	}
+
+	// Scale the watermark to the requested width, keeping its aspect ratio
+	width := int(float64(ret.Bounds().Dx()) * opts.Scale)
+	height := width * markBounds.Dy() / markBounds.Dx()
+	if width < 1 || height < 1 {
+		return ret
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), mark, markBounds, draw.Src, nil)
+
+	target := Placement(ret.Bounds(), scaled.Bounds().Size(), opts.Position)
+	alpha := uint8(clamp(opts.Opacity) * 255)
+	draw.DrawMask(ret, target, scaled, image.Point{}, image.NewUniform(color.Alpha{A: alpha}), image.Point{}, draw.Over)
+
+	return ret
+}
+
+// Placement works out where a watermark of the given size goes inside an
+// image's bounds.
+func Placement(bounds image.Rectangle, size image.Point, position string) image.Rectangle {
+	shorter := bounds.Dx()
+	if bounds.Dy() < shorter {
+		shorter = bounds.Dy()
+	}
+	gap := int(float64(shorter) * margin)
+
+	var min image.Point
+	switch position {
+	case TopLeft:
+		min = image.Pt(bounds.Min.X+gap, bounds.Min.Y+gap)
+	case TopRight:
+		min = image.Pt(bounds.Max.X-gap-size.X, bounds.Min.Y+gap)
+	case BottomLeft:
+		min = image.Pt(bounds.Min.X+gap, bounds.Max.Y-gap-size.Y)
+	case Center:
+		min = image.Pt(bounds.Min.X+(bounds.Dx()-size.X)/2, bounds.Min.Y+(bounds.Dy()-size.Y)/2)
+	default:
+		min = image.Pt(bounds.Max.X-gap-size.X, bounds.Max.Y-gap-size.Y)
+	}
+
+	return image.Rectangle{Min: min, Max: min.Add(size)}
+}
+
+// Text renders a line of text as a watermark image. The text is white with a
+// dark shadow so that it shows up on both light and dark photos.
+func Text(text string) image.Image {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, text).Ceil() + 1
+	height := face.Metrics().Height.Ceil() + 1
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	drawer := font.Drawer{
+		Dst:  img,
+		Face: face,
+	}
+
+	ascent := face.Metrics().Ascent
+	drawer.Src = image.NewUniform(color.RGBA{A: 160})
+	drawer.Dot = fixed.Point26_6{X: fixed.I(1), Y: ascent + fixed.I(1)}
+	drawer.DrawString(text)
+
+	drawer.Src = image.White
+	drawer.Dot = fixed.Point26_6{X: 0, Y: ascent}
+	drawer.DrawString(text)
+
+	return img
+}
+
+// clamp limits a value to between 0 and 1.
+func clamp(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+
+	if v > 1 {
+		return 1
+	}
+
+	return v
+}
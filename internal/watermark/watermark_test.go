@@ -0,0 +1,114 @@
+package watermark
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// solid creates a test image filled with one color.
+func solid(width, height int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+// TestPlacement ensures that watermarks are placed in the right corner, inset
+// by the margin.
+func TestPlacement(t *testing.T) {
+	// Given
+	bounds := image.Rect(0, 0, 1000, 500)
+	size := image.Pt(100, 50)
+
+	tests := map[string]image.Rectangle{
+		TopLeft:     image.Rect(10, 10, 110, 60),
+		TopRight:    image.Rect(890, 10, 990, 60),
+		BottomLeft:  image.Rect(10, 440, 110, 490),
+		BottomRight: image.Rect(890, 440, 990, 490),
+		Center:      image.Rect(450, 225, 550, 275),
+	}
+
+	for position, expected := range tests {
+		// When
+		result := Placement(bounds, size, position)
+
+		// Then
+		if result != expected {
+			t.Errorf("placement for %s does not match expected: got %v, expected: %v\n", position, result, expected)
+		}
+	}
+}
+
+// TestApply ensures that the watermark is only drawn where it's placed, and
+// that the original image isn't changed.
+func TestApply(t *testing.T) {
+	// Given
+	img := solid(200, 100, color.Black)
+	mark := solid(10, 5, color.White)
+	opts := Options{
+		Position: BottomRight,
+		Opacity:  0.5,
+		Scale:    0.25,
+	}
+
+	// When
+	result := Apply(img, mark, opts)
+
+	// Then
+	marked := result.RGBAAt(170, 85)
+	if marked.R < 100 || marked.R > 155 {
+		t.Fatalf("watermark not drawn at half opacity: got %v\n", marked)
+	}
+
+	unmarked := result.RGBAAt(10, 10)
+	if unmarked.R != 0 {
+		t.Fatalf("image changed outside of the watermark: got %v\n", unmarked)
+	}
+
+	if img.RGBAAt(170, 85).R != 0 {
+		t.Fatal("original image was changed")
+	}
+}
+
+// TestApply_Transparent ensures that a watermark with no opacity leaves the
+// image as it was.
+func TestApply_Transparent(t *testing.T) {
+	// Given
+	img := solid(200, 100, color.Black)
+	mark := solid(10, 5, color.White)
+
+	// When
+	result := Apply(img, mark, Options{Position: Center, Opacity: 0, Scale: 0.5})
+
+	// Then
+	if result.RGBAAt(100, 50).R != 0 {
+		t.Fatal("transparent watermark changed the image")
+	}
+}
+
+// TestText ensures that text watermarks have some opaque pixels to draw.
+func TestText(t *testing.T) {
+	// When
+	result := Text("© Nicole Kelly")
+
+	// Then
+	bounds := result.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		t.Fatal("text watermark is empty")
+	}
+
+	opaque := false
+	for y := bounds.Min.Y; y < bounds.Max.Y && !opaque; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := result.At(x, y).RGBA(); a > 0 {
+				opaque = true
+				break
+			}
+		}
+	}
+
+	if !opaque {
+		t.Fatal("text watermark has no visible pixels")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	v1 "github.com/nicolekellydesign/webby-api/api/v1"
 	"github.com/nicolekellydesign/webby-api/database"
+	"github.com/nicolekellydesign/webby-api/internal/realip"
 )
 
 // Listener handles requests to our API endpoints.
@@ -24,15 +25,18 @@ type Listener struct {
 	rootDir      string
 	imagesDir    string
 	resourcesDir string
+	cacheDir     string
+	privateDir   string
+	config       v1.Config
 
 	errs chan error
 }
 
 // New creates a new HTTP listener on the given port.
-func New(port int, db *database.DB, log *waterlog.WaterLog, rootDir string, errs chan error) *Listener {
+func New(port int, db *database.DB, log *waterlog.WaterLog, rootDir string, config v1.Config, errs chan error) *Listener {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(realip.Middleware(config.TrustedProxies))
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
@@ -45,6 +49,9 @@ func New(port int, db *database.DB, log *waterlog.WaterLog, rootDir string, errs
 		rootDir:      rootDir,
 		imagesDir:    filepath.Join(rootDir, "images"),
 		resourcesDir: filepath.Join(rootDir, "resources"),
+		cacheDir:     filepath.Join(rootDir, "cache"),
+		privateDir:   filepath.Join(rootDir, "private"),
+		config:       config,
 		errs:         errs,
 	}
 }
@@ -63,9 +70,38 @@ func (l Listener) Serve() {
 		l.errs <- fmt.Errorf("resources dir does not exist and could not create it: %s", err.Error())
 	}
 
-	api := v1.NewAPI(l.db, l.log, l.imagesDir, l.resourcesDir)
+	if err := os.MkdirAll(l.cacheDir, 0755); err != nil {
+		l.errs <- fmt.Errorf("cache dir does not exist and could not create it: %s", err.Error())
+	}
+
+	// Files in here, like enquiry attachments, are only ever sent through
+	// the API
+	if err := os.MkdirAll(l.privateDir, 0700); err != nil {
+		l.errs <- fmt.Errorf("private dir does not exist and could not create it: %s", err.Error())
+	}
+
+	api := v1.NewAPI(l.db, l.log, l.imagesDir, l.resourcesDir, l.cacheDir, l.privateDir, l.config)
+	if err := api.ImportAboutFile(); err != nil {
+		l.log.Errorf("Unable to import the about page file: %s\n", err.Error())
+	}
+
+	if err := api.StoreProofingPhotos(); err != nil {
+		l.log.Errorf("Unable to copy proofing photos into private storage: %s\n", err.Error())
+	}
+
 	l.router.Mount("/api/v1", api.Routes())
 
+	// Let go of prints held for orders that were never paid for
+	go func() {
+		for range time.Tick(time.Minute) {
+			api.ExpireOrders()
+		}
+	}()
+
+	// Search engines only look for these at the root of the site
+	l.router.Get("/robots.txt", api.GetRobots)
+	l.router.Get("/sitemap.xml", api.GetSitemap)
+
 	addr := fmt.Sprintf("localhost:%d", l.Port)
 	l.errs <- http.ListenAndServe(addr, l.router)
 }